@@ -0,0 +1,60 @@
+package ical
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrUnavailable indica que el canal no entregó el feed; los bloqueos importados se conservan.
+var ErrUnavailable = errors.New("external calendar feed unavailable")
+
+// maxFeedSize acota lo que se descarga de un feed externo
+const maxFeedSize = 5 << 20
+
+type Client struct {
+	http *http.Client
+}
+
+func NewClient(timeout time.Duration) Client {
+	return Client{http: &http.Client{Timeout: timeout}}
+}
+
+// Fetch descarga y lee el feed de url. Las fechas con hora se llevan al día de location.
+func (c Client) Fetch(ctx context.Context, url string, location *time.Location) (Calendar, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Calendar{}, fmt.Errorf("invalid feed URL: %w", err)
+	}
+	request.Header.Set("Accept", "text/calendar")
+	response, err := c.http.Do(request)
+	if err != nil {
+		return Calendar{}, fmt.Errorf("error fetching feed: %v: %w", err, ErrUnavailable)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return Calendar{}, fmt.Errorf("feed responded %s: %w", response.Status, ErrUnavailable)
+	}
+
+	body := io.LimitReader(response.Body, maxFeedSize+1)
+	limited := &countingReader{reader: body}
+	calendar, err := Parse(limited, location)
+	if limited.read > maxFeedSize {
+		return Calendar{}, fmt.Errorf("feed is larger than %d bytes: %w", maxFeedSize, ErrInvalidCalendar)
+	}
+	return calendar, err
+}
+
+type countingReader struct {
+	reader io.Reader
+	read   int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += n
+	return n, err
+}
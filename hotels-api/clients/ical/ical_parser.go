@@ -0,0 +1,288 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hotels-api/domain/reservations"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCalendar = errors.New("invalid iCalendar document")
+
+// maxLineLength acota las líneas desplegadas para no cargar en memoria un feed roto o malicioso
+const maxLineLength = 64 * 1024
+
+// Event es un VEVENT de un feed externo reducido a las noches que bloquea: de Start a End, sin
+// incluir End, como una reserva.
+type Event struct {
+	UID     string
+	Summary string
+	Status  string
+	Start   reservations.Date
+	End     reservations.Date
+}
+
+// Calendar es el resultado de leer un feed. Skipped cuenta los VEVENT que no se pudieron
+// interpretar; se descartan para que un evento mal formado no frene la importación del resto.
+type Calendar struct {
+	Events  []Event
+	Skipped int
+}
+
+// property es una línea de contenido de RFC 5545: NOMBRE;PARAM=valor:VALOR
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse lee un VCALENDAR de RFC 5545. Las fechas con hora se pasan al día calendario de su TZID
+// o, si vienen en UTC o sin zona, de location, que debería ser la zona del hotel. Los eventos
+// recurrentes no se expanden: los canales publican cada reserva como un evento propio.
+func Parse(r io.Reader, location *time.Location) (Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	calendar := Calendar{Events: make([]Event, 0)}
+	depth := make([]string, 0)
+	var current []property
+	started := false
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseLine(line)
+		if err != nil {
+			if current != nil {
+				// Una línea ilegible invalida solo el evento que la contiene
+				current = append(current, property{name: "X-INVALID"})
+				continue
+			}
+			return Calendar{}, err
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			component := strings.ToUpper(prop.value)
+			if len(depth) == 0 && component != "VCALENDAR" {
+				return Calendar{}, fmt.Errorf("expected BEGIN:VCALENDAR, got BEGIN:%s: %w", prop.value, ErrInvalidCalendar)
+			}
+			started = true
+			depth = append(depth, component)
+			if component == "VEVENT" && len(depth) == 2 {
+				current = make([]property, 0)
+			}
+		case "END":
+			component := strings.ToUpper(prop.value)
+			if len(depth) == 0 || depth[len(depth)-1] != component {
+				return Calendar{}, fmt.Errorf("unexpected END:%s: %w", prop.value, ErrInvalidCalendar)
+			}
+			depth = depth[:len(depth)-1]
+			if component == "VEVENT" && len(depth) == 1 {
+				event, err := toEvent(current, location)
+				if err != nil {
+					calendar.Skipped++
+				} else {
+					calendar.Events = append(calendar.Events, event)
+				}
+				current = nil
+			}
+		default:
+			if !started {
+				return Calendar{}, fmt.Errorf("content before BEGIN:VCALENDAR: %w", ErrInvalidCalendar)
+			}
+			// Solo interesan las propiedades propias del VEVENT, no las de sus VALARM
+			if current != nil && len(depth) == 2 {
+				current = append(current, prop)
+			}
+		}
+	}
+	if !started {
+		return Calendar{}, fmt.Errorf("missing BEGIN:VCALENDAR: %w", ErrInvalidCalendar)
+	}
+	if len(depth) > 0 {
+		return Calendar{}, fmt.Errorf("missing END:%s: %w", depth[len(depth)-1], ErrInvalidCalendar)
+	}
+	return calendar, nil
+}
+
+// unfold junta las líneas plegadas: una línea que empieza con espacio o tab continúa la anterior.
+// Acepta CRLF, que es lo que pide la norma, y también LF solo, que publican varios canales.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineLength)
+	lines := make([]string, 0)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			if len(lines[len(lines)-1]) > maxLineLength {
+				return nil, fmt.Errorf("line longer than %d bytes: %w", maxLineLength, ErrInvalidCalendar)
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading calendar: %v: %w", err, ErrInvalidCalendar)
+	}
+	return lines, nil
+}
+
+// parseLine separa nombre, parámetros y valor. Los dos puntos dentro de un parámetro entre
+// comillas no terminan el nombre.
+func parseLine(line string) (property, error) {
+	quoted := false
+	separator := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			separator = i
+			break
+		}
+	}
+	if separator <= 0 {
+		return property{}, fmt.Errorf("line %q has no value: %w", line, ErrInvalidCalendar)
+	}
+
+	parts := strings.Split(line[:separator], ";")
+	prop := property{name: strings.ToUpper(parts[0]), params: make(map[string]string), value: line[separator+1:]}
+	for _, param := range parts[1:] {
+		key, value, found := strings.Cut(param, "=")
+		if !found {
+			return property{}, fmt.Errorf("parameter %q has no value: %w", param, ErrInvalidCalendar)
+		}
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return prop, nil
+}
+
+func toEvent(props []property, location *time.Location) (Event, error) {
+	var event Event
+	var start, end *property
+	var duration string
+	for i, prop := range props {
+		switch prop.name {
+		case "X-INVALID":
+			return Event{}, ErrInvalidCalendar
+		case "UID":
+			event.UID = strings.TrimSpace(prop.value)
+		case "SUMMARY":
+			event.Summary = unescape(prop.value)
+		case "STATUS":
+			event.Status = strings.ToUpper(strings.TrimSpace(prop.value))
+		case "DTSTART":
+			start = &props[i]
+		case "DTEND":
+			end = &props[i]
+		case "DURATION":
+			duration = prop.value
+		}
+	}
+	if event.UID == "" || start == nil {
+		return Event{}, fmt.Errorf("event without UID or DTSTART: %w", ErrInvalidCalendar)
+	}
+
+	startAt, allDay, err := parseTime(*start, location)
+	if err != nil {
+		return Event{}, err
+	}
+	endAt := startAt
+	switch {
+	case end != nil:
+		if endAt, _, err = parseTime(*end, location); err != nil {
+			return Event{}, err
+		}
+	case duration != "":
+		length, err := parseDuration(duration)
+		if err != nil {
+			return Event{}, err
+		}
+		endAt = startAt.Add(length)
+	case allDay:
+		// Sin DTEND un evento de día completo dura ese día
+		endAt = startAt.AddDate(0, 0, 1)
+	}
+
+	event.Start = reservations.DateOf(startAt)
+	event.End = reservations.DateOf(endAt)
+	if event.End.Before(event.Start.Time) {
+		return Event{}, fmt.Errorf("event %s ends before it starts: %w", event.UID, ErrInvalidCalendar)
+	}
+	if !event.End.After(event.Start.Time) {
+		// Un bloqueo de unas horas dentro del mismo día ocupa igual esa noche
+		event.End = event.Start.AddDays(1)
+	}
+	return event, nil
+}
+
+// parseTime interpreta DTSTART/DTEND como fecha (VALUE=DATE) o fecha y hora, en UTC con Z, en la
+// zona de TZID o flotante. Devuelve además si el valor era una fecha sin hora.
+func parseTime(prop property, location *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.value)
+	if prop.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		parsed, err := time.ParseInLocation("20060102", value, time.UTC)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%s %q is not a date: %w", prop.name, value, ErrInvalidCalendar)
+		}
+		return parsed, true, nil
+	}
+
+	zone := location
+	if tzid := prop.params["TZID"]; tzid != "" {
+		loaded, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%s has unknown TZID %q: %w", prop.name, tzid, ErrInvalidCalendar)
+		}
+		zone = loaded
+	}
+	if strings.HasSuffix(value, "Z") {
+		parsed, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%s %q is not a UTC date-time: %w", prop.name, value, ErrInvalidCalendar)
+		}
+		return parsed.In(location), false, nil
+	}
+	parsed, err := time.ParseInLocation("20060102T150405", value, zone)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%s %q is not a date-time: %w", prop.name, value, ErrInvalidCalendar)
+	}
+	return parsed, false, nil
+}
+
+var durationPattern = regexp.MustCompile(`^\+?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration interpreta un DURATION de RFC 5545, como P2D o PT36H. No admite duraciones negativas.
+func parseDuration(value string) (time.Duration, error) {
+	match := durationPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil || value == "P" || value == "PT" {
+		return 0, fmt.Errorf("DURATION %q is not valid: %w", value, ErrInvalidCalendar)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var duration time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		amount, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return 0, fmt.Errorf("DURATION %q is not valid: %w", value, ErrInvalidCalendar)
+		}
+		duration += time.Duration(amount) * unit
+	}
+	return duration, nil
+}
+
+// unescape revierte el escapado de los valores TEXT.
+func unescape(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(value)
+}
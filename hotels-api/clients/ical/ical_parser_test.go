@@ -0,0 +1,116 @@
+package ical_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"hotels-api/clients/ical"
+	"hotels-api/domain/reservations"
+)
+
+type expectedEvent struct {
+	uid, summary, status string
+	start, end           string
+}
+
+func parseFixture(t *testing.T, name string) (ical.Calendar, error) {
+	t.Helper()
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	location, err := time.LoadLocation("America/Argentina/Cordoba")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ical.Parse(file, location)
+}
+
+func TestParseFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		events  []expectedEvent
+		skipped int
+	}{
+		{
+			fixture: "airbnb.ics",
+			events: []expectedEvent{
+				{uid: "1418fb94e984-2b7ca4a2c4bff29b36e4b2d3a3e5e2e0@airbnb.com", summary: "Reserved", start: "2026-11-01", end: "2026-11-05"},
+				{uid: "7f662ec65913-4e3cf2fbfd0b3d2a17a9b7e1f3f9c2aa@airbnb.com", summary: "Airbnb (Not available)", start: "2026-12-18", end: "2026-12-20"},
+			},
+		},
+		{
+			// Sin DTEND un evento de día completo ocupa solo ese día
+			fixture: "booking.ics",
+			events: []expectedEvent{
+				{uid: "a3b2c1d0e9f8@booking.com", summary: "CLOSED - Not available", start: "2026-11-10", end: "2026-11-12"},
+				{uid: "b4c3d2e1f0a9@booking.com", summary: "CLOSED - Not available", start: "2026-11-15", end: "2026-11-16"},
+			},
+		},
+		{
+			fixture: "timezones.ics",
+			events: []expectedEvent{
+				{uid: "madrid@channel", summary: "Madrid local times", start: "2026-12-01", end: "2026-12-03"},
+				// 02:00 UTC del 5 son las 23:00 del 4 en Córdoba
+				{uid: "utc@channel", summary: "UTC crossing midnight in Cordoba", start: "2026-12-04", end: "2026-12-06"},
+				{uid: "floating@channel", summary: "Floating with duration", start: "2026-12-10", end: "2026-12-12"},
+				{uid: "day-use@channel", summary: "Day use", start: "2026-12-12", end: "2026-12-13"},
+			},
+		},
+		{
+			fixture: "folded.ics",
+			events: []expectedEvent{
+				{uid: "folded@channel", summary: "Bloqueo por mantenimiento, piso 2; habitación 204\nSegunda línea", start: "2026-11-01", end: "2026-11-02"},
+			},
+		},
+		{
+			fixture: "cancelled_and_malformed.ics",
+			events: []expectedEvent{
+				{uid: "cancelled@channel", summary: "Cancelled booking", status: "CANCELLED", start: "2026-11-01", end: "2026-11-03"},
+				{uid: "valid@channel", start: "2026-11-07", end: "2026-11-09"},
+			},
+			skipped: 4,
+		},
+		{
+			fixture: "empty.ics",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			calendar, err := parseFixture(t, tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if calendar.Skipped != tt.skipped {
+				t.Errorf("expected %d skipped events, got %d", tt.skipped, calendar.Skipped)
+			}
+			if len(calendar.Events) != len(tt.events) {
+				t.Fatalf("expected %d events, got %d: %+v", len(tt.events), len(calendar.Events), calendar.Events)
+			}
+			for i, expected := range tt.events {
+				event := calendar.Events[i]
+				start, _ := reservations.ParseDate(expected.start)
+				end, _ := reservations.ParseDate(expected.end)
+				if event.UID != expected.uid || event.Summary != expected.summary || event.Status != expected.status ||
+					!event.Start.Equal(start.Time) || !event.End.Equal(end.Time) {
+					t.Errorf("event %d: expected %+v, got uid=%q summary=%q status=%q %s..%s", i, expected, event.UID, event.Summary, event.Status, event.Start, event.End)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidDocuments(t *testing.T) {
+	for _, fixture := range []string{"not_a_calendar.ics", "unterminated.ics"} {
+		t.Run(fixture, func(t *testing.T) {
+			if _, err := parseFixture(t, fixture); !errors.Is(err, ical.ErrInvalidCalendar) {
+				t.Fatalf("expected ErrInvalidCalendar, got %v", err)
+			}
+		})
+	}
+}
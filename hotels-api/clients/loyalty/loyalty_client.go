@@ -0,0 +1,76 @@
+package loyalty
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hotels-api/domain/loyalty"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Config apunta a users-api, que lleva el libro de puntos. Token es el token compartido de sus
+// rutas internas.
+type Config struct {
+	BaseURL string
+	Token   string
+	Timeout time.Duration
+}
+
+type Client struct {
+	config Config
+	http   *http.Client
+}
+
+func NewClient(config Config) Client {
+	return Client{config: config, http: &http.Client{Timeout: config.Timeout}}
+}
+
+// Redeem canjea points del usuario. users-api no descuenta dos veces la misma reference, así que
+// se puede reintentar.
+func (c Client) Redeem(ctx context.Context, userID string, points int64, reference string) error {
+	body, err := json.Marshal(map[string]interface{}{"points": points, "reference": reference})
+	if err != nil {
+		return fmt.Errorf("error marshaling redemption: %w", err)
+	}
+	path := fmt.Sprintf("/internal/users/%s/points/redemptions", url.PathEscape(userID))
+	return c.do(ctx, http.MethodPost, path, body)
+}
+
+// Refund devuelve los puntos del canje reference; repetirlo no los devuelve dos veces.
+func (c Client) Refund(ctx context.Context, userID string, reference string) error {
+	path := fmt.Sprintf("/internal/users/%s/points/redemptions/%s", url.PathEscape(userID), url.PathEscape(reference))
+	return c.do(ctx, http.MethodDelete, path, nil)
+}
+
+func (c Client) do(ctx context.Context, method string, path string, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, method, c.config.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating loyalty request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Internal-Token", c.config.Token)
+	response, err := c.http.Do(request)
+	if err != nil {
+		return fmt.Errorf("error calling users-api: %v: %w", err, loyalty.ErrUnavailable)
+	}
+	defer response.Body.Close()
+
+	var result struct {
+		Error string `json:"error"`
+	}
+	switch {
+	case response.StatusCode < 300:
+		return nil
+	case response.StatusCode == http.StatusConflict:
+		json.NewDecoder(response.Body).Decode(&result)
+		return fmt.Errorf("%s: %w", result.Error, loyalty.ErrInsufficientPoints)
+	case response.StatusCode == http.StatusBadRequest || response.StatusCode == http.StatusNotFound:
+		json.NewDecoder(response.Body).Decode(&result)
+		return fmt.Errorf("%s: %w", result.Error, loyalty.ErrInvalidPoints)
+	default:
+		return fmt.Errorf("users-api responded %s: %w", response.Status, loyalty.ErrUnavailable)
+	}
+}
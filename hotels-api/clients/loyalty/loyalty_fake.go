@@ -0,0 +1,69 @@
+package loyalty
+
+import (
+	"context"
+	"fmt"
+	"hotels-api/domain/loyalty"
+	"sync"
+)
+
+// Fake es un libro de puntos en memoria para tests, con las mismas reglas que users-api: no
+// descuenta dos veces la misma referencia ni deja el saldo negativo.
+type Fake struct {
+	mutex       *sync.Mutex
+	balances    map[string]int64
+	redemptions map[string]int64
+}
+
+func NewFake() Fake {
+	return Fake{
+		mutex:       &sync.Mutex{},
+		balances:    make(map[string]int64),
+		redemptions: make(map[string]int64),
+	}
+}
+
+// Credit suma puntos al usuario, como una estadía completa.
+func (f Fake) Credit(userID string, points int64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.balances[userID] += points
+}
+
+func (f Fake) Balance(userID string) int64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.balances[userID]
+}
+
+func (f Fake) Redeem(ctx context.Context, userID string, points int64, reference string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	key := userID + ":" + reference
+	if _, exists := f.redemptions[key]; exists {
+		return nil
+	}
+	if f.balances[userID] < points {
+		return fmt.Errorf("balance is %d points: %w", f.balances[userID], loyalty.ErrInsufficientPoints)
+	}
+	f.balances[userID] -= points
+	f.redemptions[key] = points
+	return nil
+}
+
+func (f Fake) Refund(ctx context.Context, userID string, reference string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	key := userID + ":" + reference
+	points, exists := f.redemptions[key]
+	if !exists {
+		return fmt.Errorf("reference %q: %w", reference, loyalty.ErrInvalidPoints)
+	}
+	if points > 0 {
+		f.balances[userID] += points
+		f.redemptions[key] = 0
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	paymentsDomain "hotels-api/domain/payments"
+	"math"
+	"strings"
+	"sync"
+)
+
+// Medios de pago de prueba: el primero se rechaza al autorizar y el segundo cobra la seña pero
+// rechaza las capturas siguientes, como una tarjeta sin fondos al momento del check-in. El último
+// autoriza pero la captura falla como si la pasarela no respondiera.
+const (
+	DeclinedPaymentMethod        = "tok_declined"
+	CaptureDeclinedPaymentMethod = "tok_capture_declined"
+	CaptureErrorPaymentMethod    = "tok_capture_error"
+)
+
+type authorization struct {
+	paymentMethod string
+	amount        float64
+	captured      float64
+	refunded      float64
+	voided        bool
+}
+
+// Fake es una pasarela en memoria y determinística para desarrollo local y tests: aprueba todo
+// salvo los medios de pago de prueba de rechazo y las operaciones que exceden lo autorizado.
+type Fake struct {
+	mutex          *sync.Mutex
+	sequence       *int
+	authorizations map[string]*authorization
+}
+
+func NewFake() Fake {
+	return Fake{
+		mutex:          &sync.Mutex{},
+		sequence:       new(int),
+		authorizations: make(map[string]*authorization),
+	}
+}
+
+func (gateway Fake) Authorize(ctx context.Context, request paymentsDomain.AuthorizeRequest) (paymentsDomain.Result, error) {
+	gateway.mutex.Lock()
+	defer gateway.mutex.Unlock()
+
+	reference := gateway.next("auth")
+	if request.PaymentMethod == DeclinedPaymentMethod {
+		return paymentsDomain.Result{Reference: reference, Reason: "card declined"}, nil
+	}
+	if request.Amount <= 0 {
+		return paymentsDomain.Result{Reference: reference, Reason: "amount must be positive"}, nil
+	}
+	gateway.authorizations[reference] = &authorization{paymentMethod: request.PaymentMethod, amount: request.Amount}
+	return paymentsDomain.Result{Reference: reference, Approved: true}, nil
+}
+
+func (gateway Fake) Capture(ctx context.Context, authorizationID string, amount float64) (paymentsDomain.Result, error) {
+	gateway.mutex.Lock()
+	defer gateway.mutex.Unlock()
+
+	auth, err := gateway.find(authorizationID)
+	if err != nil {
+		return paymentsDomain.Result{}, err
+	}
+	if auth.paymentMethod == CaptureErrorPaymentMethod {
+		return paymentsDomain.Result{}, fmt.Errorf("capture of %s timed out", authorizationID)
+	}
+	reference := gateway.next("capture")
+	switch {
+	case auth.voided:
+		return paymentsDomain.Result{Reference: reference, Reason: "authorization voided"}, nil
+	case auth.paymentMethod == CaptureDeclinedPaymentMethod && auth.captured > 0:
+		return paymentsDomain.Result{Reference: reference, Reason: "insufficient funds"}, nil
+	case roundCents(auth.captured+amount) > auth.amount:
+		return paymentsDomain.Result{Reference: reference, Reason: "amount exceeds authorization"}, nil
+	}
+	auth.captured = roundCents(auth.captured + amount)
+	return paymentsDomain.Result{Reference: reference, Approved: true}, nil
+}
+
+func (gateway Fake) Refund(ctx context.Context, authorizationID string, amount float64) (paymentsDomain.Result, error) {
+	gateway.mutex.Lock()
+	defer gateway.mutex.Unlock()
+
+	auth, err := gateway.find(authorizationID)
+	if err != nil {
+		return paymentsDomain.Result{}, err
+	}
+	reference := gateway.next("refund")
+	if roundCents(auth.refunded+amount) > auth.captured {
+		return paymentsDomain.Result{Reference: reference, Reason: "amount exceeds captured"}, nil
+	}
+	auth.refunded = roundCents(auth.refunded + amount)
+	return paymentsDomain.Result{Reference: reference, Approved: true}, nil
+}
+
+func (gateway Fake) Void(ctx context.Context, authorizationID string) (paymentsDomain.Result, error) {
+	gateway.mutex.Lock()
+	defer gateway.mutex.Unlock()
+
+	auth, err := gateway.find(authorizationID)
+	if err != nil {
+		return paymentsDomain.Result{}, err
+	}
+	auth.voided = true
+	return paymentsDomain.Result{Reference: gateway.next("void"), Approved: true}, nil
+}
+
+// next genera referencias correlativas, siempre iguales para la misma secuencia de operaciones.
+func (gateway Fake) next(prefix string) string {
+	*gateway.sequence++
+	return fmt.Sprintf("fake_%s_%06d", prefix, *gateway.sequence)
+}
+
+func (gateway Fake) find(authorizationID string) (*authorization, error) {
+	auth, exists := gateway.authorizations[authorizationID]
+	if !exists || !strings.HasPrefix(authorizationID, "fake_auth_") {
+		return nil, fmt.Errorf("unknown authorization %q", authorizationID)
+	}
+	return auth, nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
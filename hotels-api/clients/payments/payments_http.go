@@ -0,0 +1,96 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	paymentsDomain "hotels-api/domain/payments"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Config apunta a la API REST del proveedor de pagos. APIKey se manda como Bearer en cada llamada.
+type Config struct {
+	BaseURL string
+	APIKey  string
+	Timeout time.Duration
+}
+
+// HTTP es la pasarela del proveedor real. Pay retoma el pago guardado en cada reintento, así que
+// el cliente no necesita repetir operaciones por su cuenta.
+type HTTP struct {
+	config Config
+	http   *http.Client
+}
+
+func NewHTTP(config Config) HTTP {
+	return HTTP{config: config, http: &http.Client{Timeout: config.Timeout}}
+}
+
+func (gateway HTTP) Authorize(ctx context.Context, request paymentsDomain.AuthorizeRequest) (paymentsDomain.Result, error) {
+	return gateway.do(ctx, "/authorizations", map[string]interface{}{
+		"reference":      request.Reference,
+		"amount":         request.Amount,
+		"currency":       request.Currency,
+		"payment_method": request.PaymentMethod,
+	})
+}
+
+func (gateway HTTP) Capture(ctx context.Context, authorizationID string, amount float64) (paymentsDomain.Result, error) {
+	return gateway.do(ctx, authorizationPath(authorizationID, "captures"), map[string]interface{}{
+		"amount": amount,
+	})
+}
+
+func (gateway HTTP) Refund(ctx context.Context, authorizationID string, amount float64) (paymentsDomain.Result, error) {
+	return gateway.do(ctx, authorizationPath(authorizationID, "refunds"), map[string]interface{}{
+		"amount": amount,
+	})
+}
+
+func (gateway HTTP) Void(ctx context.Context, authorizationID string) (paymentsDomain.Result, error) {
+	return gateway.do(ctx, authorizationPath(authorizationID, "voids"), map[string]interface{}{})
+}
+
+// do manda la operación y traduce la respuesta: 2xx trae el resultado, 402 es un rechazo y
+// cualquier otra respuesta es un error de la pasarela.
+func (gateway HTTP) do(ctx context.Context, path string, body map[string]interface{}) (paymentsDomain.Result, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return paymentsDomain.Result{}, fmt.Errorf("error marshaling payment request: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, gateway.config.BaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return paymentsDomain.Result{}, fmt.Errorf("error creating payment request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+gateway.config.APIKey)
+	response, err := gateway.http.Do(request)
+	if err != nil {
+		return paymentsDomain.Result{}, fmt.Errorf("error calling payment gateway: %w", err)
+	}
+	defer response.Body.Close()
+
+	var result struct {
+		ID       string `json:"id"`
+		Approved bool   `json:"approved"`
+		Reason   string `json:"reason"`
+	}
+	if response.StatusCode >= 300 && response.StatusCode != http.StatusPaymentRequired {
+		return paymentsDomain.Result{}, fmt.Errorf("payment gateway responded %s", response.Status)
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return paymentsDomain.Result{}, fmt.Errorf("error decoding payment gateway response: %w", err)
+	}
+	return paymentsDomain.Result{
+		Reference: result.ID,
+		Approved:  result.Approved && response.StatusCode != http.StatusPaymentRequired,
+		Reason:    result.Reason,
+	}, nil
+}
+
+func authorizationPath(authorizationID string, operation string) string {
+	return fmt.Sprintf("/authorizations/%s/%s", url.PathEscape(authorizationID), operation)
+}
@@ -1,13 +1,65 @@
 package queues
 
-import "hotels-api/domain/hotels"
+import (
+	"hotels-api/domain/hotels"
+	"hotels-api/domain/reservations"
+	"sync"
+)
 
-type Mock struct{}
+// Mock guarda los avisos de hoteles publicados para que los tests puedan revisarlos.
+type Mock struct {
+	mutex  *sync.Mutex
+	events *[]hotels.HotelNew
+}
 
 func NewMock() Mock {
-	return Mock{}
+	return Mock{
+		mutex:  &sync.Mutex{},
+		events: &[]hotels.HotelNew{},
+	}
+}
+
+func (queue Mock) Publish(hotelNew hotels.HotelNew) error {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	*queue.events = append(*queue.events, hotelNew)
+	return nil
+}
+
+// Events devuelve una copia de los avisos publicados, en orden.
+func (queue Mock) Events() []hotels.HotelNew {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	return append([]hotels.HotelNew(nil), *queue.events...)
 }
 
-func (Mock) Publish(hotelNew hotels.HotelNew) error {
+// ReservationsMock guarda los eventos publicados para que los tests puedan revisarlos.
+type ReservationsMock struct {
+	mutex  *sync.Mutex
+	events *[]reservations.Event
+}
+
+func NewReservationsMock() ReservationsMock {
+	return ReservationsMock{
+		mutex:  &sync.Mutex{},
+		events: &[]reservations.Event{},
+	}
+}
+
+func (queue ReservationsMock) Publish(event reservations.Event) error {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	*queue.events = append(*queue.events, event)
 	return nil
 }
+
+// Events devuelve una copia de los eventos publicados, en orden.
+func (queue ReservationsMock) Events() []reservations.Event {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	return append([]reservations.Event(nil), *queue.events...)
+}
@@ -5,33 +5,34 @@ import (
 	"fmt"
 	"github.com/streadway/amqp"
 	"hotels-api/domain/hotels"
+	"hotels-api/domain/reservations"
 	"log"
 )
 
+// RabbitConfig indica dónde publicar: NewRabbit publica directo en la cola QueueName y
+// NewReservationsRabbit en el exchange fanout Exchange, sin declarar colas.
 type RabbitConfig struct {
 	Host      string
 	Port      string
 	Username  string
 	Password  string
+	Exchange  string
 	QueueName string
 }
 
 type Rabbit struct {
 	connection *amqp.Connection
 	channel    *amqp.Channel
+	exchange   string
 	queue      amqp.Queue
 }
 
 func NewRabbit(config RabbitConfig) Rabbit {
-	connection, err := amqp.Dial(fmt.Sprintf("amqp://%s:%s@%s:%s/", config.Username, config.Password, config.Host, config.Port))
-	if err != nil {
-		log.Fatalf("error getting Rabbit connection: %w", err)
-	}
-	channel, err := connection.Channel()
+	connection, channel := connect(config)
+	queue, err := channel.QueueDeclare(config.QueueName, false, false, false, false, nil)
 	if err != nil {
-		log.Fatalf("error creating Rabbit channel: %w", err)
+		log.Fatalf("error declaring Rabbit queue: %v", err)
 	}
-	queue, err := channel.QueueDeclare(config.QueueName, false, false, false, false, nil)
 	return Rabbit{
 		connection: connection,
 		channel:    channel,
@@ -39,13 +40,29 @@ func NewRabbit(config RabbitConfig) Rabbit {
 	}
 }
 
+func connect(config RabbitConfig) (*amqp.Connection, *amqp.Channel) {
+	connection, err := amqp.Dial(fmt.Sprintf("amqp://%s:%s@%s:%s/", config.Username, config.Password, config.Host, config.Port))
+	if err != nil {
+		log.Fatalf("error getting Rabbit connection: %v", err)
+	}
+	channel, err := connection.Channel()
+	if err != nil {
+		log.Fatalf("error creating Rabbit channel: %v", err)
+	}
+	return connection, channel
+}
+
 func (queue Rabbit) Publish(hotelNew hotels.HotelNew) error {
 	bytes, err := json.Marshal(hotelNew)
 	if err != nil {
 		return fmt.Errorf("error marshaling Rabbit hotelNew: %w", err)
 	}
+	return queue.publish(bytes)
+}
+
+func (queue Rabbit) publish(bytes []byte) error {
 	if err := queue.channel.Publish(
-		"",
+		queue.exchange,
 		queue.queue.Name,
 		false,
 		false,
@@ -67,3 +84,34 @@ func (queue Rabbit) Close() {
 		log.Printf("error closing Rabbit connection: %v", err)
 	}
 }
+
+// ReservationsRabbit publica los eventos de reservas en el exchange fanout config.Exchange. No
+// declara ninguna cola: cada servicio que los consume declara la suya y la une al exchange.
+type ReservationsRabbit struct {
+	rabbit Rabbit
+}
+
+func NewReservationsRabbit(config RabbitConfig) ReservationsRabbit {
+	connection, channel := connect(config)
+	if err := channel.ExchangeDeclare(config.Exchange, amqp.ExchangeFanout, true, false, false, false, nil); err != nil {
+		log.Fatalf("error declaring Rabbit exchange: %v", err)
+	}
+	// Sin cola propia la clave de ruteo queda vacía, que es lo que espera un fanout
+	return ReservationsRabbit{rabbit: Rabbit{
+		connection: connection,
+		channel:    channel,
+		exchange:   config.Exchange,
+	}}
+}
+
+func (queue ReservationsRabbit) Publish(event reservations.Event) error {
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshaling Rabbit reservation event: %w", err)
+	}
+	return queue.rabbit.publish(bytes)
+}
+
+func (queue ReservationsRabbit) Close() {
+	queue.rabbit.Close()
+}
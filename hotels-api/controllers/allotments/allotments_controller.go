@@ -0,0 +1,109 @@
+package allotments
+
+import (
+	"context"
+	"errors"
+	"hotels-api/domain/allotments"
+	"hotels-api/domain/reservations"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	Create(ctx context.Context, allotment allotments.Allotment, now time.Time) (allotments.Allotment, error)
+	GetByHotelID(ctx context.Context, hotelID string) ([]allotments.Allotment, error)
+	Get(ctx context.Context, hotelID, id string) (allotments.Allotment, error)
+	Release(ctx context.Context, hotelID, id string, now time.Time) (allotments.Allotment, error)
+}
+
+type Controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return Controller{service: service}
+}
+
+type createRequest struct {
+	RoomTypeID  string            `json:"room_type_id" binding:"required"`
+	Code        string            `json:"code"`
+	Name        string            `json:"name" binding:"required"`
+	StartDate   reservations.Date `json:"start_date"`
+	EndDate     reservations.Date `json:"end_date"`
+	Rooms       int               `json:"rooms" binding:"required"`
+	ReleaseDate reservations.Date `json:"release_date"`
+}
+
+// Crea un cupo para un grupo. Sin code se genera uno para repartir entre los huéspedes
+func (c Controller) Create(ctx *gin.Context) {
+	var request createRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	allotment, err := c.service.Create(ctx.Request.Context(), allotments.Allotment{
+		HotelID:     strings.TrimSpace(ctx.Param("hotel_id")),
+		RoomTypeID:  strings.TrimSpace(request.RoomTypeID),
+		Code:        request.Code,
+		Name:        request.Name,
+		StartDate:   request.StartDate,
+		EndDate:     request.EndDate,
+		Rooms:       request.Rooms,
+		ReleaseDate: request.ReleaseDate,
+	}, time.Now().UTC())
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, allotment)
+}
+
+func (c Controller) GetAll(ctx *gin.Context) {
+	result, err := c.service.GetByHotelID(ctx.Request.Context(), strings.TrimSpace(ctx.Param("hotel_id")))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching room blocks"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Devuelve el cupo con las habitaciones usadas cada noche
+func (c Controller) Get(ctx *gin.Context) {
+	allotment, err := c.service.Get(ctx.Request.Context(), strings.TrimSpace(ctx.Param("hotel_id")), strings.TrimSpace(ctx.Param("allotment_id")))
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, allotment)
+}
+
+// Libera el cupo antes de su fecha y devuelve a la venta lo que no se usó
+func (c Controller) Release(ctx *gin.Context) {
+	allotment, err := c.service.Release(ctx.Request.Context(), strings.TrimSpace(ctx.Param("hotel_id")), strings.TrimSpace(ctx.Param("allotment_id")), time.Now().UTC())
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, allotment)
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, allotments.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, allotments.ErrInvalidAllotment), errors.Is(err, reservations.ErrUnknownRoomType):
+		return http.StatusBadRequest
+	case errors.Is(err, allotments.ErrBlockClosed), errors.Is(err, reservations.ErrNoAvailability):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
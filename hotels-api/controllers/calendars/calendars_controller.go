@@ -0,0 +1,128 @@
+package calendars
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/calendars"
+	middleware "hotels-api/middlewares"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	IssueToken(ctx context.Context, scope, subjectID string) (calendars.Feed, error)
+	CheckToken(ctx context.Context, scope, subjectID, token string) error
+	Events(ctx context.Context, scope, subjectID string, now time.Time, fn func(calendars.Event) error) error
+}
+
+type Controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return Controller{service: service}
+}
+
+// Genera la URL de suscripción al calendario del huésped; la anterior deja de funcionar
+func (c Controller) IssueUserToken(ctx *gin.Context) {
+	userID := strings.TrimSpace(ctx.Param("user_id"))
+	if !middleware.CanAccess(ctx, userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: not the owner of this calendar"})
+		return
+	}
+	c.issueToken(ctx, calendars.ScopeUser, userID, "/users/%s/reservations.ics")
+}
+
+// Genera la URL de suscripción a las llegadas del hotel para el personal
+func (c Controller) IssueHotelToken(ctx *gin.Context) {
+	c.issueToken(ctx, calendars.ScopeHotel, strings.TrimSpace(ctx.Param("hotel_id")), "/hotels/%s/reservations.ics")
+}
+
+func (c Controller) issueToken(ctx *gin.Context, scope, subjectID, path string) {
+	feed, err := c.service.IssueToken(ctx.Request.Context(), scope, subjectID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("error issuing calendar token: %s", err.Error())})
+		return
+	}
+
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	ctx.JSON(http.StatusCreated, gin.H{
+		"url":        fmt.Sprintf("%s://%s%s?token=%s", scheme, ctx.Request.Host, fmt.Sprintf(path, subjectID), feed.Token),
+		"created_at": feed.CreatedAt,
+	})
+}
+
+// Feed .ics con las estadías del huésped. Acepta el JWT del dueño o el token de la URL de suscripción
+func (c Controller) UserFeed(ctx *gin.Context) {
+	userID := strings.TrimSpace(ctx.Param("user_id"))
+	if !c.authorizeFeed(ctx, calendars.ScopeUser, userID, middleware.CanAccess(ctx, userID)) {
+		return
+	}
+	c.render(ctx, calendars.ScopeUser, userID, "Mis reservas", func(event calendars.Event) string {
+		return "Estadía en " + event.HotelName
+	})
+}
+
+// Feed .ics con las llegadas del hotel. Acepta el JWT de un administrador o el token del hotel
+func (c Controller) HotelFeed(ctx *gin.Context) {
+	hotelID := strings.TrimSpace(ctx.Param("hotel_id"))
+	if !c.authorizeFeed(ctx, calendars.ScopeHotel, hotelID, middleware.IsAdmin(ctx)) {
+		return
+	}
+	c.render(ctx, calendars.ScopeHotel, hotelID, "Llegadas", func(event calendars.Event) string {
+		return fmt.Sprintf("Llegada: %d huéspedes, %s", event.Reservation.Guests, strings.Join(event.RoomTypes, ", "))
+	})
+}
+
+// authorizeFeed valida el token de la query si vino uno; si no, decide allowed, calculado con el JWT.
+func (c Controller) authorizeFeed(ctx *gin.Context, scope, subjectID string, allowed bool) bool {
+	token := ctx.Query("token")
+	if token == "" {
+		if !allowed {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: cannot read this calendar"})
+		}
+		return allowed
+	}
+
+	err := c.service.CheckToken(ctx.Request.Context(), scope, subjectID, token)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, calendars.ErrInvalidToken):
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid calendar token"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("error checking calendar token: %s", err.Error())})
+	}
+	return false
+}
+
+// render escribe el VCALENDAR a medida que se leen las reservas, como la exportación CSV.
+func (c Controller) render(ctx *gin.Context, scope, subjectID, name string, summary func(calendars.Event) string) {
+	ctx.Header("Content-Type", "text/calendar; charset=utf-8")
+	ctx.Header("Content-Disposition", `inline; filename="reservations.ics"`)
+	ics := newICSWriter(ctx.Writer, name)
+
+	events := 0
+	err := c.service.Events(ctx.Request.Context(), scope, subjectID, time.Now().UTC(), func(event calendars.Event) error {
+		events++
+		ics.event(event, summary(event))
+		if events%100 == 0 {
+			return ics.flush()
+		}
+		return nil
+	})
+	if closeErr := ics.close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// La respuesta ya empezó a enviarse, solo queda registrar el error
+		_ = ctx.Error(fmt.Errorf("error exporting calendar: %w", err))
+	}
+}
@@ -0,0 +1,98 @@
+package calendars
+
+import (
+	"bufio"
+	"fmt"
+	"hotels-api/domain/calendars"
+	"hotels-api/domain/reservations"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Formatos de fecha de RFC 5545: los eventos ocupan días completos y las marcas de tiempo van en UTC
+const (
+	icsDateLayout     = "20060102"
+	icsDateTimeLayout = "20060102T150405Z"
+	icsMaxLineOctets  = 75
+)
+
+// icsWriter escribe un VCALENDAR con CRLF y plegando las líneas largas como pide RFC 5545.
+type icsWriter struct {
+	writer *bufio.Writer
+}
+
+func newICSWriter(w io.Writer, name string) *icsWriter {
+	ics := &icsWriter{writer: bufio.NewWriter(w)}
+	ics.line("BEGIN:VCALENDAR")
+	ics.line("VERSION:2.0")
+	ics.line("PRODID:-//hotels-api//Reservas//ES")
+	ics.line("CALSCALE:GREGORIAN")
+	ics.line("METHOD:PUBLISH")
+	ics.line("X-WR-CALNAME:" + icsEscape(name))
+	return ics
+}
+
+// event escribe la estadía como un evento de día completo; DTEND es el día de salida, que RFC 5545
+// no incluye en el evento, así que el calendario muestra exactamente las noches reservadas.
+func (ics *icsWriter) event(event calendars.Event, summary string) {
+	reservation := event.Reservation
+	status := "CONFIRMED"
+	if reservation.Status == reservations.StatusPending {
+		status = "TENTATIVE"
+	}
+	description := []string{
+		"Reserva " + reservation.ID,
+		"Habitaciones: " + strings.Join(event.RoomTypes, ", "),
+		fmt.Sprintf("Huéspedes: %d", reservation.Guests),
+		"Estado: " + reservation.Status,
+	}
+
+	ics.line("BEGIN:VEVENT")
+	ics.line("UID:" + reservation.ID + "@hotels-api")
+	// DTSTAMP usa el último cambio para que el feed no cambie si la reserva no cambió
+	ics.line("DTSTAMP:" + event.LastModified.UTC().Format(icsDateTimeLayout))
+	ics.line("CREATED:" + reservation.CreatedAt.UTC().Format(icsDateTimeLayout))
+	ics.line("LAST-MODIFIED:" + event.LastModified.UTC().Format(icsDateTimeLayout))
+	ics.line(fmt.Sprintf("SEQUENCE:%d", reservation.Version))
+	ics.line("DTSTART;VALUE=DATE:" + reservation.StartDate.Format(icsDateLayout))
+	ics.line("DTEND;VALUE=DATE:" + reservation.EndDate.Format(icsDateLayout))
+	ics.line("SUMMARY:" + icsEscape(summary))
+	if event.Location != "" {
+		ics.line("LOCATION:" + icsEscape(event.Location))
+	}
+	ics.line("DESCRIPTION:" + icsEscape(strings.Join(description, "\n")))
+	ics.line("STATUS:" + status)
+	ics.line("END:VEVENT")
+}
+
+func (ics *icsWriter) close() error {
+	ics.line("END:VCALENDAR")
+	return ics.writer.Flush()
+}
+
+func (ics *icsWriter) flush() error {
+	return ics.writer.Flush()
+}
+
+// line escribe una línea de contenido partida cada 75 octetos, sin cortar caracteres UTF-8; las
+// continuaciones empiezan con un espacio.
+func (ics *icsWriter) line(value string) {
+	limit := icsMaxLineOctets
+	for len(value) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(value[cut]) {
+			cut--
+		}
+		ics.writer.WriteString(value[:cut] + "\r\n ")
+		value = value[cut:]
+		// El espacio de la continuación cuenta dentro de los 75 octetos
+		limit = icsMaxLineOctets - 1
+	}
+	ics.writer.WriteString(value + "\r\n")
+}
+
+// icsEscape escapa un valor TEXT de RFC 5545.
+func icsEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
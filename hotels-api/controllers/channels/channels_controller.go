@@ -0,0 +1,113 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"hotels-api/clients/ical"
+	"hotels-api/domain/channels"
+	"hotels-api/domain/reservations"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	CreateFeed(ctx context.Context, feed channels.Feed, now time.Time) (channels.Feed, error)
+	GetFeedsByHotelID(ctx context.Context, hotelID string) ([]channels.Feed, error)
+	GetHolds(ctx context.Context, hotelID, feedID string) ([]channels.Hold, error)
+	DeleteFeed(ctx context.Context, hotelID, feedID string) error
+	SyncFeed(ctx context.Context, hotelID, feedID string, now time.Time) (channels.Sync, error)
+}
+
+type Controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return Controller{service: service}
+}
+
+type createFeedRequest struct {
+	Name       string `json:"name" binding:"required"`
+	URL        string `json:"url" binding:"required"`
+	RoomTypeID string `json:"room_type_id"`
+}
+
+// Registra el calendario iCal de otro canal. Sin room_type_id cada evento cierra el hotel entero
+func (c Controller) CreateFeed(ctx *gin.Context) {
+	var request createFeedRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := c.service.CreateFeed(ctx.Request.Context(), channels.Feed{
+		HotelID:    strings.TrimSpace(ctx.Param("hotel_id")),
+		RoomTypeID: strings.TrimSpace(request.RoomTypeID),
+		Name:       request.Name,
+		URL:        request.URL,
+	}, time.Now().UTC())
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, feed)
+}
+
+func (c Controller) GetFeeds(ctx *gin.Context) {
+	feeds, err := c.service.GetFeedsByHotelID(ctx.Request.Context(), strings.TrimSpace(ctx.Param("hotel_id")))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching iCal feeds"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, feeds)
+}
+
+// Bloqueos importados del feed, incluidos los que chocan con noches ya vendidas
+func (c Controller) GetHolds(ctx *gin.Context) {
+	holds, err := c.service.GetHolds(ctx.Request.Context(), strings.TrimSpace(ctx.Param("hotel_id")), strings.TrimSpace(ctx.Param("feed_id")))
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, holds)
+}
+
+// Borra el feed y libera las noches que bloqueaba
+func (c Controller) DeleteFeed(ctx *gin.Context) {
+	if err := c.service.DeleteFeed(ctx.Request.Context(), strings.TrimSpace(ctx.Param("hotel_id")), strings.TrimSpace(ctx.Param("feed_id"))); err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Importa el feed en el momento, sin esperar a la sincronización periódica
+func (c Controller) SyncFeed(ctx *gin.Context) {
+	sync, err := c.service.SyncFeed(ctx.Request.Context(), strings.TrimSpace(ctx.Param("hotel_id")), strings.TrimSpace(ctx.Param("feed_id")), time.Now().UTC())
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sync)
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, channels.ErrFeedNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, channels.ErrInvalidFeed), errors.Is(err, reservations.ErrUnknownRoomType):
+		return http.StatusBadRequest
+	case errors.Is(err, ical.ErrInvalidCalendar), errors.Is(err, ical.ErrUnavailable):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
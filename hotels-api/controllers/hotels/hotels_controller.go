@@ -2,9 +2,11 @@ package hotels
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	hotelsDomain "hotels-api/domain/hotels"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +17,7 @@ type Service interface {
 	Create(ctx context.Context, hotel hotelsDomain.Hotel) (string, error)
 	Update(ctx context.Context, hotel hotelsDomain.Hotel) error
 	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, filter hotelsDomain.ListFilter) (hotelsDomain.ListResult, error)
 }
 
 type Controller struct {
@@ -44,6 +47,51 @@ func (controller Controller) GetHotelByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, hotel)
 }
 
+// GetAll lista los hoteles con filtros por ciudad, provincia, calificación mínima y comodidades
+// (separadas por coma), paginados con limit y offset. sort=rating ordena ascendente y
+// sort=-rating descendente.
+func (controller Controller) GetAll(ctx *gin.Context) {
+	filter := hotelsDomain.ListFilter{
+		City:  ctx.Query("city"),
+		State: ctx.Query("state"),
+	}
+	if amenities := ctx.Query("amenities"); amenities != "" {
+		filter.Amenities = strings.Split(amenities, ",")
+	}
+	var err error
+	if minRating := ctx.Query("min_rating"); minRating != "" {
+		if filter.MinRating, err = strconv.ParseFloat(minRating, 64); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_rating"})
+			return
+		}
+	}
+	if limit := ctx.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+	if offset := ctx.Query("offset"); offset != "" {
+		if filter.Offset, err = strconv.Atoi(offset); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+			return
+		}
+	}
+	sort := ctx.Query("sort")
+	filter.SortDesc = strings.HasPrefix(sort, "-")
+	filter.SortBy = strings.TrimPrefix(sort, "-")
+
+	result, err := controller.service.List(ctx.Request.Context(), filter)
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{
+			"error": fmt.Sprintf("error listing hotels: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
 func (controller Controller) Create(ctx *gin.Context) {
 	// Parse hotel
 	var hotel hotelsDomain.Hotel
@@ -57,7 +105,7 @@ func (controller Controller) Create(ctx *gin.Context) {
 	// Create hotel
 	id, err := controller.service.Create(ctx.Request.Context(), hotel)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
+		ctx.JSON(statusFor(err), gin.H{
 			"error": fmt.Sprintf("error creating hotel: %s", err.Error()),
 		})
 		return
@@ -87,7 +135,7 @@ func (controller Controller) Update(ctx *gin.Context) {
 
 	// Actualiza el hotel
 	if err := controller.service.Update(ctx.Request.Context(), hotel); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
+		ctx.JSON(statusFor(err), gin.H{
 			"error": fmt.Sprintf("error updating hotel: %s", err.Error()),
 		})
 		return
@@ -116,3 +164,12 @@ func (controller Controller) Delete(ctx *gin.Context) {
 		"message": id,
 	})
 }
+
+func statusFor(err error) int {
+	if errors.Is(err, hotelsDomain.ErrInvalidPolicy) || errors.Is(err, hotelsDomain.ErrInvalidSchedule) ||
+		errors.Is(err, hotelsDomain.ErrInvalidTax) || errors.Is(err, hotelsDomain.ErrInvalidFilter) ||
+		errors.Is(err, hotelsDomain.ErrInvalidClear) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,54 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pricingDomain "hotels-api/domain/pricing"
+	"hotels-api/domain/promotions"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	Quote(ctx context.Context, hotelID string, roomTypeID string, startDate string, endDate string, guests int, promoCode string) ([]pricingDomain.Quote, error)
+}
+
+type Controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return Controller{
+		service: service,
+	}
+}
+
+func (controller Controller) Quote(ctx *gin.Context) {
+	hotelID := strings.TrimSpace(ctx.Param("hotel_id"))
+	guests, err := strconv.Atoi(ctx.DefaultQuery("guests", "1"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("invalid guests: %s", err.Error()),
+		})
+		return
+	}
+
+	quotes, err := controller.service.Quote(ctx.Request.Context(), hotelID, ctx.Query("room_type"), ctx.Query("start"), ctx.Query("end"), guests, ctx.Query("promo_code"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, pricingDomain.ErrInvalidQuote) || errors.Is(err, pricingDomain.ErrNoRatePlan) ||
+			errors.Is(err, promotions.ErrNotFound) || errors.Is(err, promotions.ErrNotApplicable) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, gin.H{
+			"error": fmt.Sprintf("error quoting stay: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quotes)
+}
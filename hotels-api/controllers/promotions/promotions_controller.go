@@ -0,0 +1,74 @@
+package promotions
+
+import (
+	"context"
+	"errors"
+	"hotels-api/domain/promotions"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	Create(ctx context.Context, promotion promotions.Promotion, now time.Time) (promotions.Promotion, error)
+	GetAll(ctx context.Context) ([]promotions.Promotion, error)
+	GetByID(ctx context.Context, id string) (promotions.Promotion, error)
+}
+
+type Controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return Controller{service: service}
+}
+
+// Crea un código de descuento; los usos los cuenta el servicio, nunca el cliente
+func (c Controller) Create(ctx *gin.Context) {
+	var promotion promotions.Promotion
+	if err := ctx.ShouldBindJSON(&promotion); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := c.service.Create(ctx.Request.Context(), promotion, time.Now().UTC())
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, created)
+}
+
+func (c Controller) GetAll(ctx *gin.Context) {
+	result, err := c.service.GetAll(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching promotions"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c Controller) GetByID(ctx *gin.Context) {
+	promotion, err := c.service.GetByID(ctx.Request.Context(), strings.TrimSpace(ctx.Param("promotion_id")))
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, promotion)
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, promotions.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, promotions.ErrInvalidPromotion):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
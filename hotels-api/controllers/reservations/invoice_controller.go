@@ -0,0 +1,30 @@
+package reservations
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Factura de la reserva en JSON, o en PDF con ?format=pdf o Accept: application/pdf. La primera
+// descarga la emite; las siguientes devuelven el mismo documento.
+func (c Controller) Invoice(ctx *gin.Context) {
+	if !c.authorize(ctx) {
+		return
+	}
+
+	invoice, err := c.service.Invoice(ctx.Request.Context(), strings.TrimSpace(ctx.Param("id")))
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if ctx.Query("format") == "pdf" || strings.Contains(ctx.GetHeader("Accept"), "application/pdf") {
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=factura-%s.pdf", invoice.Number))
+		ctx.Data(http.StatusOK, "application/pdf", renderInvoicePDF(invoice))
+		return
+	}
+	ctx.JSON(http.StatusOK, invoice)
+}
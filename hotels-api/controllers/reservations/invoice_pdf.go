@@ -0,0 +1,254 @@
+package reservations
+
+import (
+	"bytes"
+	"fmt"
+	"hotels-api/domain/invoices"
+	"hotels-api/domain/reservations"
+	"strings"
+)
+
+// Página A4 en puntos y márgenes de la factura
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+	pdfLineHeight = 14
+)
+
+// Columnas de la tabla de noches: descripción a la izquierda y montos alineados a la derecha
+const (
+	pdfColumnDate     = 260
+	pdfColumnQuantity = 380
+	pdfColumnUnit     = 465
+	pdfColumnAmount   = pdfPageWidth - pdfMargin
+)
+
+// renderInvoicePDF genera la factura como PDF sin dependencias externas, con las fuentes estándar
+// Helvetica que todo lector trae. El documento depende solo de la factura, así que descargarla de
+// nuevo devuelve exactamente los mismos bytes.
+func renderInvoicePDF(invoice invoices.Invoice) []byte {
+	document := newPDFDocument()
+
+	document.text(pdfMargin, 18, true, invoice.HotelName)
+	document.rightText(pdfColumnAmount, 18, true, "Factura N° "+invoice.Number)
+	document.newLine(8)
+	document.text(pdfMargin, 10, false, invoice.HotelAddress)
+	document.rightText(pdfColumnAmount, 10, false, "Emitida el "+invoice.IssuedAt.Format(reservations.DateLayout))
+	if invoice.HotelTaxID != "" {
+		document.newLine(0)
+		document.text(pdfMargin, 10, false, "CUIT "+invoice.HotelTaxID)
+	}
+	document.newLine(14)
+	document.text(pdfMargin, 10, false, "Reserva "+invoice.ReservationID)
+	document.newLine(0)
+	document.text(pdfMargin, 10, false, fmt.Sprintf("Estadía del %s al %s", invoice.StartDate.Format(reservations.DateLayout), invoice.EndDate.Format(reservations.DateLayout)))
+	document.newLine(18)
+
+	header := func() {
+		document.text(pdfMargin, 10, true, "Habitación")
+		document.text(pdfColumnDate, 10, true, "Noche")
+		document.rightText(pdfColumnQuantity, 10, true, "Cant.")
+		document.rightText(pdfColumnUnit, 10, true, "Tarifa")
+		document.rightText(pdfColumnAmount, 10, true, "Importe")
+		document.newLine(4)
+		document.rule()
+	}
+	header()
+	for _, line := range invoice.Lines {
+		if document.newLine(0) {
+			header()
+			document.newLine(0)
+		}
+		document.text(pdfMargin, 10, false, line.Description)
+		document.text(pdfColumnDate, 10, false, line.Date)
+		document.rightText(pdfColumnQuantity, 10, false, fmt.Sprintf("%d", line.Quantity))
+		document.rightText(pdfColumnUnit, 10, false, formatAmount(line.UnitPrice))
+		document.rightText(pdfColumnAmount, 10, false, formatAmount(line.Amount))
+	}
+	document.newLine(4)
+	document.rule()
+
+	totals := [][2]string{{"Subtotal", formatAmount(invoice.Subtotal)}}
+	if invoice.Discount > 0 {
+		totals = append(totals, [2]string{"Descuento por estadía", formatAmount(-invoice.Discount)})
+	}
+	if invoice.PromoDiscount > 0 {
+		totals = append(totals, [2]string{"Código " + invoice.PromoCode, formatAmount(-invoice.PromoDiscount)})
+	}
+	if invoice.PointsDiscount > 0 {
+		totals = append(totals, [2]string{fmt.Sprintf("Puntos canjeados (%d)", invoice.PointsRedeemed), formatAmount(-invoice.PointsDiscount)})
+	}
+	if invoice.TaxPercent > 0 {
+		totals = append(totals,
+			[2]string{"Neto gravado", formatAmount(invoice.Net)},
+			[2]string{fmt.Sprintf("Impuesto incluido (%s%%)", strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", invoice.TaxPercent), "0"), ".")), formatAmount(invoice.Tax)},
+		)
+	}
+	totals = append(totals, [2]string{"Total " + invoice.Currency, formatAmount(invoice.Total)})
+	for i, total := range totals {
+		document.newLine(0)
+		bold := i == len(totals)-1
+		document.rightText(pdfColumnUnit, 10, bold, total[0])
+		document.rightText(pdfColumnAmount, 10, bold, total[1])
+	}
+
+	document.newLine(18)
+	document.text(pdfMargin, 12, true, "Pagos")
+	document.newLine(4)
+	if len(invoice.Payments) == 0 {
+		document.newLine(0)
+		document.text(pdfMargin, 10, false, "Sin pagos registrados")
+	}
+	for _, payment := range invoice.Payments {
+		document.newLine(0)
+		label := "Cobro"
+		if payment.Amount < 0 {
+			label = "Reintegro"
+		}
+		document.text(pdfMargin, 10, false, fmt.Sprintf("%s %s", label, payment.Reference))
+		document.text(pdfColumnDate, 10, false, payment.At.Format(reservations.DateLayout))
+		document.rightText(pdfColumnAmount, 10, false, formatAmount(payment.Amount))
+	}
+	document.newLine(4)
+	document.newLine(0)
+	document.rightText(pdfColumnUnit, 10, false, "Pagado")
+	document.rightText(pdfColumnAmount, 10, false, formatAmount(invoice.Paid))
+	document.newLine(0)
+	document.rightText(pdfColumnUnit, 10, true, "Saldo "+invoice.Currency)
+	document.rightText(pdfColumnAmount, 10, true, formatAmount(invoice.BalanceDue))
+
+	return document.bytes()
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+// pdfDocument escribe el texto de arriba hacia abajo y abre una página nueva cuando se llega al
+// margen inferior. El texto se escribe siempre en el renglón actual.
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func newPDFDocument() *pdfDocument {
+	document := &pdfDocument{}
+	document.addPage()
+	return document
+}
+
+func (d *pdfDocument) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// newLine baja un renglón más extra puntos e indica si para eso hubo que empezar otra página.
+func (d *pdfDocument) newLine(extra float64) bool {
+	d.y -= pdfLineHeight + extra
+	if d.y < pdfMargin {
+		d.addPage()
+		return true
+	}
+	return false
+}
+
+func (d *pdfDocument) text(x, size float64, bold bool, value string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.pages[len(d.pages)-1], "BT /%s %.0f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, d.y, pdfEscape(value))
+}
+
+// rightText alinea el texto para que termine en x, con el ancho calculado según la fuente.
+func (d *pdfDocument) rightText(x, size float64, bold bool, value string) {
+	d.text(x-textWidth(value, size, bold), size, bold, value)
+}
+
+func (d *pdfDocument) rule() {
+	fmt.Fprintf(d.pages[len(d.pages)-1], "0.5 w %d %.2f m %d %.2f l S\n", pdfMargin, d.y-4, pdfPageWidth-pdfMargin, d.y-4)
+}
+
+// bytes arma el archivo: catálogo, árbol de páginas, las dos fuentes y, por cada página, el objeto
+// de la página y su contenido, seguidos de la tabla de referencias cruzadas.
+func (d *pdfDocument) bytes() []byte {
+	objects := []string{
+		"", // el árbol de páginas se completa cuando se conocen los números de objeto de las páginas
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, 0, len(d.pages))
+	for _, page := range d.pages {
+		pageNumber := len(objects) + 2
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageNumber))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, pageNumber+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()),
+		)
+	}
+	objects[0] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+	objects = append([]string{"<< /Type /Catalog /Pages 2 0 R >>"}, objects...)
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// pdfEscape pasa el texto a WinAnsi, que cubre los acentos y la ñ, y escapa los delimitadores.
+// Los caracteres que la codificación no tiene se reemplazan por "?".
+func pdfEscape(value string) string {
+	var out strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case r == '–':
+			out.WriteByte(0x96)
+		case r == '—':
+			out.WriteByte(0x97)
+		case r == '€':
+			out.WriteByte(0x80)
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			out.WriteByte(byte(r))
+		default:
+			out.WriteByte('?')
+		}
+	}
+	return out.String()
+}
+
+// textWidth estima el ancho del texto en puntos con las métricas de Helvetica, en milésimas del
+// tamaño de la fuente. Los dígitos y la puntuación de los montos tienen su ancho exacto; para
+// las letras alcanza con un promedio.
+func textWidth(value string, size float64, bold bool) float64 {
+	width := 0
+	for _, r := range value {
+		switch {
+		case r == '.', r == ',', r == ' ':
+			width += 278
+		case r == '-':
+			width += 333
+		case r >= 'A' && r <= 'Z':
+			width += 722
+		default:
+			width += 556
+		}
+	}
+	if bold {
+		width = width * 105 / 100
+	}
+	return float64(width) * size / 1000
+}
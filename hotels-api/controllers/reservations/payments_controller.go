@@ -0,0 +1,47 @@
+package reservations
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type payRequest struct {
+	PaymentMethod string `json:"payment_method" binding:"required"`
+}
+
+// Paga la seña de una reserva pendiente, por ejemplo una oferta de la lista de espera
+func (c Controller) Pay(ctx *gin.Context) {
+	var request payRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !c.authorize(ctx) {
+		return
+	}
+
+	reservation, err := c.service.Pay(ctx.Request.Context(), strings.TrimSpace(ctx.Param("id")), request.PaymentMethod)
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+// Estado del pago de la reserva con todas las operaciones hechas en la pasarela
+func (c Controller) GetPayment(ctx *gin.Context) {
+	if !c.authorize(ctx) {
+		return
+	}
+
+	payment, err := c.service.GetPayment(ctx.Request.Context(), strings.TrimSpace(ctx.Param("id")))
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payment)
+}
@@ -2,15 +2,43 @@ package reservations
 
 import (
 	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"hotels-api/domain/allotments"
+	"hotels-api/domain/invoices"
+	"hotels-api/domain/loyalty"
+	"hotels-api/domain/payments"
+	"hotels-api/domain/pricing"
+	"hotels-api/domain/promotions"
 	"hotels-api/domain/reservations"
+	middleware "hotels-api/middlewares"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Service interface {
 	CreateReservation(ctx context.Context, reservation reservations.Reservation) (string, error)
+	GetReservationByID(ctx context.Context, id string) (reservations.Reservation, error)
 	GetReservationsByUserID(ctx context.Context, userID string) ([]reservations.Reservation, error)
+	Transition(ctx context.Context, id string, to string) (reservations.Reservation, error)
+	Cancel(ctx context.Context, id string) (reservations.Reservation, error)
+	Modify(ctx context.Context, id string, request reservations.ModifyRequest) (reservations.Reservation, error)
+	Search(ctx context.Context, filter reservations.SearchFilter) (reservations.SearchResult, error)
+	Export(ctx context.Context, filter reservations.SearchFilter, fn func(reservations.Reservation) error) error
+	JoinWaitlist(ctx context.Context, entry reservations.WaitlistEntry) (string, error)
+	GetWaitlistEntry(ctx context.Context, id string) (reservations.WaitlistEntry, error)
+	GetWaitlistByUserID(ctx context.Context, userID string) ([]reservations.WaitlistEntry, error)
+	GetWaitlistByHotelID(ctx context.Context, hotelID string) ([]reservations.WaitlistEntry, error)
+	LeaveWaitlist(ctx context.Context, id string) (reservations.WaitlistEntry, error)
+	Availability(ctx context.Context, hotelID string, roomTypeID string, from, to reservations.Date) (reservations.Availability, error)
+	Pay(ctx context.Context, id string, paymentMethod string) (reservations.Reservation, error)
+	GetPayment(ctx context.Context, reservationID string) (payments.Payment, error)
+	Invoice(ctx context.Context, id string) (invoices.Invoice, error)
 }
 
 type Controller struct {
@@ -29,19 +57,59 @@ func (c Controller) CreateReservation(ctx *gin.Context) {
 		return
 	}
 
+	// El dueño de la reserva siempre es el usuario del token
+	reservation.UserID = middleware.UserID(ctx)
+
 	// Llamar al servicio para crear la reserva
 	id, err := c.service.CreateReservation(ctx.Request.Context(), reservation)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error creating reservation"})
+		switch {
+		case errors.Is(err, reservations.ErrInvalidDates), errors.Is(err, reservations.ErrUnknownRoomType),
+			errors.Is(err, reservations.ErrInvalidGuests), errors.Is(err, reservations.ErrInvalidItems),
+			errors.Is(err, pricing.ErrNoRatePlan), errors.Is(err, allotments.ErrNotFound), errors.Is(err, allotments.ErrOutsideBlock),
+			errors.Is(err, promotions.ErrNotFound), errors.Is(err, promotions.ErrNotApplicable), errors.Is(err, loyalty.ErrInvalidPoints):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, allotments.ErrBlockClosed), errors.Is(err, promotions.ErrExhausted), errors.Is(err, loyalty.ErrInsufficientPoints):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, loyalty.ErrUnavailable):
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		case errors.Is(err, reservations.ErrNoAvailability):
+			// Sin lugar el huésped puede anotarse en la lista de espera con los mismos datos
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "waitlist": "/waitlist"})
+		case errors.Is(err, payments.ErrPaymentRequired), errors.Is(err, payments.ErrDeclined):
+			ctx.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error creating reservation"})
+		}
 		return
 	}
 
 	ctx.JSON(http.StatusCreated, gin.H{"id": id})
 }
 
+// Obtener una reserva por ID
+func (c Controller) GetReservationByID(ctx *gin.Context) {
+	reservation, err := c.service.GetReservationByID(ctx.Request.Context(), strings.TrimSpace(ctx.Param("id")))
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+	if !middleware.CanAccess(ctx, reservation.UserID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: not the owner of this reservation"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservation)
+}
+
 // Obtener reservas del usuario autenticado
 func (c Controller) GetReservationsByUserID(ctx *gin.Context) {
 	userID := ctx.Param("user_id")
+	if !middleware.CanAccess(ctx, userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: not the owner of these reservations"})
+		return
+	}
+
 	reservations, err := c.service.GetReservationsByUserID(ctx.Request.Context(), userID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching reservations"})
@@ -50,3 +118,262 @@ func (c Controller) GetReservationsByUserID(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, reservations)
 }
+
+// Cambia fechas, tipo de habitación o huéspedes de la reserva
+func (c Controller) Modify(ctx *gin.Context) {
+	var request reservations.ModifyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !c.authorize(ctx) {
+		return
+	}
+
+	reservation, err := c.service.Modify(ctx.Request.Context(), strings.TrimSpace(ctx.Param("id")), request)
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+// Endpoints del ciclo de vida de la reserva
+func (c Controller) Confirm(ctx *gin.Context) {
+	if !c.authorize(ctx) {
+		return
+	}
+	c.transition(ctx, reservations.StatusConfirmed)
+}
+
+// Cancela la reserva y devuelve la penalidad y el reembolso calculados
+func (c Controller) Cancel(ctx *gin.Context) {
+	if !c.authorize(ctx) {
+		return
+	}
+	reservation, err := c.service.Cancel(ctx.Request.Context(), strings.TrimSpace(ctx.Param("id")))
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"reservation": reservation,
+		"penalty":     reservation.Cancellation.Penalty,
+		"refund":      reservation.Cancellation.Refund,
+	})
+}
+
+func (c Controller) CheckIn(ctx *gin.Context) {
+	c.transition(ctx, reservations.StatusCheckedIn)
+}
+
+func (c Controller) CheckOut(ctx *gin.Context) {
+	c.transition(ctx, reservations.StatusCheckedOut)
+}
+
+func (c Controller) NoShow(ctx *gin.Context) {
+	c.transition(ctx, reservations.StatusNoShow)
+}
+
+func (c Controller) transition(ctx *gin.Context, to string) {
+	reservation, err := c.service.Transition(ctx.Request.Context(), strings.TrimSpace(ctx.Param("id")), to)
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+// Calendario de disponibilidad por noche del hotel, opcionalmente de un solo tipo de habitación
+func (c Controller) Availability(ctx *gin.Context) {
+	from, err := reservations.ParseDate(ctx.Query("from"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid from: %s", err.Error())})
+		return
+	}
+	to, err := reservations.ParseDate(ctx.Query("to"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid to: %s", err.Error())})
+		return
+	}
+
+	availability, err := c.service.Availability(ctx.Request.Context(), strings.TrimSpace(ctx.Param("hotel_id")), strings.TrimSpace(ctx.Query("room_type")), from, to)
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, availability)
+}
+
+// Búsqueda de reservas para administradores, de todos los hoteles o del hotel de la URL
+func (c Controller) Search(ctx *gin.Context) {
+	filter, err := parseFilter(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := c.service.Search(ctx.Request.Context(), filter)
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Exporta en CSV, escribiendo cada fila a medida que se lee de la base
+func (c Controller) Export(ctx *gin.Context) {
+	filter, err := parseFilter(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// La respuesta empieza recién con la primera reserva, así un filtro inválido o una falla al
+	// consultar todavía se responden con su código de error
+	writer := csv.NewWriter(ctx.Writer)
+	started := false
+	start := func() error {
+		if started {
+			return nil
+		}
+		started = true
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		ctx.Header("Content-Disposition", `attachment; filename="reservations.csv"`)
+		ctx.Status(http.StatusOK)
+		return writer.Write([]string{"id", "hotel_id", "room_type_id", "user_id", "start_date", "end_date", "nights", "rooms", "guests", "status", "currency", "total", "created_at"})
+	}
+
+	rows := 0
+	err = c.service.Export(ctx.Request.Context(), filter, func(reservation reservations.Reservation) error {
+		if err := start(); err != nil {
+			return err
+		}
+		rows++
+		rooms := 0
+		for _, item := range reservation.LineItems() {
+			rooms += item.Quantity
+		}
+		if err := writer.Write([]string{
+			reservation.ID,
+			reservation.HotelID,
+			reservation.RoomTypeID,
+			reservation.UserID,
+			reservation.StartDate.String(),
+			reservation.EndDate.String(),
+			strconv.Itoa(reservation.StartDate.NightsUntil(reservation.EndDate)),
+			strconv.Itoa(rooms),
+			strconv.Itoa(reservation.Guests),
+			reservation.Status,
+			reservation.Currency,
+			strconv.FormatFloat(reservation.Total, 'f', 2, 64),
+			reservation.CreatedAt.Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+		if rows%100 == 0 {
+			writer.Flush()
+			ctx.Writer.Flush()
+		}
+		return writer.Error()
+	})
+	if err != nil && !started {
+		ctx.JSON(statusFor(err), gin.H{
+			"error": fmt.Sprintf("error exporting reservations: %s", err.Error()),
+		})
+		return
+	}
+	if err == nil {
+		// Sin reservas se envía solo el encabezado
+		err = start()
+	}
+	writer.Flush()
+	if err != nil {
+		// La respuesta ya empezó a enviarse, solo queda registrar el error
+		_ = ctx.Error(fmt.Errorf("error exporting reservations: %w", err))
+	}
+}
+
+func parseFilter(ctx *gin.Context) (reservations.SearchFilter, error) {
+	filter := reservations.SearchFilter{
+		HotelID: strings.TrimSpace(ctx.Query("hotel_id")),
+		UserID:  strings.TrimSpace(ctx.Query("user_id")),
+	}
+	if hotelID := strings.TrimSpace(ctx.Param("hotel_id")); hotelID != "" {
+		filter.HotelID = hotelID
+	}
+	if statuses := ctx.Query("status"); statuses != "" {
+		filter.Statuses = strings.Split(statuses, ",")
+	}
+
+	var err error
+	if from := ctx.Query("from"); from != "" {
+		if filter.From, err = reservations.ParseDate(from); err != nil {
+			return filter, err
+		}
+	}
+	if to := ctx.Query("to"); to != "" {
+		if filter.To, err = reservations.ParseDate(to); err != nil {
+			return filter, err
+		}
+	}
+	if limit := ctx.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return filter, fmt.Errorf("invalid limit: %w", reservations.ErrInvalidFilter)
+		}
+	}
+	if offset := ctx.Query("offset"); offset != "" {
+		if filter.Offset, err = strconv.Atoi(offset); err != nil {
+			return filter, fmt.Errorf("invalid offset: %w", reservations.ErrInvalidFilter)
+		}
+	}
+
+	// sort=start_date ordena ascendente, sort=-start_date descendente
+	sort := ctx.Query("sort")
+	filter.SortDesc = strings.HasPrefix(sort, "-")
+	filter.SortBy = strings.TrimPrefix(sort, "-")
+	return filter, nil
+}
+
+// authorize verifica que el usuario autenticado sea el dueño de la reserva o un administrador.
+func (c Controller) authorize(ctx *gin.Context) bool {
+	reservation, err := c.service.GetReservationByID(ctx.Request.Context(), strings.TrimSpace(ctx.Param("id")))
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return false
+	}
+	if !middleware.CanAccess(ctx, reservation.UserID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: not the owner of this reservation"})
+		return false
+	}
+	return true
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, reservations.ErrNotFound), errors.Is(err, reservations.ErrWaitlistNotFound),
+		errors.Is(err, payments.ErrNotFound), errors.Is(err, invoices.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, payments.ErrPaymentRequired), errors.Is(err, payments.ErrDeclined):
+		return http.StatusPaymentRequired
+	case errors.Is(err, reservations.ErrOfferExpired):
+		return http.StatusGone
+	case errors.Is(err, reservations.ErrInvalidTransition), errors.Is(err, reservations.ErrNoAvailability),
+		errors.Is(err, reservations.ErrNotModifiable), errors.Is(err, reservations.ErrConcurrentUpdate),
+		errors.Is(err, invoices.ErrNotInvoiceable), errors.Is(err, allotments.ErrBlockClosed):
+		return http.StatusConflict
+	case errors.Is(err, reservations.ErrInvalidFilter), errors.Is(err, reservations.ErrInvalidDates),
+		errors.Is(err, reservations.ErrUnknownRoomType), errors.Is(err, reservations.ErrInvalidGuests),
+		errors.Is(err, reservations.ErrInvalidItems), errors.Is(err, pricing.ErrNoRatePlan),
+		errors.Is(err, allotments.ErrOutsideBlock), errors.Is(err, promotions.ErrNotApplicable),
+		errors.Is(err, loyalty.ErrInvalidPoints):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
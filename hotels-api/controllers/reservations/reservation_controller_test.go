@@ -0,0 +1,263 @@
+package reservations_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	controller "hotels-api/controllers/reservations"
+	"hotels-api/domain/reservations"
+	middleware "hotels-api/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const secret = "test-secret"
+
+// stubService guarda lo que recibe el controlador y devuelve reservas fijas. Los métodos que no
+// redefine entran en pánico, así un test se entera si el controlador llega a un método inesperado.
+type stubService struct {
+	controller.Service
+	reservations map[string]reservations.Reservation
+	created      []reservations.Reservation
+	cancelled    []string
+	filters      []reservations.SearchFilter
+	exportErr    error
+}
+
+func newStubService(list ...reservations.Reservation) *stubService {
+	stub := &stubService{reservations: make(map[string]reservations.Reservation)}
+	for _, reservation := range list {
+		stub.reservations[reservation.ID] = reservation
+	}
+	return stub
+}
+
+func (s *stubService) CreateReservation(ctx context.Context, reservation reservations.Reservation) (string, error) {
+	s.created = append(s.created, reservation)
+	return "new", nil
+}
+
+func (s *stubService) GetReservationByID(ctx context.Context, id string) (reservations.Reservation, error) {
+	reservation, exists := s.reservations[id]
+	if !exists {
+		return reservations.Reservation{}, fmt.Errorf("reservation %s: %w", id, reservations.ErrNotFound)
+	}
+	return reservation, nil
+}
+
+func (s *stubService) GetReservationsByUserID(ctx context.Context, userID string) ([]reservations.Reservation, error) {
+	result := make([]reservations.Reservation, 0)
+	for _, reservation := range s.reservations {
+		if reservation.UserID == userID {
+			result = append(result, reservation)
+		}
+	}
+	return result, nil
+}
+
+func (s *stubService) Cancel(ctx context.Context, id string) (reservations.Reservation, error) {
+	s.cancelled = append(s.cancelled, id)
+	reservation := s.reservations[id]
+	reservation.Status = reservations.StatusCancelled
+	reservation.Cancellation = &reservations.Cancellation{}
+	return reservation, nil
+}
+
+// Export recorre las reservas ordenadas por ID, o falla con exportErr antes de la primera.
+func (s *stubService) Export(ctx context.Context, filter reservations.SearchFilter, fn func(reservations.Reservation) error) error {
+	s.filters = append(s.filters, filter)
+	if s.exportErr != nil {
+		return s.exportErr
+	}
+	ids := make([]string, 0, len(s.reservations))
+	for id := range s.reservations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := fn(s.reservations[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newRouter arma las rutas de reservas como main.go, con el JWTMiddleware real.
+func newRouter(service controller.Service) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	reservationsController := controller.NewController(service)
+	authenticate := middleware.NewJWTMiddleware(secret).Authenticate()
+
+	reservationRoutes := router.Group("/reservations")
+	reservationRoutes.Use(authenticate)
+	{
+		reservationRoutes.POST("", reservationsController.CreateReservation)
+		reservationRoutes.GET("/export.csv", middleware.AdminOnly(), reservationsController.Export)
+		reservationRoutes.GET("/:id", reservationsController.GetReservationByID)
+		reservationRoutes.POST("/:id/cancel", reservationsController.Cancel)
+	}
+	router.GET("/users/:user_id/reservations", authenticate, reservationsController.GetReservationsByUserID)
+	return router
+}
+
+// token firma un JWT como users-api, con el user_id numérico.
+func token(t *testing.T, userType string, userID int) string {
+	t.Helper()
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"tipo":    userType,
+		"user_id": float64(userID),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func request(router *gin.Engine, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestReservationRoutesRequireToken(t *testing.T) {
+	service := newStubService(reservations.Reservation{ID: "r1", UserID: "7"})
+	router := newRouter(service)
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodPost, "/reservations"},
+		{http.MethodGet, "/reservations/r1"},
+		{http.MethodPost, "/reservations/r1/cancel"},
+		{http.MethodGet, "/users/7/reservations"},
+	} {
+		if response := request(router, route.method, route.path, "", `{}`); response.Code != http.StatusUnauthorized {
+			t.Fatalf("%s %s: expected 401, got %d", route.method, route.path, response.Code)
+		}
+	}
+	if len(service.created) != 0 || len(service.cancelled) != 0 {
+		t.Fatal("expected the service not to be called without a token")
+	}
+}
+
+func TestCreateReservationUsesTheUserOfTheToken(t *testing.T) {
+	service := newStubService()
+	router := newRouter(service)
+
+	response := request(router, http.MethodPost, "/reservations", token(t, "cliente", 7), `{"hotel_id":"h1","user_id":"99"}`)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", response.Code, response.Body)
+	}
+	if len(service.created) != 1 || service.created[0].UserID != "7" {
+		t.Fatalf("expected the reservation to belong to user 7, got %+v", service.created)
+	}
+}
+
+func TestReservationAccess(t *testing.T) {
+	service := newStubService(reservations.Reservation{ID: "r1", UserID: "7"})
+	router := newRouter(service)
+
+	cases := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		status int
+	}{
+		{"owner reads", token(t, "cliente", 7), http.MethodGet, "/reservations/r1", http.StatusOK},
+		{"non-owner reads", token(t, "cliente", 8), http.MethodGet, "/reservations/r1", http.StatusForbidden},
+		{"admin reads", token(t, middleware.AdminUserType, 1), http.MethodGet, "/reservations/r1", http.StatusOK},
+		{"owner lists", token(t, "cliente", 7), http.MethodGet, "/users/7/reservations", http.StatusOK},
+		{"non-owner lists", token(t, "cliente", 8), http.MethodGet, "/users/7/reservations", http.StatusForbidden},
+		{"admin lists", token(t, middleware.AdminUserType, 1), http.MethodGet, "/users/7/reservations", http.StatusOK},
+		{"non-owner cancels", token(t, "cliente", 8), http.MethodPost, "/reservations/r1/cancel", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		if response := request(router, tc.method, tc.path, tc.token, ""); response.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d %s", tc.name, tc.status, response.Code, response.Body)
+		}
+	}
+	if len(service.cancelled) != 0 {
+		t.Fatalf("expected a non-owner not to cancel, got %v", service.cancelled)
+	}
+
+	if response := request(router, http.MethodPost, "/reservations/r1/cancel", token(t, "cliente", 7), ""); response.Code != http.StatusOK {
+		t.Fatalf("owner cancels: expected 200, got %d %s", response.Code, response.Body)
+	}
+	if len(service.cancelled) != 1 || service.cancelled[0] != "r1" {
+		t.Fatalf("expected the owner to cancel r1, got %v", service.cancelled)
+	}
+}
+
+func TestExportWritesHeaderAndRows(t *testing.T) {
+	start, err := reservations.ParseDate("2030-03-10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdAt := time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+	service := newStubService(
+		reservations.Reservation{
+			ID: "r1", HotelID: "h1", RoomTypeID: "double", UserID: "7",
+			StartDate: start, EndDate: start.AddDays(3), Guests: 2,
+			Status: reservations.StatusConfirmed, Currency: "ARS", Total: 300, CreatedAt: createdAt,
+		},
+		reservations.Reservation{
+			ID: "r2", HotelID: "h1", UserID: "8",
+			StartDate: start, EndDate: start.AddDays(1), Guests: 4,
+			Items:  []reservations.LineItem{{RoomTypeID: "double", Quantity: 2}, {RoomTypeID: "single", Quantity: 1}},
+			Status: reservations.StatusCancelled, Currency: "ARS", Total: 99.5, CreatedAt: createdAt,
+		},
+	)
+	router := newRouter(service)
+
+	response := request(router, http.MethodGet, "/reservations/export.csv?hotel_id=h1&status=confirmed,cancelled", token(t, middleware.AdminUserType, 1), "")
+	if response.Code != http.StatusOK || !strings.HasPrefix(response.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("expected a CSV, got %d %s", response.Code, response.Header().Get("Content-Type"))
+	}
+	expected := "id,hotel_id,room_type_id,user_id,start_date,end_date,nights,rooms,guests,status,currency,total,created_at\n" +
+		"r1,h1,double,7,2030-03-10,2030-03-13,3,1,2,confirmed,ARS,300.00,2030-01-02T15:04:05Z\n" +
+		"r2,h1,,8,2030-03-10,2030-03-11,1,3,4,cancelled,ARS,99.50,2030-01-02T15:04:05Z\n"
+	if response.Body.String() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, response.Body)
+	}
+	filter := service.filters[0]
+	if filter.HotelID != "h1" || len(filter.Statuses) != 2 {
+		t.Fatalf("expected the query to reach the service, got %+v", filter)
+	}
+
+	// Sin reservas se envía solo el encabezado
+	empty := request(newRouter(newStubService()), http.MethodGet, "/reservations/export.csv", token(t, middleware.AdminUserType, 1), "")
+	if empty.Code != http.StatusOK || strings.Count(empty.Body.String(), "\n") != 1 {
+		t.Fatalf("expected only the header, got %d %q", empty.Code, empty.Body)
+	}
+}
+
+func TestExportRejectsInvalidFilters(t *testing.T) {
+	service := newStubService()
+	router := newRouter(service)
+	admin := token(t, middleware.AdminUserType, 1)
+
+	if response := request(router, http.MethodGet, "/reservations/export.csv", token(t, "cliente", 7), ""); response.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a customer, got %d", response.Code)
+	}
+	if response := request(router, http.MethodGet, "/reservations/export.csv?limit=ten", admin, ""); response.Code != http.StatusBadRequest || len(service.filters) != 0 {
+		t.Fatalf("expected 400 before reaching the service, got %d", response.Code)
+	}
+
+	// Un filtro que rechaza el servicio se responde como error y no como CSV
+	service.exportErr = fmt.Errorf("cannot sort by %q: %w", "user_id", reservations.ErrInvalidFilter)
+	response := request(router, http.MethodGet, "/reservations/export.csv?sort=user_id", admin, "")
+	if response.Code != http.StatusBadRequest || strings.HasPrefix(response.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("expected a 400 JSON error, got %d %s", response.Code, response.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,92 @@
+package reservations
+
+import (
+	"hotels-api/domain/reservations"
+	middleware "hotels-api/middlewares"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Anota al usuario del token en la lista de espera de un tipo de habitación agotado
+func (c Controller) JoinWaitlist(ctx *gin.Context) {
+	var entry reservations.WaitlistEntry
+	if err := ctx.ShouldBindJSON(&entry); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entry.UserID = middleware.UserID(ctx)
+
+	id, err := c.service.JoinWaitlist(ctx.Request.Context(), entry)
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (c Controller) GetWaitlistEntry(ctx *gin.Context) {
+	entry, ok := c.authorizeWaitlist(ctx)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, entry)
+}
+
+// Saca la entrada de la lista de espera
+func (c Controller) LeaveWaitlist(ctx *gin.Context) {
+	if _, ok := c.authorizeWaitlist(ctx); !ok {
+		return
+	}
+
+	entry, err := c.service.LeaveWaitlist(ctx.Request.Context(), strings.TrimSpace(ctx.Param("id")))
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entry)
+}
+
+func (c Controller) GetWaitlistByUserID(ctx *gin.Context) {
+	userID := ctx.Param("user_id")
+	if !middleware.CanAccess(ctx, userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: not the owner of this waitlist"})
+		return
+	}
+
+	entries, err := c.service.GetWaitlistByUserID(ctx.Request.Context(), userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching waitlist"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// Lista de espera del hotel, para administradores
+func (c Controller) GetWaitlistByHotelID(ctx *gin.Context) {
+	entries, err := c.service.GetWaitlistByHotelID(ctx.Request.Context(), strings.TrimSpace(ctx.Param("hotel_id")))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching waitlist"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// authorizeWaitlist verifica que el usuario autenticado sea el dueño de la entrada o un administrador.
+func (c Controller) authorizeWaitlist(ctx *gin.Context) (reservations.WaitlistEntry, bool) {
+	entry, err := c.service.GetWaitlistEntry(ctx.Request.Context(), strings.TrimSpace(ctx.Param("id")))
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return reservations.WaitlistEntry{}, false
+	}
+	if !middleware.CanAccess(ctx, entry.UserID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: not the owner of this waitlist entry"})
+		return reservations.WaitlistEntry{}, false
+	}
+	return entry, true
+}
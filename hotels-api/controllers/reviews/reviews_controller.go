@@ -0,0 +1,150 @@
+package reviews
+
+import (
+	"context"
+	"errors"
+	"hotels-api/domain/reviews"
+	middleware "hotels-api/middlewares"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	Create(ctx context.Context, review reviews.Review, now time.Time) (reviews.Review, error)
+	GetPublished(ctx context.Context, hotelID string) ([]reviews.Review, error)
+	GetQueue(ctx context.Context) ([]reviews.Review, error)
+	Approve(ctx context.Context, id, moderatorID string, now time.Time) (reviews.Review, error)
+	Reject(ctx context.Context, id, moderatorID, reason string, now time.Time) (reviews.Review, error)
+	Reply(ctx context.Context, hotelID, id string, reply reviews.Reply) (reviews.Review, error)
+}
+
+type Controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return Controller{service: service}
+}
+
+type createRequest struct {
+	ReservationID string `json:"reservation_id" binding:"required"`
+	Score         int    `json:"score" binding:"required"`
+	Text          string `json:"text" binding:"required"`
+}
+
+// Reseña de una estadía terminada; queda pendiente hasta que un administrador la aprueba
+func (c Controller) Create(ctx *gin.Context) {
+	var request createRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := c.service.Create(ctx.Request.Context(), reviews.Review{
+		HotelID:       strings.TrimSpace(ctx.Param("hotel_id")),
+		ReservationID: strings.TrimSpace(request.ReservationID),
+		UserID:        middleware.UserID(ctx),
+		Score:         request.Score,
+		Text:          request.Text,
+	}, time.Now().UTC())
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, review)
+}
+
+func (c Controller) GetByHotelID(ctx *gin.Context) {
+	result, err := c.service.GetPublished(ctx.Request.Context(), strings.TrimSpace(ctx.Param("hotel_id")))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching reviews"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Cola de moderación: las reseñas pendientes de todos los hoteles
+func (c Controller) GetQueue(ctx *gin.Context) {
+	result, err := c.service.GetQueue(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching reviews"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c Controller) Approve(ctx *gin.Context) {
+	review, err := c.service.Approve(ctx.Request.Context(), strings.TrimSpace(ctx.Param("review_id")), middleware.UserID(ctx), time.Now().UTC())
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, review)
+}
+
+type rejectRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (c Controller) Reject(ctx *gin.Context) {
+	var request rejectRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := c.service.Reject(ctx.Request.Context(), strings.TrimSpace(ctx.Param("review_id")), middleware.UserID(ctx), request.Reason, time.Now().UTC())
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, review)
+}
+
+type replyRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// Respuesta pública del hotel a una reseña; responder de nuevo reemplaza la respuesta anterior
+func (c Controller) Reply(ctx *gin.Context) {
+	var request replyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := c.service.Reply(ctx.Request.Context(), strings.TrimSpace(ctx.Param("hotel_id")), strings.TrimSpace(ctx.Param("review_id")), reviews.Reply{
+		AuthorID: middleware.UserID(ctx),
+		Text:     request.Text,
+		At:       time.Now().UTC(),
+	})
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, review)
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, reviews.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, reviews.ErrInvalidReview):
+		return http.StatusBadRequest
+	case errors.Is(err, reviews.ErrNotEligible):
+		return http.StatusForbidden
+	case errors.Is(err, reviews.ErrAlreadyReviewed), errors.Is(err, reviews.ErrInvalidModeration):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,157 @@
+package rooms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pricingDomain "hotels-api/domain/pricing"
+	roomsDomain "hotels-api/domain/rooms"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	GetByHotelID(ctx context.Context, hotelID string) ([]roomsDomain.RoomType, error)
+	GetByID(ctx context.Context, hotelID string, id string) (roomsDomain.RoomType, error)
+	Create(ctx context.Context, roomType roomsDomain.RoomType) (string, error)
+	Update(ctx context.Context, roomType roomsDomain.RoomType) error
+	SetRatePlan(ctx context.Context, hotelID string, id string, plan pricingDomain.RatePlan) error
+	Delete(ctx context.Context, hotelID string, id string) error
+}
+
+type Controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return Controller{
+		service: service,
+	}
+}
+
+func (controller Controller) GetByHotelID(ctx *gin.Context) {
+	hotelID := strings.TrimSpace(ctx.Param("hotel_id"))
+
+	roomTypes, err := controller.service.GetByHotelID(ctx.Request.Context(), hotelID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("error getting room types: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, roomTypes)
+}
+
+func (controller Controller) GetByID(ctx *gin.Context) {
+	hotelID := strings.TrimSpace(ctx.Param("hotel_id"))
+	id := strings.TrimSpace(ctx.Param("room_type_id"))
+
+	roomType, err := controller.service.GetByID(ctx.Request.Context(), hotelID, id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("error getting room type: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, roomType)
+}
+
+func (controller Controller) Create(ctx *gin.Context) {
+	var roomType roomsDomain.RoomType
+	if err := ctx.ShouldBindJSON(&roomType); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("invalid request: %s", err.Error()),
+		})
+		return
+	}
+
+	// El hotel siempre se toma de la URL
+	roomType.HotelID = strings.TrimSpace(ctx.Param("hotel_id"))
+
+	id, err := controller.service.Create(ctx.Request.Context(), roomType)
+	if err != nil {
+		ctx.JSON(statusFor(err), gin.H{
+			"error": fmt.Sprintf("error creating room type: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"id": id,
+	})
+}
+
+func (controller Controller) Update(ctx *gin.Context) {
+	var roomType roomsDomain.RoomType
+	if err := ctx.ShouldBindJSON(&roomType); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("invalid request: %s", err.Error()),
+		})
+		return
+	}
+
+	roomType.HotelID = strings.TrimSpace(ctx.Param("hotel_id"))
+	roomType.ID = strings.TrimSpace(ctx.Param("room_type_id"))
+
+	if err := controller.service.Update(ctx.Request.Context(), roomType); err != nil {
+		ctx.JSON(statusFor(err), gin.H{
+			"error": fmt.Sprintf("error updating room type: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": roomType.ID,
+	})
+}
+
+func (controller Controller) SetRatePlan(ctx *gin.Context) {
+	var plan pricingDomain.RatePlan
+	if err := ctx.ShouldBindJSON(&plan); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("invalid request: %s", err.Error()),
+		})
+		return
+	}
+
+	hotelID := strings.TrimSpace(ctx.Param("hotel_id"))
+	id := strings.TrimSpace(ctx.Param("room_type_id"))
+
+	if err := controller.service.SetRatePlan(ctx.Request.Context(), hotelID, id, plan); err != nil {
+		ctx.JSON(statusFor(err), gin.H{
+			"error": fmt.Sprintf("error setting rate plan: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": id,
+	})
+}
+
+func (controller Controller) Delete(ctx *gin.Context) {
+	hotelID := strings.TrimSpace(ctx.Param("hotel_id"))
+	id := strings.TrimSpace(ctx.Param("room_type_id"))
+
+	if err := controller.service.Delete(ctx.Request.Context(), hotelID, id); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("error deleting room type: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": id,
+	})
+}
+
+func statusFor(err error) int {
+	if errors.Is(err, roomsDomain.ErrInvalidRoomType) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
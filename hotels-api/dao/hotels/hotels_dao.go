@@ -3,12 +3,34 @@ package hotels
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
 type Hotel struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty"`
-	Name        string             `bson:"name"`
-	Address     string             `bson:"address"`
-	City        string             `bson:"city"`
-	State       string             `bson:"state"`
-	Rating      float64            `bson:"rating"`
-	Amenities   []string           `bson:"amenities"`
-	Descripcion []string           `bson:"descripcion"`
+	ID            primitive.ObjectID  `bson:"_id,omitempty"`
+	Name          string              `bson:"name"`
+	Address       string              `bson:"address"`
+	City          string              `bson:"city"`
+	State         string              `bson:"state"`
+	Rating        float64             `bson:"rating"`
+	ReviewCount   int                 `bson:"review_count"`
+	Amenities     []string            `bson:"amenities"`
+	Descripcion   []string            `bson:"descripcion"`
+	Policy        *CancellationPolicy `bson:"cancellation_policy,omitempty"`
+	TimeZone      string              `bson:"time_zone"`
+	CheckInTime   string              `bson:"check_in_time"`
+	BookingCutoff string              `bson:"booking_cutoff"`
+	MaxStayNights int                 `bson:"max_stay_nights"`
+	TaxID         string              `bson:"tax_id"`
+	TaxPercent    float64             `bson:"tax_percent"`
+	// Clear son los campos que Update borra con $unset; no se guarda
+	Clear []string `bson:"-"`
+}
+
+type CancellationPolicy struct {
+	Description    string        `bson:"description"`
+	NonRefundable  bool          `bson:"non_refundable"`
+	Tiers          []PenaltyTier `bson:"tiers"`
+	DepositPercent float64       `bson:"deposit_percent"`
+}
+
+type PenaltyTier struct {
+	HoursBeforeCheckIn int     `bson:"hours_before_check_in"`
+	PenaltyPercent     float64 `bson:"penalty_percent"`
 }
@@ -0,0 +1,38 @@
+package rooms
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type Bed struct {
+	Type     string `bson:"type"`
+	Quantity int    `bson:"quantity"`
+}
+
+type RoomType struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty"`
+	HotelID  string             `bson:"hotel_id"`
+	Name     string             `bson:"name"`
+	Capacity int                `bson:"capacity"`
+	Beds     []Bed              `bson:"beds"`
+	Count    int                `bson:"count"`
+	RatePlan *RatePlan          `bson:"rate_plan,omitempty"`
+}
+
+type RatePlan struct {
+	Currency                string         `bson:"currency"`
+	BaseRate                float64        `bson:"base_rate"`
+	WeekendSurchargePercent float64        `bson:"weekend_surcharge_percent"`
+	Seasons                 []Season       `bson:"seasons"`
+	LengthOfStayDiscounts   []StayDiscount `bson:"length_of_stay_discounts"`
+}
+
+type Season struct {
+	Name      string  `bson:"name"`
+	StartDate string  `bson:"start_date"`
+	EndDate   string  `bson:"end_date"`
+	Rate      float64 `bson:"rate"`
+}
+
+type StayDiscount struct {
+	MinNights       int     `bson:"min_nights"`
+	DiscountPercent float64 `bson:"discount_percent"`
+}
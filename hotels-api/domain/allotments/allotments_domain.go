@@ -0,0 +1,52 @@
+package allotments
+
+import (
+	"errors"
+	"hotels-api/domain/reservations"
+	"time"
+)
+
+var (
+	ErrNotFound         = errors.New("room block not found")
+	ErrInvalidAllotment = errors.New("invalid room block")
+	ErrBlockClosed      = errors.New("room block is closed")
+	ErrOutsideBlock     = errors.New("stay is outside the room block")
+)
+
+// Estados de un cupo
+const (
+	StatusActive   = "active"
+	StatusReleased = "released"
+)
+
+// Allotment es un cupo de Rooms habitaciones de un tipo reservado para un evento o una empresa
+// entre StartDate y EndDate. Las reservas que traen Code toman sus habitaciones del cupo y no del
+// inventario general. Desde ReleaseDate, en la fecha del hotel, el cupo se cierra y las
+// habitaciones que no se usaron vuelven a la venta; ReleasedRooms cuenta esas noches-habitación.
+// Pickup, que se completa al consultar un cupo, indica cuántas habitaciones se usaron cada noche.
+type Allotment struct {
+	ID            string            `json:"id" bson:"_id,omitempty"`
+	HotelID       string            `json:"hotel_id" bson:"hotel_id"`
+	RoomTypeID    string            `json:"room_type_id" bson:"room_type_id"`
+	Code          string            `json:"code" bson:"code"`
+	Name          string            `json:"name" bson:"name"`
+	StartDate     reservations.Date `json:"start_date" bson:"start_date"`
+	EndDate       reservations.Date `json:"end_date" bson:"end_date"`
+	Rooms         int               `json:"rooms" bson:"rooms"`
+	ReleaseDate   reservations.Date `json:"release_date" bson:"release_date"`
+	Status        string            `json:"status" bson:"status"`
+	CreatedAt     time.Time         `json:"created_at" bson:"created_at"`
+	ReleasedAt    *time.Time        `json:"released_at,omitempty" bson:"released_at,omitempty"`
+	ReleasedRooms int               `json:"released_rooms" bson:"released_rooms"`
+	Pickup        map[string]int    `json:"pickup,omitempty" bson:"-"`
+}
+
+// Covers indica si la estadía de start a end cae dentro de las fechas del cupo.
+func (a Allotment) Covers(start, end reservations.Date) bool {
+	return !start.Before(a.StartDate.Time) && !end.After(a.EndDate.Time)
+}
+
+// IsOpen indica si el cupo todavía acepta reservas en la fecha today del hotel.
+func (a Allotment) IsOpen(today reservations.Date) bool {
+	return a.Status == StatusActive && today.Before(a.ReleaseDate.Time)
+}
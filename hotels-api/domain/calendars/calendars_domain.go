@@ -0,0 +1,46 @@
+package calendars
+
+import (
+	"errors"
+	"hotels-api/domain/reservations"
+	"time"
+)
+
+var (
+	ErrNotFound     = errors.New("calendar feed not found")
+	ErrInvalidToken = errors.New("invalid calendar feed token")
+	ErrInvalidScope = errors.New("invalid calendar feed scope")
+)
+
+// Alcances de un feed: las reservas de un huésped o las llegadas de un hotel
+const (
+	ScopeUser  = "user"
+	ScopeHotel = "hotel"
+)
+
+// PastDays es cuántos días hacia atrás incluye el feed, para que las apps no carguen todo el historial.
+const PastDays = 90
+
+// Feed guarda el token con el que una app de calendario se suscribe sin JWT. Hay un solo token
+// por huésped u hotel: emitir uno nuevo invalida la URL anterior.
+type Feed struct {
+	ID        string    `json:"-" bson:"_id"`
+	Scope     string    `json:"scope" bson:"scope"`
+	SubjectID string    `json:"subject_id" bson:"subject_id"`
+	Token     string    `json:"token" bson:"token"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// FeedID identifica el feed de un huésped u hotel.
+func FeedID(scope, subjectID string) string {
+	return scope + ":" + subjectID
+}
+
+// Event es una reserva con los datos del hotel que se muestran en el calendario.
+type Event struct {
+	Reservation  reservations.Reservation
+	HotelName    string
+	Location     string
+	RoomTypes    []string
+	LastModified time.Time
+}
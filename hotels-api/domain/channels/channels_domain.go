@@ -0,0 +1,63 @@
+package channels
+
+import (
+	"errors"
+	"hotels-api/domain/reservations"
+	"time"
+)
+
+var (
+	ErrFeedNotFound = errors.New("external calendar feed not found")
+	ErrInvalidFeed  = errors.New("invalid external calendar feed")
+)
+
+// Estados de un bloqueo importado
+const (
+	HoldActive = "active"
+	// HoldConflict es un bloqueo que no entró en el inventario porque esas noches ya estaban
+	// vendidas: es una sobreventa entre canales que el hotel tiene que resolver. Se reintenta en
+	// cada sincronización.
+	HoldConflict = "conflict"
+)
+
+// Feed es un calendario iCal de otro canal de venta. Con RoomTypeID bloquea una habitación de ese
+// tipo por evento; sin él el evento cierra el hotel entero, todas las habitaciones de todos los tipos.
+type Feed struct {
+	ID         string     `json:"id" bson:"_id,omitempty"`
+	HotelID    string     `json:"hotel_id" bson:"hotel_id"`
+	RoomTypeID string     `json:"room_type_id,omitempty" bson:"room_type_id,omitempty"`
+	Name       string     `json:"name" bson:"name"`
+	URL        string     `json:"url" bson:"url"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	SyncedAt   *time.Time `json:"synced_at,omitempty" bson:"synced_at,omitempty"`
+	Events     int        `json:"events" bson:"events"`
+	Skipped    int        `json:"skipped" bson:"skipped"`
+	LastError  string     `json:"last_error,omitempty" bson:"last_error,omitempty"`
+}
+
+// Hold es el bloqueo de Quantity habitaciones de un tipo por un evento del feed, identificado por
+// su UID. Ocupa inventario como una reserva mientras está activo.
+type Hold struct {
+	ID         string            `json:"id" bson:"_id,omitempty"`
+	FeedID     string            `json:"feed_id" bson:"feed_id"`
+	HotelID    string            `json:"hotel_id" bson:"hotel_id"`
+	RoomTypeID string            `json:"room_type_id" bson:"room_type_id"`
+	UID        string            `json:"uid" bson:"uid"`
+	Summary    string            `json:"summary" bson:"summary"`
+	StartDate  reservations.Date `json:"start_date" bson:"start_date"`
+	EndDate    reservations.Date `json:"end_date" bson:"end_date"`
+	Quantity   int               `json:"quantity" bson:"quantity"`
+	Status     string            `json:"status" bson:"status"`
+	UpdatedAt  time.Time         `json:"updated_at" bson:"updated_at"`
+}
+
+// Sync resume una sincronización de un feed.
+type Sync struct {
+	FeedID    string `json:"feed_id"`
+	Events    int    `json:"events"`
+	Skipped   int    `json:"skipped"`
+	Created   int    `json:"created"`
+	Updated   int    `json:"updated"`
+	Removed   int    `json:"removed"`
+	Conflicts int    `json:"conflicts"`
+}
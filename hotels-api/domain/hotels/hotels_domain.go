@@ -1,17 +1,120 @@
 package hotels
 
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidPolicy   = errors.New("invalid cancellation policy")
+	ErrInvalidSchedule = errors.New("invalid hotel time zone or schedule")
+	ErrInvalidTax      = errors.New("invalid hotel tax settings")
+	ErrInvalidFilter   = errors.New("invalid hotel list filter")
+	ErrInvalidClear    = errors.New("invalid field to clear")
+)
+
+// Horarios por defecto cuando el hotel no los configura
+const (
+	DefaultTimeZone      = "America/Argentina/Cordoba"
+	DefaultCheckInTime   = "14:00"
+	DefaultMaxStayNights = 30
+	TimeOfDayLayout      = "15:04"
+)
+
+// Location devuelve la zona horaria del hotel, o la zona por defecto si no tiene una. Las zonas se
+// validan al guardar el hotel; si aun así no se puede cargar, se usa UTC.
+func Location(timeZone string) *time.Location {
+	if timeZone == "" {
+		timeZone = DefaultTimeZone
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
 type Hotel struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name"`
-	Address     string   `json:"address"`
-	City        string   `json:"city"`
-	State       string   `json:"state"`
-	Rating      float64  `json:"rating"`
-	Amenities   []string `json:"amenities"`
-	Descripcion []string `json:"descripcion"`
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Address     string              `json:"address"`
+	City        string              `json:"city"`
+	State       string              `json:"state"`
+	Rating      float64             `json:"rating"`
+	ReviewCount int                 `json:"review_count"`
+	Amenities   []string            `json:"amenities"`
+	Descripcion []string            `json:"descripcion"`
+	Policy      *CancellationPolicy `json:"cancellation_policy,omitempty"`
+	// TimeZone es una zona IANA; "hoy" y los horarios se evalúan en la hora local del hotel
+	TimeZone    string `json:"time_zone"`
+	CheckInTime string `json:"check_in_time"`
+	// BookingCutoff es la hora local límite para reservar con llegada en el mismo día
+	BookingCutoff string `json:"booking_cutoff"`
+	MaxStayNights int    `json:"max_stay_nights"`
+	// TaxID identifica al hotel ante el fisco y TaxPercent es el impuesto incluido en las tarifas,
+	// que las facturas discriminan
+	TaxID      string  `json:"tax_id"`
+	TaxPercent float64 `json:"tax_percent"`
+	// Clear lista los campos que una actualización borra. Update solo escribe los campos con valor,
+	// así que es la única forma de quitar la política o volver a 0 la estadía máxima o el impuesto.
+	Clear []string `json:"clear,omitempty"`
+}
+
+// Campos que se pueden borrar con Clear; borrados vuelven a su valor por defecto
+const (
+	FieldPolicy        = "cancellation_policy"
+	FieldMaxStayNights = "max_stay_nights"
+	FieldTaxPercent    = "tax_percent"
+)
+
+var ClearableFields = []string{FieldPolicy, FieldMaxStayNights, FieldTaxPercent}
+
+// CancellationPolicy define la penalidad al cancelar según la anticipación respecto del check-in.
+// Cada tramo aplica cuando se cancela con menos de HoursBeforeCheckIn horas de anticipación;
+// si aplican varios, se cobra el mayor porcentaje. DepositPercent es la parte del total que se
+// cobra al reservar; con 0 no se pide pago hasta llegar al hotel.
+type CancellationPolicy struct {
+	Description    string        `json:"description"`
+	NonRefundable  bool          `json:"non_refundable"`
+	Tiers          []PenaltyTier `json:"tiers"`
+	DepositPercent float64       `json:"deposit_percent"`
+}
+
+type PenaltyTier struct {
+	HoursBeforeCheckIn int     `json:"hours_before_check_in"`
+	PenaltyPercent     float64 `json:"penalty_percent"`
 }
 
 type HotelNew struct {
 	Operation string `json:"operation"`
 	HotelID   string `json:"hotel_id"`
 }
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListSortFields lista los campos por los que se puede ordenar el listado de hoteles.
+var ListSortFields = []string{"name", "city", "state", "rating", "review_count"}
+
+// ListFilter filtra el listado de hoteles. City y State se comparan sin distinguir mayúsculas ni
+// acentos, MinRating deja los hoteles con esa calificación o más y Amenities los que tienen todas
+// las comodidades indicadas.
+type ListFilter struct {
+	City      string
+	State     string
+	MinRating float64
+	Amenities []string
+	SortBy    string
+	SortDesc  bool
+	Limit     int
+	Offset    int
+}
+
+type ListResult struct {
+	Results []Hotel `json:"results"`
+	Total   int64   `json:"total"`
+	Limit   int     `json:"limit"`
+	Offset  int     `json:"offset"`
+}
@@ -0,0 +1,17 @@
+package idempotency
+
+import "time"
+
+// HeaderKey es el header con el que el cliente identifica un intento de escritura para poder reintentarlo.
+const HeaderKey = "Idempotency-Key"
+
+// Record guarda la respuesta que se dio a la primera request con una clave, para devolverla en los reintentos.
+type Record struct {
+	ID          string    `bson:"_id"`
+	RequestHash string    `bson:"request_hash"`
+	Completed   bool      `bson:"completed"`
+	Status      int       `bson:"status,omitempty"`
+	ContentType string    `bson:"content_type,omitempty"`
+	Body        []byte    `bson:"body,omitempty"`
+	CreatedAt   time.Time `bson:"created_at"`
+}
@@ -0,0 +1,73 @@
+package invoices
+
+import (
+	"errors"
+	"fmt"
+	"hotels-api/domain/reservations"
+	"time"
+)
+
+var (
+	ErrNotFound        = errors.New("invoice not found")
+	ErrNotInvoiceable  = errors.New("reservation cannot be invoiced")
+	ErrNumberingFailed = errors.New("invoice number could not be assigned")
+)
+
+// InvoiceableStatuses son los estados de una reserva que ya se puede facturar.
+var InvoiceableStatuses = []string{reservations.StatusConfirmed, reservations.StatusCheckedIn, reservations.StatusCheckedOut}
+
+// Invoice es la factura de una reserva. Se emite una sola vez con un número correlativo por hotel
+// y desde entonces no cambia: los datos del hotel, las tarifas y los pagos quedan copiados tal
+// como estaban al emitirla. Las tarifas incluyen el impuesto, que se discrimina en Net y Tax.
+// Discount es el descuento por estadía, PromoDiscount el del código de descuento y PointsDiscount el
+// de los puntos canjeados, si hubo.
+type Invoice struct {
+	ReservationID  string            `json:"reservation_id" bson:"_id"`
+	Number         string            `json:"number" bson:"number"`
+	Sequence       int64             `json:"sequence" bson:"sequence"`
+	IssuedAt       time.Time         `json:"issued_at" bson:"issued_at"`
+	HotelID        string            `json:"hotel_id" bson:"hotel_id"`
+	HotelName      string            `json:"hotel_name" bson:"hotel_name"`
+	HotelAddress   string            `json:"hotel_address" bson:"hotel_address"`
+	HotelTaxID     string            `json:"hotel_tax_id,omitempty" bson:"hotel_tax_id,omitempty"`
+	UserID         string            `json:"user_id" bson:"user_id"`
+	StartDate      reservations.Date `json:"start_date" bson:"start_date"`
+	EndDate        reservations.Date `json:"end_date" bson:"end_date"`
+	Currency       string            `json:"currency" bson:"currency"`
+	Lines          []Line            `json:"lines" bson:"lines"`
+	Subtotal       float64           `json:"subtotal" bson:"subtotal"`
+	Discount       float64           `json:"discount" bson:"discount"`
+	PromoCode      string            `json:"promo_code,omitempty" bson:"promo_code,omitempty"`
+	PromoDiscount  float64           `json:"promo_discount,omitempty" bson:"promo_discount,omitempty"`
+	PointsRedeemed int64             `json:"points_redeemed,omitempty" bson:"points_redeemed,omitempty"`
+	PointsDiscount float64           `json:"points_discount,omitempty" bson:"points_discount,omitempty"`
+	Total          float64           `json:"total" bson:"total"`
+	TaxPercent     float64           `json:"tax_percent" bson:"tax_percent"`
+	Net            float64           `json:"net" bson:"net"`
+	Tax            float64           `json:"tax" bson:"tax"`
+	Payments       []Payment         `json:"payments" bson:"payments"`
+	Paid           float64           `json:"paid" bson:"paid"`
+	BalanceDue     float64           `json:"balance_due" bson:"balance_due"`
+}
+
+// Line es una noche de un tipo de habitación; Quantity es la cantidad de habitaciones.
+type Line struct {
+	Description string  `json:"description" bson:"description"`
+	Date        string  `json:"date" bson:"date"`
+	Quantity    int     `json:"quantity" bson:"quantity"`
+	UnitPrice   float64 `json:"unit_price" bson:"unit_price"`
+	Amount      float64 `json:"amount" bson:"amount"`
+}
+
+// Payment es un cobro o reintegro aprobado por la pasarela; los reintegros tienen monto negativo.
+type Payment struct {
+	Operation string    `json:"operation" bson:"operation"`
+	Reference string    `json:"reference" bson:"reference"`
+	Amount    float64   `json:"amount" bson:"amount"`
+	At        time.Time `json:"at" bson:"at"`
+}
+
+// FormatNumber arma el número visible de la factura a partir del correlativo del hotel.
+func FormatNumber(sequence int64) string {
+	return fmt.Sprintf("%08d", sequence)
+}
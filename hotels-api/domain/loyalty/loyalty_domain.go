@@ -0,0 +1,34 @@
+package loyalty
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrInvalidPoints      = errors.New("invalid points redemption")
+	ErrInsufficientPoints = errors.New("insufficient points")
+	ErrUnavailable        = errors.New("loyalty service unavailable")
+)
+
+// PointValues es lo que descuenta cada punto en cada moneda. Los puntos se ganan por noche de
+// estadía en users-api, sin moneda, y solo se pueden canjear en las monedas de esta tabla.
+var PointValues = map[string]float64{
+	"ARS": 10,
+	"BRL": 0.05,
+	"EUR": 0.01,
+	"USD": 0.01,
+}
+
+// DiscountFor es el descuento en currency que vale canjear points.
+func DiscountFor(points int64, currency string) (float64, error) {
+	if points <= 0 {
+		return 0, fmt.Errorf("points must be positive: %w", ErrInvalidPoints)
+	}
+	value, exists := PointValues[currency]
+	if !exists {
+		return 0, fmt.Errorf("points cannot be redeemed in %s: %w", currency, ErrInvalidPoints)
+	}
+	return math.Round(float64(points)*value*100) / 100, nil
+}
@@ -0,0 +1,78 @@
+package payments
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound        = errors.New("payment not found")
+	ErrPaymentRequired = errors.New("a payment method is required to book this hotel")
+	ErrDeclined        = errors.New("payment declined")
+)
+
+// Estados del pago de una reserva
+const (
+	StatusAuthorized = "authorized"
+	StatusCaptured   = "captured"
+	StatusRefunded   = "refunded"
+	StatusVoided     = "voided"
+	StatusDeclined   = "declined"
+)
+
+// Operaciones contra la pasarela
+const (
+	OperationAuthorize = "authorize"
+	OperationCapture   = "capture"
+	OperationRefund    = "refund"
+	OperationVoid      = "void"
+)
+
+// Payment es el pago de una reserva. Al reservar se autoriza el total y se captura la seña; el
+// saldo se captura en el check-in y al cancelar se reintegra o cobra según la penalidad.
+type Payment struct {
+	ID              string        `json:"id" bson:"_id,omitempty"`
+	ReservationID   string        `json:"reservation_id" bson:"reservation_id"`
+	HotelID         string        `json:"hotel_id" bson:"hotel_id"`
+	UserID          string        `json:"user_id" bson:"user_id"`
+	Currency        string        `json:"currency" bson:"currency"`
+	AuthorizationID string        `json:"authorization_id" bson:"authorization_id"`
+	Status          string        `json:"status" bson:"status"`
+	Authorized      float64       `json:"authorized" bson:"authorized"`
+	Captured        float64       `json:"captured" bson:"captured"`
+	Refunded        float64       `json:"refunded" bson:"refunded"`
+	Transactions    []Transaction `json:"transactions" bson:"transactions"`
+	CreatedAt       time.Time     `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at" bson:"updated_at"`
+}
+
+// Transaction registra cada operación hecha contra la pasarela, aprobada o no.
+type Transaction struct {
+	Operation string    `json:"operation" bson:"operation"`
+	Amount    float64   `json:"amount" bson:"amount"`
+	Reference string    `json:"reference" bson:"reference"`
+	Approved  bool      `json:"approved" bson:"approved"`
+	Reason    string    `json:"reason,omitempty" bson:"reason,omitempty"`
+	At        time.Time `json:"at" bson:"at"`
+}
+
+// Remaining devuelve lo autorizado que todavía no se capturó.
+func (p Payment) Remaining() float64 {
+	return p.Authorized - p.Captured
+}
+
+// AuthorizeRequest pide a la pasarela reservar Amount sobre el medio de pago PaymentMethod.
+type AuthorizeRequest struct {
+	Reference     string
+	Amount        float64
+	Currency      string
+	PaymentMethod string
+}
+
+// Result es la respuesta de la pasarela. Un rechazo no es un error: Approved queda en false y
+// Reason explica el motivo.
+type Result struct {
+	Reference string
+	Approved  bool
+	Reason    string
+}
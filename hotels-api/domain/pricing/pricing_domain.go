@@ -0,0 +1,63 @@
+package pricing
+
+import "errors"
+
+var (
+	ErrNoRatePlan   = errors.New("room type has no rate plan")
+	ErrInvalidQuote = errors.New("invalid quote request")
+)
+
+// MaxStayNights es la estadía más larga que se cotiza. La cotización es pública y se arma noche por
+// noche, así que el rango se limita antes de recorrerlo.
+const MaxStayNights = 365
+
+// RatePlan define la tarifa de un tipo de habitación.
+// Las temporadas reemplazan la tarifa base en su rango de fechas (ambos extremos incluidos),
+// el recargo de fin de semana se aplica a las noches de viernes y sábado y el descuento por
+// estadía se toma del tramo con mayor MinNights que cumpla la cantidad de noches.
+type RatePlan struct {
+	Currency                string         `json:"currency"`
+	BaseRate                float64        `json:"base_rate"`
+	WeekendSurchargePercent float64        `json:"weekend_surcharge_percent"`
+	Seasons                 []Season       `json:"seasons"`
+	LengthOfStayDiscounts   []StayDiscount `json:"length_of_stay_discounts"`
+}
+
+type Season struct {
+	Name      string  `json:"name"`
+	StartDate string  `json:"start_date"`
+	EndDate   string  `json:"end_date"`
+	Rate      float64 `json:"rate"`
+}
+
+type StayDiscount struct {
+	MinNights       int     `json:"min_nights"`
+	DiscountPercent float64 `json:"discount_percent"`
+}
+
+type NightPrice struct {
+	Date      string  `json:"date" bson:"date"`
+	Season    string  `json:"season,omitempty" bson:"season,omitempty"`
+	Rate      float64 `json:"rate" bson:"rate"`
+	Surcharge float64 `json:"surcharge" bson:"surcharge"`
+	Price     float64 `json:"price" bson:"price"`
+}
+
+// Quote es el precio de la estadía en un tipo de habitación. Con un código de descuento, PromoDiscount
+// es lo que descuenta el código sobre el total con el descuento por estadía ya aplicado.
+type Quote struct {
+	HotelID         string       `json:"hotel_id"`
+	RoomTypeID      string       `json:"room_type_id"`
+	RoomTypeName    string       `json:"room_type_name"`
+	StartDate       string       `json:"start_date"`
+	EndDate         string       `json:"end_date"`
+	Guests          int          `json:"guests"`
+	Currency        string       `json:"currency"`
+	Nights          []NightPrice `json:"nights"`
+	Subtotal        float64      `json:"subtotal"`
+	DiscountPercent float64      `json:"discount_percent"`
+	Discount        float64      `json:"discount"`
+	PromoCode       string       `json:"promo_code,omitempty"`
+	PromoDiscount   float64      `json:"promo_discount,omitempty"`
+	Total           float64      `json:"total"`
+}
@@ -0,0 +1,78 @@
+package promotions
+
+import (
+	"errors"
+	"fmt"
+	"hotels-api/domain/reservations"
+	"math"
+	"time"
+)
+
+var (
+	ErrNotFound         = errors.New("promo code not found")
+	ErrInvalidPromotion = errors.New("invalid promotion")
+	ErrNotApplicable    = errors.New("promo code does not apply to this stay")
+	ErrExhausted        = errors.New("promo code has no uses left")
+)
+
+// Promotion es un código de descuento de una campaña. Descuenta DiscountPercent del total de la
+// estadía o, como voucher, un monto fijo DiscountAmount en Currency. Se puede usar desde ValidFrom
+// hasta ValidUntil, en los hoteles de HotelIDs (todos si está vacío), para estadías de al menos
+// MinNights noches que, si StayFrom y StayUntil están, caigan dentro de esas fechas.
+// MaxRedemptions limita los usos del código y MaxPerUser los de cada usuario; 0 es sin límite.
+// Redemptions cuenta los usos de reservas que no se cancelaron.
+type Promotion struct {
+	ID              string            `json:"id" bson:"_id,omitempty"`
+	Code            string            `json:"code" bson:"code"`
+	Name            string            `json:"name" bson:"name"`
+	DiscountPercent float64           `json:"discount_percent,omitempty" bson:"discount_percent,omitempty"`
+	DiscountAmount  float64           `json:"discount_amount,omitempty" bson:"discount_amount,omitempty"`
+	Currency        string            `json:"currency,omitempty" bson:"currency,omitempty"`
+	ValidFrom       time.Time         `json:"valid_from" bson:"valid_from"`
+	ValidUntil      time.Time         `json:"valid_until" bson:"valid_until"`
+	HotelIDs        []string          `json:"hotel_ids,omitempty" bson:"hotel_ids,omitempty"`
+	MinNights       int               `json:"min_nights,omitempty" bson:"min_nights,omitempty"`
+	StayFrom        reservations.Date `json:"stay_from" bson:"stay_from,omitempty"`
+	StayUntil       reservations.Date `json:"stay_until" bson:"stay_until,omitempty"`
+	MaxRedemptions  int               `json:"max_redemptions,omitempty" bson:"max_redemptions,omitempty"`
+	MaxPerUser      int               `json:"max_per_user,omitempty" bson:"max_per_user,omitempty"`
+	Redemptions     int               `json:"redemptions" bson:"redemptions"`
+	CreatedAt       time.Time         `json:"created_at" bson:"created_at"`
+}
+
+// IsValidAt indica si el código se puede usar en el momento at.
+func (p Promotion) IsValidAt(at time.Time) bool {
+	return !at.Before(p.ValidFrom) && at.Before(p.ValidUntil)
+}
+
+// Fits verifica que la estadía de start a end en el hotel cumpla las condiciones del código.
+func (p Promotion) Fits(hotelID string, start, end reservations.Date) error {
+	if len(p.HotelIDs) > 0 {
+		eligible := false
+		for _, id := range p.HotelIDs {
+			eligible = eligible || id == hotelID
+		}
+		if !eligible {
+			return fmt.Errorf("code %s is not valid at this hotel: %w", p.Code, ErrNotApplicable)
+		}
+	}
+	if nights := start.NightsUntil(end); nights < p.MinNights {
+		return fmt.Errorf("code %s requires at least %d nights: %w", p.Code, p.MinNights, ErrNotApplicable)
+	}
+	if !p.StayFrom.IsZero() && start.Before(p.StayFrom.Time) || !p.StayUntil.IsZero() && end.After(p.StayUntil.Time) {
+		return fmt.Errorf("code %s is for stays from %s to %s: %w", p.Code, p.StayFrom, p.StayUntil, ErrNotApplicable)
+	}
+	return nil
+}
+
+// DiscountFor devuelve cuánto descuenta el código de un total en currency. Un voucher nunca
+// descuenta más que el total.
+func (p Promotion) DiscountFor(total float64, currency string) (float64, error) {
+	if p.DiscountPercent > 0 {
+		return math.Round(total*p.DiscountPercent) / 100, nil
+	}
+	if p.Currency != currency {
+		return 0, fmt.Errorf("code %s is a voucher in %s, not %s: %w", p.Code, p.Currency, currency, ErrNotApplicable)
+	}
+	return math.Min(p.DiscountAmount, total), nil
+}
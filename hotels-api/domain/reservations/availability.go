@@ -0,0 +1,27 @@
+package reservations
+
+// MaxAvailabilityNights acota el rango que se puede consultar de una vez en el calendario.
+const MaxAvailabilityNights = 120
+
+// Availability es el calendario de un hotel: para cada tipo de habitación, cuántas quedan libres
+// y el precio de cada noche del rango [From, To).
+type Availability struct {
+	HotelID   string                 `json:"hotel_id"`
+	From      Date                   `json:"from"`
+	To        Date                   `json:"to"`
+	RoomTypes []RoomTypeAvailability `json:"room_types"`
+}
+
+type RoomTypeAvailability struct {
+	RoomTypeID string              `json:"room_type_id"`
+	Name       string              `json:"name"`
+	Capacity   int                 `json:"capacity"`
+	Currency   string              `json:"currency"`
+	Nights     []NightAvailability `json:"nights"`
+}
+
+type NightAvailability struct {
+	Date      Date    `json:"date"`
+	Remaining int     `json:"remaining"`
+	Price     float64 `json:"price"`
+}
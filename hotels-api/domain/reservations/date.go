@@ -0,0 +1,104 @@
+package reservations
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+const DateLayout = "2006-01-02"
+
+// Date es un día calendario sin hora. En JSON viaja como "2006-01-02" y en Mongo se guarda
+// como fecha BSON a medianoche UTC, para poder consultarla por rangos.
+type Date struct {
+	time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DateOf devuelve el día calendario del instante t en su propia zona horaria.
+func DateOf(t time.Time) Date {
+	return NewDate(t.Date())
+}
+
+func ParseDate(value string) (Date, error) {
+	parsed, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return Date{}, fmt.Errorf("date %q must be YYYY-MM-DD: %w", value, ErrInvalidDates)
+	}
+	return Date{parsed}, nil
+}
+
+func (d Date) AddDays(days int) Date {
+	return Date{d.Time.AddDate(0, 0, days)}
+}
+
+// NightsUntil devuelve la cantidad de noches entre d y end.
+func (d Date) NightsUntil(end Date) int {
+	return int(end.Time.Sub(d.Time).Hours() / 24)
+}
+
+func (d Date) String() string {
+	return d.Time.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("date must be a string: %w", err)
+	}
+	if value == "" {
+		*d = Date{}
+		return nil
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Date) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(d.Time.UTC())
+}
+
+// UnmarshalBSONValue lee la fecha BSON y también el texto "2006-01-02" con que se guardaban las
+// reservas anteriores, que siguen en la base sin migrar.
+func (d *Date) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	switch t {
+	case bsontype.Null:
+		*d = Date{}
+		return nil
+	case bsontype.String:
+		var text string
+		if err := bson.UnmarshalValue(t, data, &text); err != nil {
+			return fmt.Errorf("error decoding date: %w", err)
+		}
+		parsed, err := ParseDate(text)
+		if err != nil {
+			return fmt.Errorf("error decoding date: %w", err)
+		}
+		*d = parsed
+		return nil
+	}
+
+	var value time.Time
+	if err := bson.UnmarshalValue(t, data, &value); err != nil {
+		return fmt.Errorf("error decoding date: %w", err)
+	}
+	*d = DateOf(value.UTC())
+	return nil
+}
@@ -0,0 +1,43 @@
+package reservations_test
+
+import (
+	"testing"
+
+	"hotels-api/domain/reservations"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDateDecodesBSONDatesAndLegacyStrings(t *testing.T) {
+	want := reservations.NewDate(2024, 12, 2)
+	documents := []bson.M{
+		{"start_date": want},
+		{"start_date": "2024-12-02"},
+	}
+	for _, document := range documents {
+		data, err := bson.Marshal(document)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded struct {
+			StartDate reservations.Date `bson:"start_date"`
+		}
+		if err := bson.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("error decoding %v: %v", document, err)
+		}
+		if !decoded.StartDate.Equal(want.Time) {
+			t.Fatalf("expected %s from %v, got %s", want, document, decoded.StartDate)
+		}
+	}
+
+	data, err := bson.Marshal(bson.M{"start_date": "02/12/2024"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		StartDate reservations.Date `bson:"start_date"`
+	}
+	if err := bson.Unmarshal(data, &decoded); err == nil {
+		t.Fatalf("expected an invalid legacy date to fail, got %s", decoded.StartDate)
+	}
+}
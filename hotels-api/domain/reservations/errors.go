@@ -0,0 +1,17 @@
+package reservations
+
+import "errors"
+
+var (
+	ErrNotFound          = errors.New("reservation not found")
+	ErrInvalidDates      = errors.New("invalid reservation dates")
+	ErrUnknownRoomType   = errors.New("unknown room type for hotel")
+	ErrInvalidGuests     = errors.New("invalid number of guests for room type")
+	ErrInvalidItems      = errors.New("invalid reservation rooms")
+	ErrNoAvailability    = errors.New("no rooms available for the requested dates")
+	ErrInvalidTransition = errors.New("invalid reservation status transition")
+	ErrNotModifiable     = errors.New("reservation cannot be modified")
+	ErrConcurrentUpdate  = errors.New("reservation was changed by another request")
+	ErrOfferExpired      = errors.New("waitlist offer expired")
+	ErrWaitlistNotFound  = errors.New("waitlist entry not found")
+)
@@ -0,0 +1,86 @@
+package reservations
+
+import "time"
+
+// EventVersion es la versión del formato de los eventos de reservas. Se incrementa ante cualquier
+// cambio que no sea agregar campos, para que los consumidores puedan distinguir los formatos.
+const EventVersion = 1
+
+// Tipos de eventos que se publican cuando cambia una reserva
+const (
+	EventCreated    = "reservation.created"
+	EventModified   = "reservation.modified"
+	EventCancelled  = "reservation.cancelled"
+	EventCheckedOut = "reservation.checked_out"
+)
+
+// Event es el mensaje que se publica en la cola de reservas. Su formato es independiente de
+// Reservation para que los cambios internos no rompan a los consumidores.
+type Event struct {
+	Version     int          `json:"version"`
+	ID          string       `json:"id"`
+	Type        string       `json:"type"`
+	OccurredAt  time.Time    `json:"occurred_at"`
+	Reservation EventPayload `json:"reservation"`
+}
+
+// EventPayload resume la reserva. CancellationReason distingue las reservas que el sistema
+// canceló por vencidas o por pago rechazado.
+type EventPayload struct {
+	ID                 string      `json:"id"`
+	HotelID            string      `json:"hotel_id"`
+	UserID             string      `json:"user_id"`
+	Status             string      `json:"status"`
+	StartDate          Date        `json:"start_date"`
+	EndDate            Date        `json:"end_date"`
+	Guests             int         `json:"guests"`
+	Rooms              []EventRoom `json:"rooms"`
+	Currency           string      `json:"currency"`
+	Total              float64     `json:"total"`
+	Penalty            *float64    `json:"penalty,omitempty"`
+	Refund             *float64    `json:"refund,omitempty"`
+	CancellationReason string      `json:"cancellation_reason,omitempty"`
+}
+
+type EventRoom struct {
+	RoomTypeID string `json:"room_type_id"`
+	Quantity   int    `json:"quantity"`
+	Adults     int    `json:"adults"`
+	Children   int    `json:"children"`
+}
+
+// NewEvent arma el evento de tipo eventType con los datos actuales de la reserva.
+func NewEvent(id string, eventType string, reservation Reservation, at time.Time) Event {
+	payload := EventPayload{
+		ID:        reservation.ID,
+		HotelID:   reservation.HotelID,
+		UserID:    reservation.UserID,
+		Status:    reservation.Status,
+		StartDate: reservation.StartDate,
+		EndDate:   reservation.EndDate,
+		Guests:    reservation.Guests,
+		Rooms:     make([]EventRoom, 0),
+		Currency:  reservation.Currency,
+		Total:     reservation.Total,
+	}
+	for _, item := range reservation.LineItems() {
+		payload.Rooms = append(payload.Rooms, EventRoom{
+			RoomTypeID: item.RoomTypeID,
+			Quantity:   item.Quantity,
+			Adults:     item.Adults,
+			Children:   item.Children,
+		})
+	}
+	if reservation.Cancellation != nil {
+		payload.Penalty = &reservation.Cancellation.Penalty
+		payload.Refund = &reservation.Cancellation.Refund
+		payload.CancellationReason = reservation.Cancellation.Reason
+	}
+	return Event{
+		Version:     EventVersion,
+		ID:          id,
+		Type:        eventType,
+		OccurredAt:  at,
+		Reservation: payload,
+	}
+}
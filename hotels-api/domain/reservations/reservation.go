@@ -1,10 +1,114 @@
 package reservations
 
+import (
+	"hotels-api/domain/pricing"
+	"time"
+)
+
+// Reservation es la reserva de un huésped. Items lista las habitaciones reservadas, y la reserva
+// completa se toma, cotiza y cancela como una unidad; RoomTypeID y Guests resumen el primer tipo
+// de habitación y el total de huéspedes. Las que se ofrecen desde la lista de espera tienen
+// ExpiresAt y vencen si no se confirman a tiempo. Las que traen BlockCode toman sus habitaciones
+// del cupo con ese código, que queda en AllotmentID. Con PromoCode se aplica ese código de descuento,
+// que queda en PromotionID, y PromoDiscount es lo que descontó del total además de Discount.
+// PointsRedeemed son los puntos de fidelidad que el huésped canjea por PointsDiscount, que se
+// descuenta después del código; PointsReference identifica el canje en users-api.
+// PaymentMethod es el token del medio de pago para la seña y nunca se guarda con la reserva.
 type Reservation struct {
-	ID        string `json:"id" bson:"_id,omitempty"`
-	HotelID   string `json:"hotel_id" bson:"hotel_id"`
-	UserID    string `json:"user_id" bson:"user_id"`
-	StartDate string `json:"start_date" bson:"start_date"`
-	EndDate   string `json:"end_date" bson:"end_date"`
-	Status    string `json:"status" bson:"status"`
+	ID              string               `json:"id" bson:"_id,omitempty"`
+	HotelID         string               `json:"hotel_id" bson:"hotel_id"`
+	RoomTypeID      string               `json:"room_type_id" bson:"room_type_id"`
+	UserID          string               `json:"user_id" bson:"user_id"`
+	StartDate       Date                 `json:"start_date" bson:"start_date"`
+	EndDate         Date                 `json:"end_date" bson:"end_date"`
+	Status          string               `json:"status" bson:"status"`
+	Guests          int                  `json:"guests" bson:"guests"`
+	Items           []LineItem           `json:"items" bson:"items,omitempty"`
+	Currency        string               `json:"currency" bson:"currency"`
+	Nights          []pricing.NightPrice `json:"nights,omitempty" bson:"nights,omitempty"`
+	Discount        float64              `json:"discount" bson:"discount"`
+	Total           float64              `json:"total" bson:"total"`
+	CreatedAt       time.Time            `json:"created_at" bson:"created_at"`
+	History         []StatusChange       `json:"history" bson:"history"`
+	Cancellation    *Cancellation        `json:"cancellation,omitempty" bson:"cancellation,omitempty"`
+	Modifications   []Modification       `json:"modifications,omitempty" bson:"modifications,omitempty"`
+	Version         int                  `json:"version" bson:"version"`
+	ExpiresAt       *time.Time           `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	WaitlistEntryID string               `json:"waitlist_entry_id,omitempty" bson:"waitlist_entry_id,omitempty"`
+	BlockCode       string               `json:"block_code,omitempty" bson:"block_code,omitempty"`
+	AllotmentID     string               `json:"allotment_id,omitempty" bson:"allotment_id,omitempty"`
+	PromoCode       string               `json:"promo_code,omitempty" bson:"promo_code,omitempty"`
+	PromotionID     string               `json:"promotion_id,omitempty" bson:"promotion_id,omitempty"`
+	PromoDiscount   float64              `json:"promo_discount,omitempty" bson:"promo_discount,omitempty"`
+	PointsRedeemed  int64                `json:"points_redeemed,omitempty" bson:"points_redeemed,omitempty"`
+	PointsDiscount  float64              `json:"points_discount,omitempty" bson:"points_discount,omitempty"`
+	PointsReference string               `json:"-" bson:"points_reference,omitempty"`
+	PaymentMethod   string               `json:"payment_method,omitempty" bson:"-"`
+}
+
+// LineItem es una línea de la reserva: Quantity habitaciones del mismo tipo para Adults adultos
+// y Children menores en total. Nights es el precio por noche de una habitación y Total el de
+// todas las habitaciones de la línea por toda la estadía.
+type LineItem struct {
+	RoomTypeID string               `json:"room_type_id" bson:"room_type_id"`
+	Quantity   int                  `json:"quantity" bson:"quantity"`
+	Adults     int                  `json:"adults" bson:"adults"`
+	Children   int                  `json:"children" bson:"children"`
+	Nights     []pricing.NightPrice `json:"nights" bson:"nights"`
+	Discount   float64              `json:"discount" bson:"discount"`
+	Total      float64              `json:"total" bson:"total"`
+}
+
+// LineItems devuelve las habitaciones de la reserva. Las reservas anteriores a los ítems no los
+// tienen guardados y ocupan una sola habitación de RoomTypeID.
+func (r Reservation) LineItems() []LineItem {
+	if len(r.Items) > 0 || r.RoomTypeID == "" {
+		return r.Items
+	}
+	return []LineItem{{
+		RoomTypeID: r.RoomTypeID,
+		Quantity:   1,
+		Adults:     r.Guests,
+		Nights:     r.Nights,
+		Discount:   r.Discount,
+		Total:      r.Total,
+	}}
+}
+
+// ModifyRequest cambia fechas, habitaciones o huéspedes; los campos vacíos conservan el valor actual.
+// RoomTypeID y Guests solo sirven para reservas de una única habitación; Items reemplaza todas las líneas.
+type ModifyRequest struct {
+	RoomTypeID string     `json:"room_type_id"`
+	StartDate  Date       `json:"start_date"`
+	EndDate    Date       `json:"end_date"`
+	Guests     int        `json:"guests"`
+	Items      []LineItem `json:"items"`
+}
+
+// Modification registra los valores que tenía la reserva antes de cada cambio.
+type Modification struct {
+	At                 time.Time  `json:"at" bson:"at"`
+	PreviousRoomTypeID string     `json:"previous_room_type_id" bson:"previous_room_type_id"`
+	PreviousStartDate  Date       `json:"previous_start_date" bson:"previous_start_date"`
+	PreviousEndDate    Date       `json:"previous_end_date" bson:"previous_end_date"`
+	PreviousTotal      float64    `json:"previous_total" bson:"previous_total"`
+	PreviousItems      []LineItem `json:"previous_items,omitempty" bson:"previous_items,omitempty"`
+}
+
+// Motivos de las cancelaciones que hace el sistema; las del huésped o del hotel no tienen motivo.
+// CancellationPaymentFailed es la de una reserva nueva cuyo cobro no terminó por un error.
+const (
+	CancellationExpired       = "expired"
+	CancellationDeclined      = "payment_declined"
+	CancellationPaymentFailed = "payment_failed"
+)
+
+// Cancellation guarda la penalidad calculada con la política del hotel al momento de cancelar.
+// Reason indica si la canceló el sistema, por vencida o por pago rechazado.
+type Cancellation struct {
+	PenaltyPercent float64   `json:"penalty_percent" bson:"penalty_percent"`
+	Penalty        float64   `json:"penalty" bson:"penalty"`
+	Refund         float64   `json:"refund" bson:"refund"`
+	Reason         string    `json:"reason,omitempty" bson:"reason,omitempty"`
+	CancelledAt    time.Time `json:"cancelled_at" bson:"cancelled_at"`
 }
@@ -0,0 +1,34 @@
+package reservations
+
+import "errors"
+
+var ErrInvalidFilter = errors.New("invalid reservation search filter")
+
+const (
+	DefaultSearchLimit = 50
+	MaxSearchLimit     = 200
+)
+
+// SortFields lista los campos por los que se puede ordenar una búsqueda.
+var SortFields = []string{"start_date", "end_date", "created_at", "status", "total"}
+
+// SearchFilter filtra reservas para el personal del hotel. From/To seleccionan las
+// estadías que ocupan al menos una noche del rango [From, To).
+type SearchFilter struct {
+	HotelID  string
+	UserID   string
+	Statuses []string
+	From     Date
+	To       Date
+	SortBy   string
+	SortDesc bool
+	Limit    int
+	Offset   int
+}
+
+type SearchResult struct {
+	Results []Reservation `json:"results"`
+	Total   int64         `json:"total"`
+	Limit   int           `json:"limit"`
+	Offset  int           `json:"offset"`
+}
@@ -0,0 +1,60 @@
+package reservations
+
+import "time"
+
+const (
+	StatusPending    = "pending"
+	StatusConfirmed  = "confirmed"
+	StatusCheckedIn  = "checked_in"
+	StatusCheckedOut = "checked_out"
+	StatusCancelled  = "cancelled"
+	StatusNoShow     = "no_show"
+)
+
+// InactiveStatuses agrupa los estados que ya no ocupan inventario.
+var InactiveStatuses = []string{StatusCancelled, StatusNoShow}
+
+// ModifiableStatuses son los estados en los que todavía se pueden cambiar fechas o habitación.
+var ModifiableStatuses = []string{StatusPending, StatusConfirmed, StatusCheckedIn}
+
+// transitions define, para cada estado, a qué estados se puede pasar.
+var transitions = map[string][]string{
+	StatusPending:   {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusCheckedIn, StatusCancelled, StatusNoShow},
+	StatusCheckedIn: {StatusCheckedOut},
+}
+
+type StatusChange struct {
+	From string    `json:"from" bson:"from"`
+	To   string    `json:"to" bson:"to"`
+	At   time.Time `json:"at" bson:"at"`
+}
+
+// IsInactive indica si una reserva en ese estado ya liberó su inventario.
+func IsInactive(status string) bool {
+	for _, inactive := range InactiveStatuses {
+		if status == inactive {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStatus indica si value es uno de los estados del ciclo de vida.
+func IsStatus(value string) bool {
+	switch value {
+	case StatusPending, StatusConfirmed, StatusCheckedIn, StatusCheckedOut, StatusCancelled, StatusNoShow:
+		return true
+	}
+	return false
+}
+
+// CanTransition indica si una reserva en estado from puede pasar al estado to.
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
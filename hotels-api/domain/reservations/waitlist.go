@@ -0,0 +1,29 @@
+package reservations
+
+import "time"
+
+// Estados de una entrada de la lista de espera
+const (
+	WaitlistWaiting = "waiting"
+	WaitlistOffered = "offered"
+	WaitlistLeft    = "left"
+)
+
+// OfferTTL es el tiempo que tiene el huésped para confirmar la reserva que se le ofreció.
+const OfferTTL = 12 * time.Hour
+
+// WaitlistEntry es un pedido para un tipo de habitación agotado en las fechas indicadas. Cuando se
+// libera inventario la entrada más antigua que entra recibe una reserva pendiente que vence.
+type WaitlistEntry struct {
+	ID            string     `json:"id" bson:"_id,omitempty"`
+	HotelID       string     `json:"hotel_id" bson:"hotel_id"`
+	RoomTypeID    string     `json:"room_type_id" bson:"room_type_id"`
+	UserID        string     `json:"user_id" bson:"user_id"`
+	StartDate     Date       `json:"start_date" bson:"start_date"`
+	EndDate       Date       `json:"end_date" bson:"end_date"`
+	Guests        int        `json:"guests" bson:"guests"`
+	Status        string     `json:"status" bson:"status"`
+	CreatedAt     time.Time  `json:"created_at" bson:"created_at"`
+	ReservationID string     `json:"reservation_id,omitempty" bson:"reservation_id,omitempty"`
+	OfferedAt     *time.Time `json:"offered_at,omitempty" bson:"offered_at,omitempty"`
+}
@@ -0,0 +1,63 @@
+package reviews
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound          = errors.New("review not found")
+	ErrInvalidReview     = errors.New("invalid review")
+	ErrNotEligible       = errors.New("only guests with a completed stay can review the hotel")
+	ErrAlreadyReviewed   = errors.New("stay was already reviewed")
+	ErrInvalidModeration = errors.New("invalid review moderation")
+)
+
+// Estados de una reseña. Solo las aprobadas se publican y cuentan para la calificación del hotel.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// Límites de una reseña
+const (
+	MinScore      = 1
+	MaxScore      = 5
+	MaxTextLength = 2000
+)
+
+// Review es la opinión de un huésped sobre una estadía terminada. Cada reserva admite una sola
+// reseña. Las reseñas nuevas quedan pendientes hasta que un administrador las modera.
+type Review struct {
+	ID            string    `json:"id" bson:"_id,omitempty"`
+	HotelID       string    `json:"hotel_id" bson:"hotel_id"`
+	ReservationID string    `json:"reservation_id" bson:"reservation_id"`
+	UserID        string    `json:"user_id" bson:"user_id"`
+	Score         int       `json:"score" bson:"score"`
+	Text          string    `json:"text" bson:"text"`
+	Status        string    `json:"status" bson:"status"`
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
+	Moderation    *Decision `json:"moderation,omitempty" bson:"moderation,omitempty"`
+	Reply         *Reply    `json:"reply,omitempty" bson:"reply,omitempty"`
+}
+
+// Decision registra quién moderó la reseña y por qué la rechazó.
+type Decision struct {
+	ModeratorID string    `json:"moderator_id" bson:"moderator_id"`
+	Reason      string    `json:"reason,omitempty" bson:"reason,omitempty"`
+	At          time.Time `json:"at" bson:"at"`
+}
+
+// Reply es la respuesta pública del hotel a una reseña; responder de nuevo la reemplaza.
+type Reply struct {
+	AuthorID string    `json:"author_id" bson:"author_id"`
+	Text     string    `json:"text" bson:"text"`
+	At       time.Time `json:"at" bson:"at"`
+}
+
+// Summary es la calificación del hotel: el promedio de las reseñas aprobadas y cuántas son.
+type Summary struct {
+	Rating float64 `json:"rating"`
+	Count  int     `json:"count"`
+}
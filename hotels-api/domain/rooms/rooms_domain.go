@@ -0,0 +1,23 @@
+package rooms
+
+import (
+	"errors"
+	"hotels-api/domain/pricing"
+)
+
+var ErrInvalidRoomType = errors.New("invalid room type")
+
+type Bed struct {
+	Type     string `json:"type"`
+	Quantity int    `json:"quantity"`
+}
+
+type RoomType struct {
+	ID       string            `json:"id"`
+	HotelID  string            `json:"hotel_id"`
+	Name     string            `json:"name"`
+	Capacity int               `json:"capacity"`
+	Beds     []Bed             `json:"beds"`
+	Count    int               `json:"count"`
+	RatePlan *pricing.RatePlan `json:"rate_plan,omitempty"`
+}
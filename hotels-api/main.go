@@ -3,19 +3,50 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 	"time"
 
+	"hotels-api/clients/ical"
+	clientsLoyalty "hotels-api/clients/loyalty"
+	clientsPayments "hotels-api/clients/payments"
 	"hotels-api/clients/queues"
+	controllersAllotments "hotels-api/controllers/allotments"
+	controllersCalendars "hotels-api/controllers/calendars"
+	controllersChannels "hotels-api/controllers/channels"
 	controllersHotels "hotels-api/controllers/hotels"
+	controllersPricing "hotels-api/controllers/pricing"
+	controllersPromotions "hotels-api/controllers/promotions"
 	controllersReservations "hotels-api/controllers/reservations"
+	controllersReviews "hotels-api/controllers/reviews"
+	controllersRooms "hotels-api/controllers/rooms"
 	middleware "hotels-api/middlewares"
+	repositoriesAllotments "hotels-api/repositories/allotments"
+	repositoriesCalendars "hotels-api/repositories/calendars"
+	repositoriesChannels "hotels-api/repositories/channels"
 	repositoriesHotels "hotels-api/repositories/hotels"
+	repositoriesIdempotency "hotels-api/repositories/idempotency"
+	repositoriesInvoices "hotels-api/repositories/invoices"
+	repositoriesLeases "hotels-api/repositories/leases"
+	repositoriesPayments "hotels-api/repositories/payments"
+	repositoriesPromotions "hotels-api/repositories/promotions"
 	repositoriesReservations "hotels-api/repositories/reservations"
+	repositoriesReviews "hotels-api/repositories/reviews"
+	repositoriesRooms "hotels-api/repositories/rooms"
+	repositoriesWaitlist "hotels-api/repositories/waitlist"
+	servicesAllotments "hotels-api/services/allotments"
+	servicesCalendars "hotels-api/services/calendars"
+	servicesChannels "hotels-api/services/channels"
 	servicesHotels "hotels-api/services/hotels"
+	servicesPricing "hotels-api/services/pricing"
+	servicesPromotions "hotels-api/services/promotions"
 	servicesReservations "hotels-api/services/reservations"
+	servicesReviews "hotels-api/services/reviews"
+	servicesRooms "hotels-api/services/rooms"
+	"hotels-api/workers"
 
 	"github.com/gin-contrib/cors" // Importa el paquete de CORS
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -36,10 +67,59 @@ func main() {
 		Database:   "hotels-api",
 		Collection: "hotels",
 	})
+	if err := hotelsRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating hotel indexes: %v", err)
+	}
 
-	reservationsRepo := repositoriesReservations.NewMongo(mongoClient, "hotels-api", "reservations")
+	reservationsRepo := repositoriesReservations.NewMongo(mongoClient, "hotels-api", "reservations", "inventory")
+	if err := reservationsRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating reservation indexes: %v", err)
+	}
+	if err := reservationsRepo.MigrateDates(context.Background()); err != nil {
+		log.Fatalf("Error migrating reservation dates: %v", err)
+	}
+	if err := reservationsRepo.BackfillInventory(context.Background()); err != nil {
+		log.Fatalf("Error backfilling reservation inventory: %v", err)
+	}
+	roomsRepo := repositoriesRooms.NewMongo(mongoClient, "hotels-api", "room_types")
+	waitlistRepo := repositoriesWaitlist.NewMongo(mongoClient, "hotels-api", "waitlist")
+	if err := waitlistRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating waitlist indexes: %v", err)
+	}
+	idempotencyRepo := repositoriesIdempotency.NewMongo(mongoClient, "hotels-api", "idempotency_keys", 24*time.Hour)
+	if err := idempotencyRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating idempotency indexes: %v", err)
+	}
+	paymentsRepo := repositoriesPayments.NewMongo(mongoClient, "hotels-api", "payments")
+	if err := paymentsRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating payments indexes: %v", err)
+	}
+	invoicesRepo := repositoriesInvoices.NewMongo(mongoClient, "hotels-api", "invoices")
+	if err := invoicesRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating invoice indexes: %v", err)
+	}
+	calendarsRepo := repositoriesCalendars.NewMongo(mongoClient, "hotels-api", "calendar_feeds")
+	leasesRepo := repositoriesLeases.NewMongo(mongoClient, "hotels-api", "leases")
+	channelsRepo := repositoriesChannels.NewMongo(mongoClient, "hotels-api", "ical_feeds", "ical_holds")
+	if err := channelsRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating iCal feed indexes: %v", err)
+	}
+	allotmentsRepo := repositoriesAllotments.NewMongo(mongoClient, "hotels-api", "allotments")
+	if err := allotmentsRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating allotment indexes: %v", err)
+	}
+	reviewsRepo := repositoriesReviews.NewMongo(mongoClient, "hotels-api", "reviews")
+	if err := reviewsRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating review indexes: %v", err)
+	}
+	promotionsRepo := repositoriesPromotions.NewMongo(mongoClient, "hotels-api", "promotions", "promotion_redemptions")
+	if err := promotionsRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating promotion indexes: %v", err)
+	}
 
 	// Configuración de Cache y RabbitMQ
+	// El cache vive en memoria de cada instancia: Duration también acota cuánto puede atrasar la
+	// disponibilidad que muestra una réplica que no atendió la reserva
 	cacheRepo := repositoriesHotels.NewCache(repositoriesHotels.CacheConfig{
 		MaxSize:      100000,
 		ItemsToPrune: 100,
@@ -52,14 +132,73 @@ func main() {
 		Password:  "root",
 		QueueName: "hotels-news",
 	})
+	reservationsQueue := queues.NewReservationsRabbit(queues.RabbitConfig{
+		Host:     "rabbitmq",
+		Port:     "5672",
+		Username: "root",
+		Password: "root",
+		Exchange: "reservations",
+	})
+
+	paymentGateway := newPaymentGateway()
+	icalClient := ical.NewClient(30 * time.Second)
+	// Puntos de fidelidad en users-api, por las rutas internas detrás del balanceador
+	loyaltyClient := clientsLoyalty.NewClient(clientsLoyalty.Config{
+		BaseURL: "http://nginx",
+		Token:   os.Getenv("INTERNAL_API_TOKEN"),
+		Timeout: 5 * time.Second,
+	})
 
 	// Servicios
 	hotelsService := servicesHotels.NewService(hotelsRepo, cacheRepo, eventsQueue)
-	reservationsService := servicesReservations.NewService(reservationsRepo)
+	roomsService := servicesRooms.NewService(roomsRepo, hotelsRepo)
+	pricingService := servicesPricing.NewService(roomsRepo, promotionsRepo)
+	reservationsService := servicesReservations.NewService(reservationsRepo, roomsRepo, hotelsRepo, waitlistRepo, cacheRepo, reservationsQueue, paymentsRepo, paymentGateway, invoicesRepo, allotmentsRepo, promotionsRepo, loyaltyClient)
+	calendarsService := servicesCalendars.NewService(calendarsRepo, reservationsRepo, hotelsRepo, roomsRepo)
+	channelsService := servicesChannels.NewService(channelsRepo, reservationsRepo, roomsRepo, hotelsRepo, cacheRepo, icalClient)
+	allotmentsService := servicesAllotments.NewService(allotmentsRepo, reservationsRepo, roomsRepo, hotelsRepo, cacheRepo)
+	reviewsService := servicesReviews.NewService(reviewsRepo, reservationsRepo, hotelsRepo, cacheRepo, eventsQueue)
+	promotionsService := servicesPromotions.NewService(promotionsRepo, hotelsRepo)
+
+	// Tareas periódicas: con varias instancias cada una corre en una sola a la vez
+	hostname, _ := os.Hostname()
+	runner := workers.NewRunner(leasesRepo, hostname+"-"+primitive.NewObjectID().Hex())
+	pendingTTL := durationFromEnv("PENDING_RESERVATION_TTL", 30*time.Minute)
+	// Vence las reservas pendientes sin pagar y las ofertas de la lista de espera no confirmadas
+	runner.Start(context.Background(), workers.Job{
+		Name:     "expire-pending-reservations",
+		Interval: time.Minute,
+		Run: func(ctx context.Context, now time.Time) error {
+			expired, err := reservationsService.ExpirePending(ctx, now, pendingTTL)
+			if expired > 0 {
+				log.Printf("Expired %d pending reservations", expired)
+			}
+			return err
+		},
+	})
+	// Importa los calendarios de los otros canales de venta
+	runner.Start(context.Background(), workers.Job{
+		Name:     "sync-ical-feeds",
+		Interval: 15 * time.Minute,
+		Run:      channelsService.SyncAll,
+	})
+	// Devuelve a la venta las habitaciones de los cupos que llegaron a su fecha de liberación
+	runner.Start(context.Background(), workers.Job{
+		Name:     "release-allotments",
+		Interval: 15 * time.Minute,
+		Run:      allotmentsService.ReleaseDue,
+	})
 
 	// Controladores
 	hotelsController := controllersHotels.NewController(hotelsService)
+	roomsController := controllersRooms.NewController(roomsService)
+	pricingController := controllersPricing.NewController(pricingService)
 	reservationsController := controllersReservations.NewController(reservationsService)
+	calendarsController := controllersCalendars.NewController(calendarsService)
+	channelsController := controllersChannels.NewController(channelsService)
+	allotmentsController := controllersAllotments.NewController(allotmentsService)
+	reviewsController := controllersReviews.NewController(reviewsService)
+	promotionsController := controllersPromotions.NewController(promotionsService)
 
 	jwtMiddleware := middleware.NewJWTMiddleware("ThisIsAnExampleJWTKey!")
 
@@ -67,8 +206,8 @@ func main() {
 	router := gin.Default()
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3001", "*"}, // Permite localhost y cualquier origen
-		AllowMethods:     []string{"POST", "GET", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Content-Type", "Authorization"},
+		AllowMethods:     []string{"POST", "GET", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Content-Type", "Authorization", "Idempotency-Key"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -76,20 +215,129 @@ func main() {
 	adminRoutes := router.Group("/hotels")
 	adminRoutes.Use(jwtMiddleware.Authenticate(), middleware.AdminOnly())
 	{
-		adminRoutes.POST("", hotelsController.Create)
+		adminRoutes.POST("", middleware.Idempotent(idempotencyRepo), hotelsController.Create)
 		adminRoutes.DELETE("/:hotel_id", hotelsController.Delete)
-		//adminRoutes.PUT("/:hotel_id", hotelsController.Update)
+		// Cambia políticas de cancelación, seña e impuestos: solo para administradores
+		adminRoutes.PUT("/:hotel_id", hotelsController.Update)
+		adminRoutes.POST("/:hotel_id/rooms", roomsController.Create)
+		adminRoutes.PUT("/:hotel_id/rooms/:room_type_id", roomsController.Update)
+		adminRoutes.PUT("/:hotel_id/rooms/:room_type_id/rate-plan", roomsController.SetRatePlan)
+		adminRoutes.DELETE("/:hotel_id/rooms/:room_type_id", roomsController.Delete)
+		adminRoutes.GET("/:hotel_id/reservations", reservationsController.Search)
+		adminRoutes.GET("/:hotel_id/reservations.csv", reservationsController.Export)
+		adminRoutes.GET("/:hotel_id/waitlist", reservationsController.GetWaitlistByHotelID)
+		adminRoutes.POST("/:hotel_id/reservations.ics/token", calendarsController.IssueHotelToken)
+		adminRoutes.POST("/:hotel_id/ical-feeds", channelsController.CreateFeed)
+		adminRoutes.GET("/:hotel_id/ical-feeds", channelsController.GetFeeds)
+		adminRoutes.DELETE("/:hotel_id/ical-feeds/:feed_id", channelsController.DeleteFeed)
+		adminRoutes.POST("/:hotel_id/ical-feeds/:feed_id/sync", channelsController.SyncFeed)
+		adminRoutes.GET("/:hotel_id/ical-feeds/:feed_id/holds", channelsController.GetHolds)
+		adminRoutes.POST("/:hotel_id/allotments", allotmentsController.Create)
+		adminRoutes.GET("/:hotel_id/allotments", allotmentsController.GetAll)
+		adminRoutes.GET("/:hotel_id/allotments/:allotment_id", allotmentsController.Get)
+		adminRoutes.POST("/:hotel_id/allotments/:allotment_id/release", allotmentsController.Release)
+		adminRoutes.PUT("/:hotel_id/reviews/:review_id/reply", reviewsController.Reply)
+	}
+	// Moderación de reseñas
+	reviewRoutes := router.Group("/reviews")
+	reviewRoutes.Use(jwtMiddleware.Authenticate(), middleware.AdminOnly())
+	{
+		reviewRoutes.GET("/pending", reviewsController.GetQueue)
+		reviewRoutes.POST("/:review_id/approve", reviewsController.Approve)
+		reviewRoutes.POST("/:review_id/reject", reviewsController.Reject)
+	}
+	// Códigos de descuento
+	promotionRoutes := router.Group("/promotions")
+	promotionRoutes.Use(jwtMiddleware.Authenticate(), middleware.AdminOnly())
+	{
+		promotionRoutes.POST("", promotionsController.Create)
+		promotionRoutes.GET("", promotionsController.GetAll)
+		promotionRoutes.GET("/:promotion_id", promotionsController.GetByID)
 	}
 	// Rutas de Reservas y Hoteles (usando solo `hotel_id` en las rutas para evitar conflictos)
-	router.POST("/reservations", reservationsController.CreateReservation)
+	reservationRoutes := router.Group("/reservations")
+	reservationRoutes.Use(jwtMiddleware.Authenticate())
+	{
+		reservationRoutes.POST("", middleware.Idempotent(idempotencyRepo), reservationsController.CreateReservation)
+		reservationRoutes.GET("", middleware.AdminOnly(), reservationsController.Search)
+		reservationRoutes.GET("/export.csv", middleware.AdminOnly(), reservationsController.Export)
+		reservationRoutes.GET("/:id", reservationsController.GetReservationByID)
+		reservationRoutes.PUT("/:id", reservationsController.Modify)
+		reservationRoutes.PATCH("/:id", reservationsController.Modify)
+		reservationRoutes.POST("/:id/confirm", reservationsController.Confirm)
+		reservationRoutes.POST("/:id/cancel", reservationsController.Cancel)
+		reservationRoutes.POST("/:id/pay", reservationsController.Pay)
+		reservationRoutes.GET("/:id/payment", reservationsController.GetPayment)
+		reservationRoutes.GET("/:id/invoice", reservationsController.Invoice)
+		reservationRoutes.POST("/:id/check-in", middleware.AdminOnly(), reservationsController.CheckIn)
+		reservationRoutes.POST("/:id/check-out", middleware.AdminOnly(), reservationsController.CheckOut)
+		reservationRoutes.POST("/:id/no-show", middleware.AdminOnly(), reservationsController.NoShow)
+	}
+	waitlistRoutes := router.Group("/waitlist")
+	waitlistRoutes.Use(jwtMiddleware.Authenticate())
+	{
+		waitlistRoutes.POST("", reservationsController.JoinWaitlist)
+		waitlistRoutes.GET("/:id", reservationsController.GetWaitlistEntry)
+		waitlistRoutes.DELETE("/:id", reservationsController.LeaveWaitlist)
+	}
+	router.GET("/hotels", hotelsController.GetAll)
 	router.GET("/hotels/:hotel_id", hotelsController.GetHotelByID)
+	router.GET("/hotels/:hotel_id/rooms", roomsController.GetByHotelID)
+	router.GET("/hotels/:hotel_id/rooms/:room_type_id", roomsController.GetByID)
+	router.GET("/hotels/:hotel_id/quote", pricingController.Quote)
+	router.GET("/hotels/:hotel_id/availability", reservationsController.Availability)
+	router.GET("/hotels/:hotel_id/reviews", reviewsController.GetByHotelID)
+	router.POST("/hotels/:hotel_id/reviews", jwtMiddleware.Authenticate(), reviewsController.Create)
 	//router.POST("/hotels", hotelsController.Create)
-	router.PUT("/hotels/:hotel_id", hotelsController.Update)
 	//router.DELETE("/hotels/:hotel_id", hotelsController.Delete)
-	router.GET("/users/:user_id/reservations", reservationsController.GetReservationsByUserID)
+	router.GET("/users/:user_id/reservations", jwtMiddleware.Authenticate(), reservationsController.GetReservationsByUserID)
+	router.GET("/users/:user_id/waitlist", jwtMiddleware.Authenticate(), reservationsController.GetWaitlistByUserID)
+
+	// Feeds de calendario: las apps se suscriben con el token de la URL, sin JWT
+	router.GET("/users/:user_id/reservations.ics", jwtMiddleware.AuthenticateUnlessQuery("token"), calendarsController.UserFeed)
+	router.GET("/hotels/:hotel_id/reservations.ics", jwtMiddleware.AuthenticateUnlessQuery("token"), calendarsController.HotelFeed)
+	router.POST("/users/:user_id/reservations.ics/token", jwtMiddleware.Authenticate(), calendarsController.IssueUserToken)
 
 	// Ejecutar servidor
 	if err := router.Run(":8081"); err != nil {
 		log.Fatalf("Error running server: %v", err)
 	}
 }
+
+// newPaymentGateway elige la pasarela con PAYMENT_GATEWAY: "http" usa el proveedor configurado en
+// PAYMENT_GATEWAY_URL y PAYMENT_GATEWAY_API_KEY, y "fake" la simulada, que aprueba cualquier
+// tarjeta y solo sirve para desarrollo. Sin configuración el servicio no arranca.
+func newPaymentGateway() servicesReservations.PaymentGateway {
+	switch gateway := os.Getenv("PAYMENT_GATEWAY"); gateway {
+	case "http":
+		baseURL, apiKey := os.Getenv("PAYMENT_GATEWAY_URL"), os.Getenv("PAYMENT_GATEWAY_API_KEY")
+		if baseURL == "" || apiKey == "" {
+			log.Fatalf("PAYMENT_GATEWAY_URL and PAYMENT_GATEWAY_API_KEY are required for the http payment gateway")
+		}
+		return clientsPayments.NewHTTP(clientsPayments.Config{
+			BaseURL: baseURL,
+			APIKey:  apiKey,
+			Timeout: durationFromEnv("PAYMENT_GATEWAY_TIMEOUT", 10*time.Second),
+		})
+	case "fake":
+		log.Printf("Using the fake payment gateway: payments are simulated")
+		return clientsPayments.NewFake()
+	default:
+		log.Fatalf("Invalid PAYMENT_GATEWAY %q: use http or fake", gateway)
+		return nil
+	}
+}
+
+// durationFromEnv lee una duración como "45m" o "2h" de la variable name, o usa fallback.
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Invalid %s %q, using %s", name, value, fallback)
+		return fallback
+	}
+	return duration
+}
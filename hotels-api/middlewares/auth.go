@@ -4,12 +4,21 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Claves con las que Authenticate guarda los datos del token en el contexto de gin
+const (
+	UserTypeKey = "userType"
+	UserIDKey   = "userID"
+
+	AdminUserType = "administrador"
+)
+
 type JWTMiddleware struct {
 	SecretKey string
 }
@@ -59,22 +68,50 @@ func (m JWTMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
-		// Almacena el tipo de usuario en el contexto para usarlo posteriormente
-		c.Set("userType", userType)
+		// users-api firma el user_id como número, que llega como float64 en los claims
+		var userID string
+		switch value := claims["user_id"].(type) {
+		case float64:
+			userID = strconv.FormatInt(int64(value), 10)
+		case string:
+			userID = value
+		}
+		if userID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+			return
+		}
+
+		// Almacena el tipo y el ID de usuario en el contexto para usarlos posteriormente
+		c.Set(UserTypeKey, userType)
+		c.Set(UserIDKey, userID)
 
 		c.Next()
 	}
 }
 
+// AuthenticateUnlessQuery deja pasar sin JWT las requests que traen param en la query, para las
+// rutas que validan su propio token, como las suscripciones de calendario. El resto se autentica
+// con el JWT como siempre.
+func (m JWTMiddleware) AuthenticateUnlessQuery(param string) gin.HandlerFunc {
+	authenticate := m.Authenticate()
+	return func(c *gin.Context) {
+		if c.Query(param) != "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
+
 func AdminOnly() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userType, exists := c.Get("userType")
+		userType, exists := c.Get(UserTypeKey)
 		if !exists {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User type not found"})
 			return
 		}
 
-		if userType != "administrador" {
+		if userType != AdminUserType {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden: Administrators only"})
 			return
 		}
@@ -82,3 +119,18 @@ func AdminOnly() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// UserID devuelve el ID del usuario autenticado, o "" si la ruta no pasó por Authenticate.
+func UserID(c *gin.Context) string {
+	return c.GetString(UserIDKey)
+}
+
+// IsAdmin indica si el usuario autenticado es administrador.
+func IsAdmin(c *gin.Context) bool {
+	return c.GetString(UserTypeKey) == AdminUserType
+}
+
+// CanAccess indica si el usuario autenticado es el dueño del recurso o un administrador.
+func CanAccess(c *gin.Context, ownerID string) bool {
+	return IsAdmin(c) || (ownerID != "" && UserID(c) == ownerID)
+}
@@ -0,0 +1,130 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	middleware "hotels-api/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const secret = "test-secret"
+
+func sign(t *testing.T, key string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// newAuthRouter responde con el usuario que dejó Authenticate y si puede acceder a los recursos de owner.
+func newAuthRouter(handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handlers = append([]gin.HandlerFunc{middleware.NewJWTMiddleware(secret).Authenticate()}, handlers...)
+	handlers = append(handlers, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"user_id":    middleware.UserID(c),
+			"can_access": middleware.CanAccess(c, c.Param("owner")),
+		})
+	})
+	router.GET("/owners/:owner", handlers...)
+	return router
+}
+
+type authResponse struct {
+	UserID    string `json:"user_id"`
+	CanAccess bool   `json:"can_access"`
+}
+
+func decode(t *testing.T, response *httptest.ResponseRecorder) authResponse {
+	t.Helper()
+	var body authResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func get(router *gin.Engine, path string, authorization string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestAuthenticateRejectsMissingOrInvalidTokens(t *testing.T) {
+	router := newAuthRouter()
+
+	cases := map[string]string{
+		"missing header": "",
+		"bad format":     sign(t, secret, jwt.MapClaims{"tipo": "cliente", "user_id": float64(7)}),
+		"wrong secret":   "Bearer " + sign(t, "other-secret", jwt.MapClaims{"tipo": "cliente", "user_id": float64(7)}),
+		"without type":   "Bearer " + sign(t, secret, jwt.MapClaims{"user_id": float64(7)}),
+		"without user":   "Bearer " + sign(t, secret, jwt.MapClaims{"tipo": "cliente"}),
+	}
+	for name, authorization := range cases {
+		if response := get(router, "/owners/7", authorization); response.Code != http.StatusUnauthorized {
+			t.Fatalf("%s: expected 401, got %d %s", name, response.Code, response.Body)
+		}
+	}
+}
+
+func TestAuthenticateStoresTheUserOfTheToken(t *testing.T) {
+	router := newAuthRouter()
+
+	// users-api firma el user_id como número
+	response := get(router, "/owners/7", "Bearer "+sign(t, secret, jwt.MapClaims{"tipo": "cliente", "user_id": float64(7)}))
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", response.Code, response.Body)
+	}
+	if body := decode(t, response); body.UserID != "7" || !body.CanAccess {
+		t.Fatalf("expected user 7 with access, got %+v", body)
+	}
+}
+
+func TestCanAccess(t *testing.T) {
+	router := newAuthRouter()
+
+	cases := []struct {
+		name   string
+		claims jwt.MapClaims
+		path   string
+		access bool
+	}{
+		{"owner", jwt.MapClaims{"tipo": "cliente", "user_id": float64(7)}, "/owners/7", true},
+		{"non-owner", jwt.MapClaims{"tipo": "cliente", "user_id": float64(8)}, "/owners/7", false},
+		{"admin", jwt.MapClaims{"tipo": middleware.AdminUserType, "user_id": float64(1)}, "/owners/7", true},
+	}
+	for _, tc := range cases {
+		response := get(router, tc.path, "Bearer "+sign(t, secret, tc.claims))
+		if response.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d %s", tc.name, response.Code, response.Body)
+		}
+		if body := decode(t, response); body.CanAccess != tc.access {
+			t.Fatalf("%s: expected can_access %t, got %t", tc.name, tc.access, body.CanAccess)
+		}
+	}
+}
+
+func TestAdminOnly(t *testing.T) {
+	router := newAuthRouter(middleware.AdminOnly())
+
+	customer := get(router, "/owners/7", "Bearer "+sign(t, secret, jwt.MapClaims{"tipo": "cliente", "user_id": float64(7)}))
+	if customer.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a customer, got %d", customer.Code)
+	}
+	admin := get(router, "/owners/7", "Bearer "+sign(t, secret, jwt.MapClaims{"tipo": middleware.AdminUserType, "user_id": float64(1)}))
+	if admin.Code != http.StatusOK {
+		t.Fatalf("expected 200 for an administrator, got %d", admin.Code)
+	}
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hotels-api/domain/idempotency"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxIdempotencyKeyLength acota el largo de las claves que se guardan en Mongo
+const maxIdempotencyKeyLength = 255
+
+type IdempotencyRepository interface {
+	Begin(ctx context.Context, record idempotency.Record) (*idempotency.Record, error)
+	Complete(ctx context.Context, id string, status int, contentType string, body []byte) error
+	Release(ctx context.Context, id string) error
+}
+
+// Idempotent hace que los reintentos de una request con el mismo Idempotency-Key devuelvan la
+// respuesta original en lugar de repetir la escritura. La clave se aísla por usuario y ruta, y
+// reusarla con otro body se rechaza. Sin el header la request sigue de largo.
+func Idempotent(repository IdempotencyRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(idempotency.HeaderKey))
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be at most %d characters", idempotency.HeaderKey, maxIdempotencyKeyLength)})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request: %s", err.Error())})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.Sum256(body)
+
+		record := idempotency.Record{
+			ID:          fmt.Sprintf("%s:%s %s:%s", UserID(c), c.Request.Method, c.FullPath(), key),
+			RequestHash: hex.EncodeToString(hash[:]),
+			CreatedAt:   time.Now().UTC(),
+		}
+		existing, err := repository.Begin(c.Request.Context(), record)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("error checking %s: %s", idempotency.HeaderKey, err.Error())})
+			return
+		}
+		if existing != nil {
+			replay(c, *existing, record.RequestHash)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		completed := false
+		defer func() {
+			// Si el handler falló o entró en pánico se libera la clave para permitir el reintento
+			if !completed {
+				if err := repository.Release(context.Background(), record.ID); err != nil {
+					log.Printf("error releasing idempotency key %s: %v", record.ID, err)
+				}
+			}
+		}()
+
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+		if err := repository.Complete(c.Request.Context(), record.ID, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			log.Printf("error saving idempotent response for %s: %v", record.ID, err)
+			return
+		}
+		completed = true
+	}
+}
+
+// replay responde a un reintento con la respuesta guardada de la request original.
+func replay(c *gin.Context, existing idempotency.Record, requestHash string) {
+	if existing.RequestHash != requestHash {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("%s was already used with a different request body", idempotency.HeaderKey)})
+		return
+	}
+	if !existing.Completed {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("a request with this %s is still in progress", idempotency.HeaderKey)})
+		return
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(existing.Status, existing.ContentType, existing.Body)
+	c.Abort()
+}
+
+// responseRecorder copia lo que escribe el handler para poder guardarlo junto a la clave.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(data string) (int, error) {
+	r.body.WriteString(data)
+	return r.ResponseWriter.WriteString(data)
+}
@@ -0,0 +1,87 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	middleware "hotels-api/middlewares"
+	repositoriesIdempotency "hotels-api/repositories/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newIdempotentRouter(calls *int, status int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/reservations", middleware.Idempotent(repositoriesIdempotency.NewMock()), func(c *gin.Context) {
+		*calls++
+		c.JSON(status, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func post(router *gin.Engine, key string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/reservations", strings.NewReader(body))
+	if key != "" {
+		request.Header.Set("Idempotency-Key", key)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIdempotentReplaysOriginalResponse(t *testing.T) {
+	calls := 0
+	router := newIdempotentRouter(&calls, http.StatusCreated)
+
+	first := post(router, "abc", `{"hotel_id":"1"}`)
+	replayed := post(router, "abc", `{"hotel_id":"1"}`)
+
+	if calls != 1 {
+		t.Fatalf("expected the handler to run once, ran %d times", calls)
+	}
+	if replayed.Code != http.StatusCreated || replayed.Body.String() != first.Body.String() {
+		t.Fatalf("expected replay of %d %s, got %d %s", first.Code, first.Body, replayed.Code, replayed.Body)
+	}
+	if replayed.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("expected replayed response to be flagged")
+	}
+}
+
+func TestIdempotentRejectsDifferentBody(t *testing.T) {
+	calls := 0
+	router := newIdempotentRouter(&calls, http.StatusCreated)
+
+	post(router, "abc", `{"hotel_id":"1"}`)
+	response := post(router, "abc", `{"hotel_id":"2"}`)
+
+	if response.Code != http.StatusUnprocessableEntity || calls != 1 {
+		t.Fatalf("expected 422 without running the handler, got %d after %d calls", response.Code, calls)
+	}
+}
+
+func TestIdempotentReleasesKeyOnServerError(t *testing.T) {
+	calls := 0
+	router := newIdempotentRouter(&calls, http.StatusInternalServerError)
+
+	post(router, "abc", `{}`)
+	post(router, "abc", `{}`)
+
+	if calls != 2 {
+		t.Fatalf("expected failed request to be retried, handler ran %d times", calls)
+	}
+}
+
+func TestIdempotentWithoutKey(t *testing.T) {
+	calls := 0
+	router := newIdempotentRouter(&calls, http.StatusCreated)
+
+	post(router, "", `{}`)
+	post(router, "", `{}`)
+
+	if calls != 2 {
+		t.Fatalf("expected requests without key to always run, handler ran %d times", calls)
+	}
+}
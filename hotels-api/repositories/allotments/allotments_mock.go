@@ -0,0 +1,97 @@
+package allotments
+
+import (
+	"context"
+	"fmt"
+	"hotels-api/domain/allotments"
+	"hotels-api/domain/reservations"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Mock struct {
+	mutex *sync.Mutex
+	docs  map[string]allotments.Allotment
+}
+
+func NewMock() Mock {
+	return Mock{
+		mutex: &sync.Mutex{},
+		docs:  make(map[string]allotments.Allotment),
+	}
+}
+
+func (repository Mock) Create(ctx context.Context, allotment allotments.Allotment) (string, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, existing := range repository.docs {
+		if existing.HotelID == allotment.HotelID && existing.Code == allotment.Code {
+			return "", fmt.Errorf("code %q is already used in this hotel: %w", allotment.Code, allotments.ErrInvalidAllotment)
+		}
+	}
+	allotment.ID = primitive.NewObjectID().Hex()
+	repository.docs[allotment.ID] = allotment
+	return allotment.ID, nil
+}
+
+func (repository Mock) GetByID(ctx context.Context, id string) (allotments.Allotment, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	allotment, exists := repository.docs[id]
+	if !exists {
+		return allotments.Allotment{}, fmt.Errorf("room block %s: %w", id, allotments.ErrNotFound)
+	}
+	return allotment, nil
+}
+
+func (repository Mock) GetByCode(ctx context.Context, hotelID, code string) (allotments.Allotment, error) {
+	for _, allotment := range repository.find(func(a allotments.Allotment) bool { return a.HotelID == hotelID && a.Code == code }) {
+		return allotment, nil
+	}
+	return allotments.Allotment{}, fmt.Errorf("room block %s: %w", code, allotments.ErrNotFound)
+}
+
+func (repository Mock) GetByHotelID(ctx context.Context, hotelID string) ([]allotments.Allotment, error) {
+	return repository.find(func(a allotments.Allotment) bool { return a.HotelID == hotelID }), nil
+}
+
+func (repository Mock) GetDue(ctx context.Context, before reservations.Date) ([]allotments.Allotment, error) {
+	return repository.find(func(a allotments.Allotment) bool {
+		return a.Status == allotments.StatusActive && a.ReleaseDate.Before(before.Time)
+	}), nil
+}
+
+func (repository Mock) find(match func(allotments.Allotment) bool) []allotments.Allotment {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	result := make([]allotments.Allotment, 0)
+	for _, allotment := range repository.docs {
+		if match(allotment) {
+			result = append(result, allotment)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartDate.Before(result[j].StartDate.Time) })
+	return result
+}
+
+func (repository Mock) MarkReleased(ctx context.Context, id string, releasedRooms int, at time.Time) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	allotment, exists := repository.docs[id]
+	if !exists {
+		return fmt.Errorf("room block %s: %w", id, allotments.ErrNotFound)
+	}
+	if allotment.Status != allotments.StatusActive {
+		return fmt.Errorf("room block %s is no longer active: %w", id, allotments.ErrBlockClosed)
+	}
+	allotment.Status, allotment.ReleasedAt, allotment.ReleasedRooms = allotments.StatusReleased, &at, releasedRooms
+	repository.docs[id] = allotment
+	return nil
+}
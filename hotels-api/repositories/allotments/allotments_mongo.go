@@ -0,0 +1,118 @@
+package allotments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/allotments"
+	"hotels-api/domain/reservations"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	client     *mongo.Client
+	database   string
+	collection string
+}
+
+func NewMongo(client *mongo.Client, database, collection string) Mongo {
+	return Mongo{client: client, database: database, collection: collection}
+}
+
+// EnsureIndexes hace único el código de cada cupo dentro del hotel y acelera la búsqueda de los
+// cupos que hay que liberar.
+func (m Mongo) EnsureIndexes(ctx context.Context) error {
+	_, err := m.allotments().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "hotel_id", Value: 1}, {Key: "code", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "release_date", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating allotment indexes: %w", err)
+	}
+	return nil
+}
+
+func (m Mongo) allotments() *mongo.Collection {
+	return m.client.Database(m.database).Collection(m.collection)
+}
+
+func (m Mongo) Create(ctx context.Context, allotment allotments.Allotment) (string, error) {
+	result, err := m.allotments().InsertOne(ctx, allotment)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", fmt.Errorf("code %q is already used in this hotel: %w", allotment.Code, allotments.ErrInvalidAllotment)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error creating allotment: %w", err)
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (m Mongo) GetByID(ctx context.Context, id string) (allotments.Allotment, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return allotments.Allotment{}, fmt.Errorf("invalid ID %q: %w", id, allotments.ErrNotFound)
+	}
+	return m.findOne(ctx, bson.M{"_id": objectID}, id)
+}
+
+// GetByCode busca el cupo por el código que reciben los invitados del evento o la empresa.
+func (m Mongo) GetByCode(ctx context.Context, hotelID, code string) (allotments.Allotment, error) {
+	return m.findOne(ctx, bson.M{"hotel_id": hotelID, "code": code}, code)
+}
+
+func (m Mongo) findOne(ctx context.Context, query bson.M, reference string) (allotments.Allotment, error) {
+	var allotment allotments.Allotment
+	err := m.allotments().FindOne(ctx, query).Decode(&allotment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return allotments.Allotment{}, fmt.Errorf("room block %s: %w", reference, allotments.ErrNotFound)
+	}
+	if err != nil {
+		return allotments.Allotment{}, fmt.Errorf("error getting allotment: %w", err)
+	}
+	return allotment, nil
+}
+
+func (m Mongo) GetByHotelID(ctx context.Context, hotelID string) ([]allotments.Allotment, error) {
+	return m.find(ctx, bson.M{"hotel_id": hotelID}, options.Find().SetSort(bson.D{{Key: "start_date", Value: 1}}))
+}
+
+// GetDue devuelve los cupos activos cuya fecha de liberación es anterior a before.
+func (m Mongo) GetDue(ctx context.Context, before reservations.Date) ([]allotments.Allotment, error) {
+	query := bson.M{"status": allotments.StatusActive, "release_date": bson.M{"$lt": before}}
+	return m.find(ctx, query, options.Find().SetSort(bson.D{{Key: "release_date", Value: 1}}))
+}
+
+func (m Mongo) find(ctx context.Context, query bson.M, opts *options.FindOptions) ([]allotments.Allotment, error) {
+	cursor, err := m.allotments().Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error getting allotments: %w", err)
+	}
+	result := make([]allotments.Allotment, 0)
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("error decoding allotments: %w", err)
+	}
+	return result, nil
+}
+
+// MarkReleased pasa el cupo a liberado solo si seguía activo.
+func (m Mongo) MarkReleased(ctx context.Context, id string, releasedRooms int, at time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q: %w", id, allotments.ErrNotFound)
+	}
+	filter := bson.M{"_id": objectID, "status": allotments.StatusActive}
+	update := bson.M{"$set": bson.M{"status": allotments.StatusReleased, "released_at": at, "released_rooms": releasedRooms}}
+	result, err := m.allotments().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("error releasing allotment: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("room block %s is no longer active: %w", id, allotments.ErrBlockClosed)
+	}
+	return nil
+}
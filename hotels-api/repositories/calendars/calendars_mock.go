@@ -0,0 +1,40 @@
+package calendars
+
+import (
+	"context"
+	"fmt"
+	"hotels-api/domain/calendars"
+	"sync"
+)
+
+type Mock struct {
+	mutex *sync.Mutex
+	docs  map[string]calendars.Feed
+}
+
+func NewMock() Mock {
+	return Mock{
+		mutex: &sync.Mutex{},
+		docs:  make(map[string]calendars.Feed),
+	}
+}
+
+func (repository Mock) Save(ctx context.Context, feed calendars.Feed) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	feed.ID = calendars.FeedID(feed.Scope, feed.SubjectID)
+	repository.docs[feed.ID] = feed
+	return nil
+}
+
+func (repository Mock) Get(ctx context.Context, scope, subjectID string) (calendars.Feed, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	feed, exists := repository.docs[calendars.FeedID(scope, subjectID)]
+	if !exists {
+		return calendars.Feed{}, fmt.Errorf("%s %s: %w", scope, subjectID, calendars.ErrNotFound)
+	}
+	return feed, nil
+}
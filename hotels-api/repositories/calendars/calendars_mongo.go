@@ -0,0 +1,44 @@
+package calendars
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/calendars"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	client     *mongo.Client
+	database   string
+	collection string
+}
+
+func NewMongo(client *mongo.Client, database, collection string) Mongo {
+	return Mongo{client: client, database: database, collection: collection}
+}
+
+// Save reemplaza el feed del huésped u hotel, con lo que el token anterior deja de valer.
+func (m Mongo) Save(ctx context.Context, feed calendars.Feed) error {
+	feed.ID = calendars.FeedID(feed.Scope, feed.SubjectID)
+	_, err := m.client.Database(m.database).Collection(m.collection).ReplaceOne(ctx, bson.M{"_id": feed.ID}, feed, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("error saving calendar feed: %w", err)
+	}
+	return nil
+}
+
+func (m Mongo) Get(ctx context.Context, scope, subjectID string) (calendars.Feed, error) {
+	var feed calendars.Feed
+	err := m.client.Database(m.database).Collection(m.collection).FindOne(ctx, bson.M{"_id": calendars.FeedID(scope, subjectID)}).Decode(&feed)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return calendars.Feed{}, fmt.Errorf("%s %s: %w", scope, subjectID, calendars.ErrNotFound)
+	}
+	if err != nil {
+		return calendars.Feed{}, fmt.Errorf("error getting calendar feed: %w", err)
+	}
+	return feed, nil
+}
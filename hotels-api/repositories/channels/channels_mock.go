@@ -0,0 +1,131 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"hotels-api/domain/channels"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Mock struct {
+	mutex *sync.Mutex
+	feeds map[string]channels.Feed
+	holds map[string]channels.Hold
+}
+
+func NewMock() Mock {
+	return Mock{
+		mutex: &sync.Mutex{},
+		feeds: make(map[string]channels.Feed),
+		holds: make(map[string]channels.Hold),
+	}
+}
+
+func (repository Mock) CreateFeed(ctx context.Context, feed channels.Feed) (string, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	feed.ID = primitive.NewObjectID().Hex()
+	repository.feeds[feed.ID] = feed
+	return feed.ID, nil
+}
+
+func (repository Mock) GetFeedByID(ctx context.Context, id string) (channels.Feed, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	feed, exists := repository.feeds[id]
+	if !exists {
+		return channels.Feed{}, fmt.Errorf("feed %s: %w", id, channels.ErrFeedNotFound)
+	}
+	return feed, nil
+}
+
+func (repository Mock) GetFeedsByHotelID(ctx context.Context, hotelID string) ([]channels.Feed, error) {
+	return repository.findFeeds(func(feed channels.Feed) bool { return feed.HotelID == hotelID }), nil
+}
+
+func (repository Mock) GetFeeds(ctx context.Context) ([]channels.Feed, error) {
+	return repository.findFeeds(func(channels.Feed) bool { return true }), nil
+}
+
+func (repository Mock) findFeeds(match func(channels.Feed) bool) []channels.Feed {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	feeds := make([]channels.Feed, 0)
+	for _, feed := range repository.feeds {
+		if match(feed) {
+			feeds = append(feeds, feed)
+		}
+	}
+	sort.Slice(feeds, func(i, j int) bool { return feeds[i].ID < feeds[j].ID })
+	return feeds
+}
+
+func (repository Mock) UpdateSync(ctx context.Context, id string, at time.Time, events, skipped int, lastError string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	feed, exists := repository.feeds[id]
+	if !exists {
+		return fmt.Errorf("feed %s: %w", id, channels.ErrFeedNotFound)
+	}
+	feed.SyncedAt, feed.Events, feed.Skipped, feed.LastError = &at, events, skipped, lastError
+	repository.feeds[id] = feed
+	return nil
+}
+
+func (repository Mock) DeleteFeed(ctx context.Context, id string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, exists := repository.feeds[id]; !exists {
+		return fmt.Errorf("feed %s: %w", id, channels.ErrFeedNotFound)
+	}
+	delete(repository.feeds, id)
+	return nil
+}
+
+func (repository Mock) GetHolds(ctx context.Context, feedID string) ([]channels.Hold, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	holds := make([]channels.Hold, 0)
+	for _, hold := range repository.holds {
+		if hold.FeedID == feedID {
+			holds = append(holds, hold)
+		}
+	}
+	sort.Slice(holds, func(i, j int) bool { return holds[i].StartDate.Before(holds[j].StartDate.Time) })
+	return holds, nil
+}
+
+func (repository Mock) CreateHold(ctx context.Context, hold channels.Hold) (string, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	hold.ID = primitive.NewObjectID().Hex()
+	repository.holds[hold.ID] = hold
+	return hold.ID, nil
+}
+
+func (repository Mock) UpdateHold(ctx context.Context, hold channels.Hold) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.holds[hold.ID] = hold
+	return nil
+}
+
+func (repository Mock) DeleteHold(ctx context.Context, id string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	delete(repository.holds, id)
+	return nil
+}
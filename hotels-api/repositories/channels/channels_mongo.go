@@ -0,0 +1,174 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/channels"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	client          *mongo.Client
+	database        string
+	feedsCollection string
+	holdsCollection string
+}
+
+// NewMongo guarda los feeds externos en feedsCollection y sus bloqueos en holdsCollection.
+func NewMongo(client *mongo.Client, database, feedsCollection, holdsCollection string) Mongo {
+	return Mongo{client: client, database: database, feedsCollection: feedsCollection, holdsCollection: holdsCollection}
+}
+
+// EnsureIndexes garantiza un solo bloqueo por evento y tipo de habitación de cada feed.
+func (m Mongo) EnsureIndexes(ctx context.Context) error {
+	if _, err := m.holds().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "feed_id", Value: 1}, {Key: "uid", Value: 1}, {Key: "room_type_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return fmt.Errorf("error creating hold indexes: %w", err)
+	}
+	if _, err := m.feeds().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "hotel_id", Value: 1}},
+	}); err != nil {
+		return fmt.Errorf("error creating feed indexes: %w", err)
+	}
+	return nil
+}
+
+func (m Mongo) feeds() *mongo.Collection {
+	return m.client.Database(m.database).Collection(m.feedsCollection)
+}
+
+func (m Mongo) holds() *mongo.Collection {
+	return m.client.Database(m.database).Collection(m.holdsCollection)
+}
+
+func (m Mongo) CreateFeed(ctx context.Context, feed channels.Feed) (string, error) {
+	result, err := m.feeds().InsertOne(ctx, feed)
+	if err != nil {
+		return "", fmt.Errorf("error creating feed: %w", err)
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (m Mongo) GetFeedByID(ctx context.Context, id string) (channels.Feed, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return channels.Feed{}, fmt.Errorf("invalid ID %q: %w", id, channels.ErrFeedNotFound)
+	}
+	var feed channels.Feed
+	err = m.feeds().FindOne(ctx, bson.M{"_id": objectID}).Decode(&feed)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return channels.Feed{}, fmt.Errorf("feed %s: %w", id, channels.ErrFeedNotFound)
+	}
+	if err != nil {
+		return channels.Feed{}, fmt.Errorf("error getting feed: %w", err)
+	}
+	return feed, nil
+}
+
+func (m Mongo) GetFeedsByHotelID(ctx context.Context, hotelID string) ([]channels.Feed, error) {
+	return m.findFeeds(ctx, bson.M{"hotel_id": hotelID})
+}
+
+// GetFeeds devuelve todos los feeds, para la sincronización periódica.
+func (m Mongo) GetFeeds(ctx context.Context) ([]channels.Feed, error) {
+	return m.findFeeds(ctx, bson.M{})
+}
+
+func (m Mongo) findFeeds(ctx context.Context, query bson.M) ([]channels.Feed, error) {
+	cursor, err := m.feeds().Find(ctx, query, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("error getting feeds: %w", err)
+	}
+	feeds := make([]channels.Feed, 0)
+	if err := cursor.All(ctx, &feeds); err != nil {
+		return nil, fmt.Errorf("error decoding feeds: %w", err)
+	}
+	return feeds, nil
+}
+
+// UpdateSync guarda el resultado de la última sincronización del feed.
+func (m Mongo) UpdateSync(ctx context.Context, id string, at time.Time, events, skipped int, lastError string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q: %w", id, channels.ErrFeedNotFound)
+	}
+	update := bson.M{"$set": bson.M{"synced_at": at, "events": events, "skipped": skipped, "last_error": lastError}}
+	if _, err := m.feeds().UpdateOne(ctx, bson.M{"_id": objectID}, update); err != nil {
+		return fmt.Errorf("error updating feed: %w", err)
+	}
+	return nil
+}
+
+func (m Mongo) DeleteFeed(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q: %w", id, channels.ErrFeedNotFound)
+	}
+	result, err := m.feeds().DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return fmt.Errorf("error deleting feed: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("feed %s: %w", id, channels.ErrFeedNotFound)
+	}
+	return nil
+}
+
+func (m Mongo) GetHolds(ctx context.Context, feedID string) ([]channels.Hold, error) {
+	cursor, err := m.holds().Find(ctx, bson.M{"feed_id": feedID}, options.Find().SetSort(bson.D{{Key: "start_date", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("error getting holds: %w", err)
+	}
+	holds := make([]channels.Hold, 0)
+	if err := cursor.All(ctx, &holds); err != nil {
+		return nil, fmt.Errorf("error decoding holds: %w", err)
+	}
+	return holds, nil
+}
+
+func (m Mongo) CreateHold(ctx context.Context, hold channels.Hold) (string, error) {
+	result, err := m.holds().InsertOne(ctx, hold)
+	if err != nil {
+		return "", fmt.Errorf("error creating hold: %w", err)
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+// UpdateHold guarda fechas, cantidad y estado del bloqueo.
+func (m Mongo) UpdateHold(ctx context.Context, hold channels.Hold) error {
+	objectID, err := primitive.ObjectIDFromHex(hold.ID)
+	if err != nil {
+		return fmt.Errorf("invalid hold ID %q: %w", hold.ID, channels.ErrFeedNotFound)
+	}
+	update := bson.M{"$set": bson.M{
+		"summary":    hold.Summary,
+		"start_date": hold.StartDate,
+		"end_date":   hold.EndDate,
+		"quantity":   hold.Quantity,
+		"status":     hold.Status,
+		"updated_at": hold.UpdatedAt,
+	}}
+	if _, err := m.holds().UpdateOne(ctx, bson.M{"_id": objectID}, update); err != nil {
+		return fmt.Errorf("error updating hold: %w", err)
+	}
+	return nil
+}
+
+func (m Mongo) DeleteHold(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid hold ID %q: %w", id, channels.ErrFeedNotFound)
+	}
+	if _, err := m.holds().DeleteOne(ctx, bson.M{"_id": objectID}); err != nil {
+		return fmt.Errorf("error deleting hold: %w", err)
+	}
+	return nil
+}
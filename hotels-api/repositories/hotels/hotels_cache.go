@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	hotelsDAO "hotels-api/dao/hotels"
+	hotelsDomain "hotels-api/domain/hotels"
+	"hotels-api/domain/reservations"
 	"time"
 
 	"github.com/karlseguin/ccache"
 )
 
 const (
-	keyFormat = "hotel:%s"
+	keyFormat             = "hotel:%s"
+	availabilityKeyFormat = "%s:%s:%s"
 )
 
 type CacheConfig struct {
@@ -19,18 +22,29 @@ type CacheConfig struct {
 	Duration     time.Duration
 }
 
+// Cache guarda hoteles y calendarios de disponibilidad en la memoria del proceso. No se comparte
+// entre instancias: DeleteAvailability solo limpia la instancia que atendió el cambio, y las demás
+// siguen mostrando su calendario hasta que vence Duration. Con más de una réplica de hotels-api
+// la disponibilidad publicada puede atrasar hasta Duration; las reservas no, porque Book valida
+// contra los contadores de Mongo y nunca contra este cache.
 type Cache struct {
-	client   *ccache.Cache
-	duration time.Duration
+	client *ccache.Cache
+	// Los calendarios se guardan por hotel para poder invalidarlos todos juntos
+	availability *ccache.LayeredCache
+	duration     time.Duration
 }
 
 func NewCache(config CacheConfig) Cache {
 	client := ccache.New(ccache.Configure().
 		MaxSize(config.MaxSize).
 		ItemsToPrune(config.ItemsToPrune))
+	availability := ccache.Layered(ccache.Configure().
+		MaxSize(config.MaxSize).
+		ItemsToPrune(config.ItemsToPrune))
 	return Cache{
-		client:   client,
-		duration: config.Duration,
+		client:       client,
+		availability: availability,
+		duration:     config.Duration,
 	}
 }
 
@@ -57,7 +71,7 @@ func (repository Cache) Create(ctx context.Context, hotel hotelsDAO.Hotel) (stri
 }
 
 func (repository Cache) Update(ctx context.Context, hotel hotelsDAO.Hotel) error {
-	key := fmt.Sprintf(keyFormat, hotel.ID)
+	key := fmt.Sprintf(keyFormat, hotel.ID.Hex())
 
 	// Retrieve the current hotel data from the cache
 	item := repository.client.Get(key)
@@ -97,6 +111,28 @@ func (repository Cache) Update(ctx context.Context, hotel hotelsDAO.Hotel) error
 	if len(hotel.Descripcion) > 0 {
 		currentHotel.Descripcion = hotel.Descripcion
 	}
+	if hotel.Policy != nil {
+		currentHotel.Policy = hotel.Policy
+	}
+	if hotel.TimeZone != "" {
+		currentHotel.TimeZone = hotel.TimeZone
+	}
+	if hotel.CheckInTime != "" {
+		currentHotel.CheckInTime = hotel.CheckInTime
+	}
+	if hotel.BookingCutoff != "" {
+		currentHotel.BookingCutoff = hotel.BookingCutoff
+	}
+	if hotel.MaxStayNights > 0 {
+		currentHotel.MaxStayNights = hotel.MaxStayNights
+	}
+	if hotel.TaxID != "" {
+		currentHotel.TaxID = hotel.TaxID
+	}
+	if hotel.TaxPercent > 0 {
+		currentHotel.TaxPercent = hotel.TaxPercent
+	}
+	clearFields(&currentHotel, hotel.Clear)
 
 	// Update the cache with the new hotel data and reset the expiration timer
 	repository.client.Set(key, currentHotel, repository.duration)
@@ -104,9 +140,56 @@ func (repository Cache) Update(ctx context.Context, hotel hotelsDAO.Hotel) error
 	return nil
 }
 
+// List no se resuelve desde la cache: los listados se leen siempre de Mongo.
+// clearFields vuelve a su valor por defecto los campos que la actualización pide borrar.
+func clearFields(hotel *hotelsDAO.Hotel, fields []string) {
+	for _, field := range fields {
+		switch field {
+		case hotelsDomain.FieldPolicy:
+			hotel.Policy = nil
+		case hotelsDomain.FieldMaxStayNights:
+			hotel.MaxStayNights = 0
+		case hotelsDomain.FieldTaxPercent:
+			hotel.TaxPercent = 0
+		}
+	}
+}
+
+func (repository Cache) List(ctx context.Context, filter hotelsDomain.ListFilter) ([]hotelsDAO.Hotel, int64, error) {
+	return nil, 0, fmt.Errorf("hotel lists are not cached")
+}
+
 func (repository Cache) Delete(ctx context.Context, id string) error {
 	key := fmt.Sprintf(keyFormat, id)
 	// Remove the item from the cache
 	repository.client.Delete(key)
 	return nil
 }
+
+func (repository Cache) GetAvailability(ctx context.Context, hotelID string, roomTypeID string, from, to reservations.Date) (reservations.Availability, error) {
+	key := fmt.Sprintf(availabilityKeyFormat, roomTypeID, from, to)
+	item := repository.availability.Get(hotelID, key)
+	if item == nil {
+		return reservations.Availability{}, fmt.Errorf("not found item with key %s", key)
+	}
+	if item.Expired() {
+		return reservations.Availability{}, fmt.Errorf("item with key %s is expired", key)
+	}
+	availability, ok := item.Value().(reservations.Availability)
+	if !ok {
+		return reservations.Availability{}, fmt.Errorf("error converting item with key %s", key)
+	}
+	return availability, nil
+}
+
+func (repository Cache) SetAvailability(ctx context.Context, roomTypeID string, availability reservations.Availability) error {
+	key := fmt.Sprintf(availabilityKeyFormat, roomTypeID, availability.From, availability.To)
+	repository.availability.Set(availability.HotelID, key, availability, repository.duration)
+	return nil
+}
+
+// DeleteAvailability descarta todos los calendarios cacheados del hotel en esta instancia.
+func (repository Cache) DeleteAvailability(ctx context.Context, hotelID string) error {
+	repository.availability.DeleteAll(hotelID)
+	return nil
+}
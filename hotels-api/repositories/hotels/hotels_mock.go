@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	hotelsDAO "hotels-api/dao/hotels"
+	hotelsDomain "hotels-api/domain/hotels"
+	"sort"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -60,11 +63,102 @@ func (repository Mock) Update(ctx context.Context, hotel hotelsDAO.Hotel) error
 	if len(hotel.Descripcion) > 0 {
 		currentHotel.Descripcion = hotel.Descripcion
 	}
+	if hotel.Policy != nil {
+		currentHotel.Policy = hotel.Policy
+	}
+	if hotel.TimeZone != "" {
+		currentHotel.TimeZone = hotel.TimeZone
+	}
+	if hotel.CheckInTime != "" {
+		currentHotel.CheckInTime = hotel.CheckInTime
+	}
+	if hotel.BookingCutoff != "" {
+		currentHotel.BookingCutoff = hotel.BookingCutoff
+	}
+	if hotel.MaxStayNights > 0 {
+		currentHotel.MaxStayNights = hotel.MaxStayNights
+	}
+	if hotel.TaxID != "" {
+		currentHotel.TaxID = hotel.TaxID
+	}
+	if hotel.TaxPercent > 0 {
+		currentHotel.TaxPercent = hotel.TaxPercent
+	}
+	clearFields(&currentHotel, hotel.Clear)
 	// Save the updated hotel back to the mock storage
 	repository.docs[hotel.ID.Hex()] = currentHotel
 	return nil
 }
 
+func (repository Mock) SetRating(ctx context.Context, id string, rating float64, reviewCount int) error {
+	hotel, exists := repository.docs[id]
+	if !exists {
+		return fmt.Errorf("hotel with ID %s not found", id)
+	}
+	hotel.Rating, hotel.ReviewCount = rating, reviewCount
+	repository.docs[id] = hotel
+	return nil
+}
+
+// List filtra, ordena y pagina en memoria con las mismas reglas que Mongo, salvo los acentos, que
+// aquí sí se distinguen.
+func (repository Mock) List(ctx context.Context, filter hotelsDomain.ListFilter) ([]hotelsDAO.Hotel, int64, error) {
+	matches := make([]hotelsDAO.Hotel, 0)
+	for _, hotel := range repository.docs {
+		if filter.City != "" && !strings.EqualFold(hotel.City, filter.City) ||
+			filter.State != "" && !strings.EqualFold(hotel.State, filter.State) ||
+			hotel.Rating < filter.MinRating || !hasAmenities(hotel, filter.Amenities) {
+			continue
+		}
+		matches = append(matches, hotel)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := sortKey(matches[i], filter.SortBy), sortKey(matches[j], filter.SortBy)
+		if a == b {
+			a, b = matches[i].ID.Hex(), matches[j].ID.Hex()
+		}
+		if filter.SortDesc {
+			return a > b
+		}
+		return a < b
+	})
+
+	total := int64(len(matches))
+	start := min(filter.Offset, len(matches))
+	end := min(start+filter.Limit, len(matches))
+	return matches[start:end], total, nil
+}
+
+func hasAmenities(hotel hotelsDAO.Hotel, amenities []string) bool {
+	for _, wanted := range amenities {
+		found := false
+		for _, amenity := range hotel.Amenities {
+			found = found || strings.EqualFold(amenity, wanted)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sortKey arma una clave comparable como texto; los números se rellenan para que ordenen bien.
+func sortKey(hotel hotelsDAO.Hotel, field string) string {
+	switch field {
+	case "city":
+		return strings.ToLower(hotel.City)
+	case "state":
+		return strings.ToLower(hotel.State)
+	case "rating":
+		return fmt.Sprintf("%08.2f", hotel.Rating)
+	case "review_count":
+		return fmt.Sprintf("%012d", hotel.ReviewCount)
+	default:
+		return strings.ToLower(hotel.Name)
+	}
+}
+
 func (repository Mock) Delete(ctx context.Context, id string) error {
 	if _, exists := repository.docs[id]; !exists {
 		return fmt.Errorf("hotel with ID %s not found", id)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	hotelsDAO "hotels-api/dao/hotels"
+	hotelsDomain "hotels-api/domain/hotels"
 	"log"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -53,6 +54,23 @@ func NewMongo(config MongoConfig) Mongo {
 	}
 }
 
+// listCollation compara ciudades, provincias y comodidades sin distinguir mayúsculas ni acentos,
+// así "cordoba" encuentra "Córdoba". Los índices del listado usan la misma para poder aprovecharse.
+var listCollation = &options.Collation{Locale: "es", Strength: 1}
+
+// EnsureIndexes crea los índices del listado de hoteles.
+func (repository Mongo) EnsureIndexes(ctx context.Context) error {
+	_, err := repository.client.Database(repository.database).Collection(repository.collection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "city", Value: 1}, {Key: "state", Value: 1}}, Options: options.Index().SetCollation(listCollation)},
+		{Keys: bson.D{{Key: "rating", Value: -1}}},
+		{Keys: bson.D{{Key: "amenities", Value: 1}}, Options: options.Index().SetCollation(listCollation)},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating hotel indexes: %w", err)
+	}
+	return nil
+}
+
 func (repository Mongo) GetHotelByID(ctx context.Context, id string) (hotelsDAO.Hotel, error) {
 	// Verifica que el ID sea de 24 caracteres para asegurar que es un ObjectID
 	if len(id) != 24 {
@@ -121,14 +139,47 @@ func (repository Mongo) Update(ctx context.Context, hotel hotelsDAO.Hotel) error
 	if len(hotel.Descripcion) > 0 { // Assuming empty slice is the default for Descripcion
 		update["descripcion"] = hotel.Descripcion
 	}
+	if hotel.Policy != nil {
+		update["cancellation_policy"] = hotel.Policy
+	}
+	if hotel.TimeZone != "" {
+		update["time_zone"] = hotel.TimeZone
+	}
+	if hotel.CheckInTime != "" {
+		update["check_in_time"] = hotel.CheckInTime
+	}
+	if hotel.BookingCutoff != "" {
+		update["booking_cutoff"] = hotel.BookingCutoff
+	}
+	if hotel.MaxStayNights > 0 {
+		update["max_stay_nights"] = hotel.MaxStayNights
+	}
+	if hotel.TaxID != "" {
+		update["tax_id"] = hotel.TaxID
+	}
+	if hotel.TaxPercent > 0 {
+		update["tax_percent"] = hotel.TaxPercent
+	}
+	// Los campos a borrar se quitan del documento y se leen con su valor por defecto
+	unset := bson.M{}
+	for _, field := range hotel.Clear {
+		unset[field] = ""
+	}
 
 	// Update the document in MongoDB
-	if len(update) == 0 {
+	if len(update) == 0 && len(unset) == 0 {
 		return fmt.Errorf("no fields to update for hotel ID %s", hotel.ID)
 	}
+	changes := bson.M{}
+	if len(update) > 0 {
+		changes["$set"] = update
+	}
+	if len(unset) > 0 {
+		changes["$unset"] = unset
+	}
 
 	filter := bson.M{"_id": objectID}
-	result, err := repository.client.Database(repository.database).Collection(repository.collection).UpdateOne(ctx, filter, bson.M{"$set": update})
+	result, err := repository.client.Database(repository.database).Collection(repository.collection).UpdateOne(ctx, filter, changes)
 	if err != nil {
 		return fmt.Errorf("error updating document: %w", err)
 	}
@@ -139,6 +190,67 @@ func (repository Mongo) Update(ctx context.Context, hotel hotelsDAO.Hotel) error
 	return nil
 }
 
+// SetRating guarda la calificación calculada con las reseñas. A diferencia de Update escribe también
+// el 0, que es la calificación de un hotel sin reseñas aprobadas.
+func (repository Mongo) SetRating(ctx context.Context, id string, rating float64, reviewCount int) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+	update := bson.M{"$set": bson.M{"rating": rating, "review_count": reviewCount}}
+	result, err := repository.client.Database(repository.database).Collection(repository.collection).UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return fmt.Errorf("error updating rating: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no document found with ID %s", id)
+	}
+	return nil
+}
+
+// List devuelve una página de los hoteles del filtro y el total de hoteles que lo cumplen.
+func (repository Mongo) List(ctx context.Context, filter hotelsDomain.ListFilter) ([]hotelsDAO.Hotel, int64, error) {
+	collection := repository.client.Database(repository.database).Collection(repository.collection)
+	query := bson.M{}
+	if filter.City != "" {
+		query["city"] = filter.City
+	}
+	if filter.State != "" {
+		query["state"] = filter.State
+	}
+	if filter.MinRating > 0 {
+		query["rating"] = bson.M{"$gte": filter.MinRating}
+	}
+	if len(filter.Amenities) > 0 {
+		query["amenities"] = bson.M{"$all": filter.Amenities}
+	}
+
+	total, err := collection.CountDocuments(ctx, query, options.Count().SetCollation(listCollation))
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting hotels: %w", err)
+	}
+
+	direction := 1
+	if filter.SortDesc {
+		direction = -1
+	}
+	opts := options.Find().
+		SetCollation(listCollation).
+		SetSort(bson.D{{Key: filter.SortBy, Value: direction}, {Key: "_id", Value: direction}}).
+		SetSkip(int64(filter.Offset)).
+		SetLimit(int64(filter.Limit))
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing hotels: %w", err)
+	}
+
+	result := make([]hotelsDAO.Hotel, 0)
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, 0, fmt.Errorf("error decoding hotels: %w", err)
+	}
+	return result, total, nil
+}
+
 func (repository Mongo) Delete(ctx context.Context, id string) error {
 	// Convert hotel ID to MongoDB ObjectID
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -0,0 +1,51 @@
+package idempotency
+
+import (
+	"context"
+	"hotels-api/domain/idempotency"
+	"sync"
+)
+
+type Mock struct {
+	mutex *sync.Mutex
+	docs  map[string]idempotency.Record
+}
+
+func NewMock() Mock {
+	return Mock{
+		mutex: &sync.Mutex{},
+		docs:  make(map[string]idempotency.Record),
+	}
+}
+
+func (repository Mock) Begin(ctx context.Context, record idempotency.Record) (*idempotency.Record, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if existing, exists := repository.docs[record.ID]; exists {
+		return &existing, nil
+	}
+	repository.docs[record.ID] = record
+	return nil, nil
+}
+
+func (repository Mock) Complete(ctx context.Context, id string, status int, contentType string, body []byte) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	record := repository.docs[id]
+	record.Completed = true
+	record.Status = status
+	record.ContentType = contentType
+	record.Body = body
+	repository.docs[id] = record
+	return nil
+}
+
+func (repository Mock) Release(ctx context.Context, id string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	delete(repository.docs, id)
+	return nil
+}
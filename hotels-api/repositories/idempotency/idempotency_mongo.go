@@ -0,0 +1,81 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/idempotency"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	client     *mongo.Client
+	database   string
+	collection string
+	ttl        time.Duration
+}
+
+func NewMongo(client *mongo.Client, database, collection string, ttl time.Duration) Mongo {
+	return Mongo{client: client, database: database, collection: collection, ttl: ttl}
+}
+
+// EnsureIndexes crea el índice TTL con el que Mongo borra solo las claves vencidas.
+func (m Mongo) EnsureIndexes(ctx context.Context) error {
+	_, err := m.client.Database(m.database).Collection(m.collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(m.ttl.Seconds())),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating idempotency indexes: %w", err)
+	}
+	return nil
+}
+
+// Begin reserva la clave insertando el registro en curso. Si la clave ya existía devuelve el
+// registro guardado; el _id único hace que solo una de dos requests simultáneas pueda tomarla.
+func (m Mongo) Begin(ctx context.Context, record idempotency.Record) (*idempotency.Record, error) {
+	collection := m.client.Database(m.database).Collection(m.collection)
+	_, err := collection.InsertOne(ctx, record)
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("error saving idempotency key: %w", err)
+	}
+
+	var existing idempotency.Record
+	if err := collection.FindOne(ctx, bson.M{"_id": record.ID}).Decode(&existing); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// Venció entre el insert y la lectura: se trata como una clave nueva
+			return m.Begin(ctx, record)
+		}
+		return nil, fmt.Errorf("error getting idempotency key: %w", err)
+	}
+	return &existing, nil
+}
+
+// Complete guarda la respuesta final de la request que tomó la clave.
+func (m Mongo) Complete(ctx context.Context, id string, status int, contentType string, body []byte) error {
+	update := bson.M{"$set": bson.M{
+		"completed":    true,
+		"status":       status,
+		"content_type": contentType,
+		"body":         body,
+	}}
+	if _, err := m.client.Database(m.database).Collection(m.collection).UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		return fmt.Errorf("error saving idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release borra la clave para que el cliente pueda reintentar una request que falló.
+func (m Mongo) Release(ctx context.Context, id string) error {
+	if _, err := m.client.Database(m.database).Collection(m.collection).DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("error releasing idempotency key: %w", err)
+	}
+	return nil
+}
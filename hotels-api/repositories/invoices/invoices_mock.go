@@ -0,0 +1,47 @@
+package invoices
+
+import (
+	"context"
+	"fmt"
+	"hotels-api/domain/invoices"
+	"sync"
+)
+
+type Mock struct {
+	mutex    *sync.Mutex
+	docs     map[string]invoices.Invoice
+	counters map[string]int64
+}
+
+func NewMock() Mock {
+	return Mock{
+		mutex:    &sync.Mutex{},
+		docs:     make(map[string]invoices.Invoice),
+		counters: make(map[string]int64),
+	}
+}
+
+func (repository Mock) GetByReservationID(ctx context.Context, reservationID string) (invoices.Invoice, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	invoice, exists := repository.docs[reservationID]
+	if !exists {
+		return invoices.Invoice{}, fmt.Errorf("reservation %s: %w", reservationID, invoices.ErrNotFound)
+	}
+	return invoice, nil
+}
+
+func (repository Mock) Issue(ctx context.Context, invoice invoices.Invoice) (invoices.Invoice, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if existing, exists := repository.docs[invoice.ReservationID]; exists {
+		return existing, nil
+	}
+	repository.counters[invoice.HotelID]++
+	invoice.Sequence = repository.counters[invoice.HotelID]
+	invoice.Number = invoices.FormatNumber(invoice.Sequence)
+	repository.docs[invoice.ReservationID] = invoice
+	return invoice, nil
+}
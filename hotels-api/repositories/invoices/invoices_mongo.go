@@ -0,0 +1,120 @@
+package invoices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/invoices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	client     *mongo.Client
+	database   string
+	collection string
+}
+
+// maxNumberingAttempts es cuántas veces se intenta numerar una factura cuando otras del mismo
+// hotel toman el número al mismo tiempo.
+const maxNumberingAttempts = 20
+
+func NewMongo(client *mongo.Client, database, collection string) Mongo {
+	return Mongo{client: client, database: database, collection: collection}
+}
+
+// EnsureIndexes garantiza que dos facturas del mismo hotel nunca tengan el mismo número. Los
+// borradores todavía sin numerar quedan fuera del índice.
+func (m Mongo) EnsureIndexes(ctx context.Context) error {
+	_, err := m.client.Database(m.database).Collection(m.collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "hotel_id", Value: 1}, {Key: "sequence", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"sequence": bson.M{"$gt": 0},
+		}),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating invoice indexes: %w", err)
+	}
+	return nil
+}
+
+// GetByReservationID devuelve la factura emitida para la reserva. Un borrador que todavía no
+// tiene número no cuenta como emitido.
+func (m Mongo) GetByReservationID(ctx context.Context, reservationID string) (invoices.Invoice, error) {
+	var invoice invoices.Invoice
+	err := m.client.Database(m.database).Collection(m.collection).FindOne(ctx, bson.M{"_id": reservationID, "sequence": bson.M{"$gt": 0}}).Decode(&invoice)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return invoices.Invoice{}, fmt.Errorf("reservation %s: %w", reservationID, invoices.ErrNotFound)
+	}
+	if err != nil {
+		return invoices.Invoice{}, fmt.Errorf("error getting invoice: %w", err)
+	}
+	return invoice, nil
+}
+
+// Issue emite la factura: primero guarda el borrador con la reserva como _id, así dos pedidos
+// simultáneos terminan en el mismo documento, y después le asigna el número siguiente al último
+// emitido del hotel. El número se toma en la misma escritura que lo guarda en la factura, así que
+// nunca queda uno sin usar: si otra factura lo tomó antes, el índice único lo rechaza y se prueba
+// con el siguiente.
+func (m Mongo) Issue(ctx context.Context, invoice invoices.Invoice) (invoices.Invoice, error) {
+	collection := m.client.Database(m.database).Collection(m.collection)
+	invoice.Sequence, invoice.Number = 0, ""
+	if _, err := collection.InsertOne(ctx, invoice); err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			return invoices.Invoice{}, fmt.Errorf("error saving invoice: %w", err)
+		}
+		// Ya hay una factura o un borrador que quedó sin numerar: se respeta lo guardado
+		if err := collection.FindOne(ctx, bson.M{"_id": invoice.ReservationID}).Decode(&invoice); err != nil {
+			return invoices.Invoice{}, fmt.Errorf("error getting invoice: %w", err)
+		}
+		if invoice.Sequence > 0 {
+			return invoice, nil
+		}
+	}
+
+	for attempt := 0; attempt < maxNumberingAttempts; attempt++ {
+		sequence, err := m.lastSequence(ctx, invoice.HotelID)
+		if err != nil {
+			return invoices.Invoice{}, err
+		}
+		sequence++
+		number := invoices.FormatNumber(sequence)
+		result, err := collection.UpdateOne(ctx,
+			bson.M{"_id": invoice.ReservationID, "sequence": 0},
+			bson.M{"$set": bson.M{"sequence": sequence, "number": number}},
+		)
+		if mongo.IsDuplicateKeyError(err) {
+			// Otra factura del hotel tomó el número: se vuelve a leer el último
+			continue
+		}
+		if err != nil {
+			return invoices.Invoice{}, fmt.Errorf("error numbering invoice: %w", err)
+		}
+		if result.MatchedCount == 0 {
+			// Otro pedido numeró este mismo borrador
+			return m.GetByReservationID(ctx, invoice.ReservationID)
+		}
+		invoice.Sequence, invoice.Number = sequence, number
+		return invoice, nil
+	}
+	return invoices.Invoice{}, fmt.Errorf("reservation %s after %d attempts: %w", invoice.ReservationID, maxNumberingAttempts, invoices.ErrNumberingFailed)
+}
+
+// lastSequence devuelve el número de la última factura emitida por el hotel, o 0 si no emitió ninguna.
+func (m Mongo) lastSequence(ctx context.Context, hotelID string) (int64, error) {
+	var last invoices.Invoice
+	err := m.client.Database(m.database).Collection(m.collection).FindOne(ctx,
+		bson.M{"hotel_id": hotelID, "sequence": bson.M{"$gt": 0}},
+		options.FindOne().SetSort(bson.D{{Key: "sequence", Value: -1}}),
+	).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error getting last invoice number: %w", err)
+	}
+	return last.Sequence, nil
+}
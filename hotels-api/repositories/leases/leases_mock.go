@@ -0,0 +1,36 @@
+package leases
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type lease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+type Mock struct {
+	mutex  *sync.Mutex
+	leases map[string]lease
+}
+
+func NewMock() Mock {
+	return Mock{
+		mutex:  &sync.Mutex{},
+		leases: make(map[string]lease),
+	}
+}
+
+func (repository Mock) Acquire(ctx context.Context, name, owner string, now time.Time, duration time.Duration) (bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	current, exists := repository.leases[name]
+	if exists && current.owner != owner && current.expiresAt.After(now) {
+		return false, nil
+	}
+	repository.leases[name] = lease{owner: owner, expiresAt: now.Add(duration)}
+	return true, nil
+}
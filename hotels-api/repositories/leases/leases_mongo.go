@@ -0,0 +1,47 @@
+package leases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	client     *mongo.Client
+	database   string
+	collection string
+}
+
+func NewMongo(client *mongo.Client, database, collection string) Mongo {
+	return Mongo{client: client, database: database, collection: collection}
+}
+
+// Acquire toma o renueva el lease name para owner hasta now+duration. Solo lo consigue si está
+// libre, vencido o ya era suyo: si otra instancia lo tiene el filtro no coincide y el upsert choca
+// con el _id existente, así que de dos instancias simultáneas solo una puede tomarlo.
+func (m Mongo) Acquire(ctx context.Context, name, owner string, now time.Time, duration time.Duration) (bool, error) {
+	filter := bson.M{
+		"_id": name,
+		"$or": []bson.M{
+			{"expires_at": bson.M{"$lte": now}},
+			{"owner": owner},
+		},
+	}
+	update := bson.M{"$set": bson.M{
+		"owner":       owner,
+		"acquired_at": now,
+		"expires_at":  now.Add(duration),
+	}}
+	_, err := m.client.Database(m.database).Collection(m.collection).UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error acquiring lease %s: %w", name, err)
+	}
+	return true, nil
+}
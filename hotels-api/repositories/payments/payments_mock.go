@@ -0,0 +1,56 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"hotels-api/domain/payments"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Mock struct {
+	mutex *sync.Mutex
+	docs  map[string]payments.Payment
+}
+
+func NewMock() Mock {
+	return Mock{
+		mutex: &sync.Mutex{},
+		docs:  make(map[string]payments.Payment),
+	}
+}
+
+func (repository Mock) Create(ctx context.Context, payment payments.Payment) (string, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, exists := repository.docs[payment.ReservationID]; exists {
+		return "", fmt.Errorf("reservation %s already has a payment", payment.ReservationID)
+	}
+	payment.ID = primitive.NewObjectID().Hex()
+	repository.docs[payment.ReservationID] = payment
+	return payment.ID, nil
+}
+
+func (repository Mock) GetByReservationID(ctx context.Context, reservationID string) (payments.Payment, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	payment, exists := repository.docs[reservationID]
+	if !exists {
+		return payments.Payment{}, fmt.Errorf("reservation %s: %w", reservationID, payments.ErrNotFound)
+	}
+	return payment, nil
+}
+
+func (repository Mock) Update(ctx context.Context, payment payments.Payment) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if current, exists := repository.docs[payment.ReservationID]; !exists || current.ID != payment.ID {
+		return fmt.Errorf("payment %s: %w", payment.ID, payments.ErrNotFound)
+	}
+	repository.docs[payment.ReservationID] = payment
+	return nil
+}
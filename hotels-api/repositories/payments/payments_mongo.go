@@ -0,0 +1,79 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/payments"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	client     *mongo.Client
+	database   string
+	collection string
+}
+
+func NewMongo(client *mongo.Client, database, collection string) Mongo {
+	return Mongo{client: client, database: database, collection: collection}
+}
+
+// EnsureIndexes garantiza un único pago por reserva.
+func (m Mongo) EnsureIndexes(ctx context.Context) error {
+	_, err := m.client.Database(m.database).Collection(m.collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "reservation_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating payment indexes: %w", err)
+	}
+	return nil
+}
+
+func (m Mongo) Create(ctx context.Context, payment payments.Payment) (string, error) {
+	result, err := m.client.Database(m.database).Collection(m.collection).InsertOne(ctx, payment)
+	if err != nil {
+		return "", fmt.Errorf("error creating payment: %w", err)
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (m Mongo) GetByReservationID(ctx context.Context, reservationID string) (payments.Payment, error) {
+	var payment payments.Payment
+	err := m.client.Database(m.database).Collection(m.collection).FindOne(ctx, bson.M{"reservation_id": reservationID}).Decode(&payment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return payments.Payment{}, fmt.Errorf("reservation %s: %w", reservationID, payments.ErrNotFound)
+	}
+	if err != nil {
+		return payments.Payment{}, fmt.Errorf("error getting payment: %w", err)
+	}
+	return payment, nil
+}
+
+// Update guarda el estado del pago después de una operación con la pasarela.
+func (m Mongo) Update(ctx context.Context, payment payments.Payment) error {
+	objectID, err := primitive.ObjectIDFromHex(payment.ID)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q: %w", payment.ID, payments.ErrNotFound)
+	}
+	update := bson.M{"$set": bson.M{
+		"status":       payment.Status,
+		"authorized":   payment.Authorized,
+		"captured":     payment.Captured,
+		"refunded":     payment.Refunded,
+		"transactions": payment.Transactions,
+		"updated_at":   payment.UpdatedAt,
+	}}
+	result, err := m.client.Database(m.database).Collection(m.collection).UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return fmt.Errorf("error updating payment: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("payment %s: %w", payment.ID, payments.ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package promotions
+
+import (
+	"context"
+	"fmt"
+	"hotels-api/domain/promotions"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Mock struct {
+	mutex       *sync.Mutex
+	docs        map[string]promotions.Promotion
+	redemptions map[string]int
+}
+
+func NewMock() Mock {
+	return Mock{
+		mutex:       &sync.Mutex{},
+		docs:        make(map[string]promotions.Promotion),
+		redemptions: make(map[string]int),
+	}
+}
+
+func (repository Mock) Create(ctx context.Context, promotion promotions.Promotion) (string, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, existing := range repository.docs {
+		if existing.Code == promotion.Code {
+			return "", fmt.Errorf("code %q already exists: %w", promotion.Code, promotions.ErrInvalidPromotion)
+		}
+	}
+	promotion.ID = primitive.NewObjectID().Hex()
+	repository.docs[promotion.ID] = promotion
+	return promotion.ID, nil
+}
+
+func (repository Mock) GetByID(ctx context.Context, id string) (promotions.Promotion, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	promotion, exists := repository.docs[id]
+	if !exists {
+		return promotions.Promotion{}, fmt.Errorf("promo code %s: %w", id, promotions.ErrNotFound)
+	}
+	return promotion, nil
+}
+
+func (repository Mock) GetByCode(ctx context.Context, code string) (promotions.Promotion, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, promotion := range repository.docs {
+		if promotion.Code == code {
+			return promotion, nil
+		}
+	}
+	return promotions.Promotion{}, fmt.Errorf("promo code %s: %w", code, promotions.ErrNotFound)
+}
+
+func (repository Mock) GetAll(ctx context.Context) ([]promotions.Promotion, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	result := make([]promotions.Promotion, 0, len(repository.docs))
+	for _, promotion := range repository.docs {
+		result = append(result, promotion)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ValidFrom.After(result[j].ValidFrom) })
+	return result, nil
+}
+
+func (repository Mock) Redeem(ctx context.Context, promotion promotions.Promotion, userID string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	current, exists := repository.docs[promotion.ID]
+	if !exists {
+		return fmt.Errorf("promo code %s: %w", promotion.ID, promotions.ErrNotFound)
+	}
+	key := redemptionKey(promotion.ID, userID)
+	if promotion.MaxPerUser > 0 && repository.redemptions[key] >= promotion.MaxPerUser {
+		return fmt.Errorf("code %s can be used %d times per guest: %w", promotion.Code, promotion.MaxPerUser, promotions.ErrExhausted)
+	}
+	if promotion.MaxRedemptions > 0 && current.Redemptions >= promotion.MaxRedemptions {
+		return fmt.Errorf("code %s: %w", promotion.Code, promotions.ErrExhausted)
+	}
+	current.Redemptions++
+	repository.docs[promotion.ID] = current
+	if promotion.MaxPerUser > 0 {
+		repository.redemptions[key]++
+	}
+	return nil
+}
+
+func (repository Mock) Unredeem(ctx context.Context, promotion promotions.Promotion, userID string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if current, exists := repository.docs[promotion.ID]; exists && current.Redemptions > 0 {
+		current.Redemptions--
+		repository.docs[promotion.ID] = current
+	}
+	if key := redemptionKey(promotion.ID, userID); promotion.MaxPerUser > 0 && repository.redemptions[key] > 0 {
+		repository.redemptions[key]--
+	}
+	return nil
+}
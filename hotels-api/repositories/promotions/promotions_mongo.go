@@ -0,0 +1,165 @@
+package promotions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/promotions"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	client                *mongo.Client
+	database              string
+	collection            string
+	redemptionsCollection string
+}
+
+func NewMongo(client *mongo.Client, database, collection, redemptionsCollection string) Mongo {
+	return Mongo{
+		client:                client,
+		database:              database,
+		collection:            collection,
+		redemptionsCollection: redemptionsCollection,
+	}
+}
+
+// EnsureIndexes hace único cada código.
+func (m Mongo) EnsureIndexes(ctx context.Context) error {
+	_, err := m.promotions().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating promotion indexes: %w", err)
+	}
+	return nil
+}
+
+func (m Mongo) promotions() *mongo.Collection {
+	return m.client.Database(m.database).Collection(m.collection)
+}
+
+func (m Mongo) redemptions() *mongo.Collection {
+	return m.client.Database(m.database).Collection(m.redemptionsCollection)
+}
+
+func (m Mongo) Create(ctx context.Context, promotion promotions.Promotion) (string, error) {
+	result, err := m.promotions().InsertOne(ctx, promotion)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", fmt.Errorf("code %q already exists: %w", promotion.Code, promotions.ErrInvalidPromotion)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error creating promotion: %w", err)
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (m Mongo) GetByID(ctx context.Context, id string) (promotions.Promotion, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return promotions.Promotion{}, fmt.Errorf("invalid ID %q: %w", id, promotions.ErrNotFound)
+	}
+	return m.findOne(ctx, bson.M{"_id": objectID}, id)
+}
+
+func (m Mongo) GetByCode(ctx context.Context, code string) (promotions.Promotion, error) {
+	return m.findOne(ctx, bson.M{"code": code}, code)
+}
+
+func (m Mongo) findOne(ctx context.Context, query bson.M, reference string) (promotions.Promotion, error) {
+	var promotion promotions.Promotion
+	err := m.promotions().FindOne(ctx, query).Decode(&promotion)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return promotions.Promotion{}, fmt.Errorf("promo code %s: %w", reference, promotions.ErrNotFound)
+	}
+	if err != nil {
+		return promotions.Promotion{}, fmt.Errorf("error getting promotion: %w", err)
+	}
+	return promotion, nil
+}
+
+func (m Mongo) GetAll(ctx context.Context) ([]promotions.Promotion, error) {
+	cursor, err := m.promotions().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "valid_from", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("error getting promotions: %w", err)
+	}
+	result := make([]promotions.Promotion, 0)
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("error decoding promotions: %w", err)
+	}
+	return result, nil
+}
+
+// Redeem cuenta un uso del código por userID. Los dos límites se verifican en la misma
+// escritura que incrementa cada contador, así que dos reservas simultáneas no pueden pasarse del
+// límite: el contador del usuario es un documento por usuario que solo se incrementa por debajo de
+// MaxPerUser (si está lleno el upsert choca con el _id existente) y el del código solo por debajo
+// de MaxRedemptions.
+func (m Mongo) Redeem(ctx context.Context, promotion promotions.Promotion, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(promotion.ID)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q: %w", promotion.ID, promotions.ErrNotFound)
+	}
+	if promotion.MaxPerUser > 0 {
+		filter := bson.M{"_id": redemptionKey(promotion.ID, userID), "count": bson.M{"$lt": promotion.MaxPerUser}}
+		update := bson.M{
+			"$inc":         bson.M{"count": 1},
+			"$setOnInsert": bson.M{"promotion_id": promotion.ID, "user_id": userID},
+		}
+		_, err := m.redemptions().UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("code %s can be used %d times per guest: %w", promotion.Code, promotion.MaxPerUser, promotions.ErrExhausted)
+		}
+		if err != nil {
+			return fmt.Errorf("error counting redemption: %w", err)
+		}
+	}
+
+	filter := bson.M{"_id": objectID}
+	if promotion.MaxRedemptions > 0 {
+		filter["redemptions"] = bson.M{"$lt": promotion.MaxRedemptions}
+	}
+	result, err := m.promotions().UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"redemptions": 1}})
+	if err == nil && result.MatchedCount == 0 {
+		err = fmt.Errorf("code %s: %w", promotion.Code, promotions.ErrExhausted)
+	} else if err != nil {
+		err = fmt.Errorf("error counting redemption: %w", err)
+	}
+	if err != nil && promotion.MaxPerUser > 0 {
+		m.returnUserRedemption(ctx, promotion.ID, userID)
+	}
+	return err
+}
+
+// Unredeem devuelve el uso de una reserva que se canceló.
+func (m Mongo) Unredeem(ctx context.Context, promotion promotions.Promotion, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(promotion.ID)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q: %w", promotion.ID, promotions.ErrNotFound)
+	}
+	filter := bson.M{"_id": objectID, "redemptions": bson.M{"$gt": 0}}
+	if _, err := m.promotions().UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"redemptions": -1}}); err != nil {
+		return fmt.Errorf("error returning redemption: %w", err)
+	}
+	if promotion.MaxPerUser > 0 {
+		return m.returnUserRedemption(ctx, promotion.ID, userID)
+	}
+	return nil
+}
+
+func (m Mongo) returnUserRedemption(ctx context.Context, promotionID, userID string) error {
+	filter := bson.M{"_id": redemptionKey(promotionID, userID), "count": bson.M{"$gt": 0}}
+	if _, err := m.redemptions().UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"count": -1}}); err != nil {
+		return fmt.Errorf("error returning redemption: %w", err)
+	}
+	return nil
+}
+
+func redemptionKey(promotionID, userID string) string {
+	return promotionID + ":" + userID
+}
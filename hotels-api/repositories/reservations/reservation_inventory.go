@@ -0,0 +1,332 @@
+package reservations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/reservations"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// claim es la ocupación de units habitaciones de un tipo durante una noche. Con allotmentID las
+// habitaciones se toman del cupo, que tiene su propio contador: el cupo ya las tiene apartadas
+// en el inventario general del tipo.
+type claim struct {
+	roomTypeID  string
+	allotmentID string
+	night       reservations.Date
+	units       int
+}
+
+func (c claim) key() string {
+	if c.allotmentID != "" {
+		return fmt.Sprintf("allotment:%s:%s", c.allotmentID, c.night)
+	}
+	return fmt.Sprintf("%s:%s", c.roomTypeID, c.night)
+}
+
+// capacityKey es la clave del límite del contador: el tipo de habitación o el cupo.
+func (c claim) capacityKey() string {
+	if c.allotmentID != "" {
+		return c.allotmentID
+	}
+	return c.roomTypeID
+}
+
+// general devuelve el claim equivalente sobre el inventario general del tipo de habitación.
+func (c claim) general() claim {
+	return claim{roomTypeID: c.roomTypeID, night: c.night, units: c.units}
+}
+
+// claimsOf devuelve las noches que ocupa una reserva activa, sumando las habitaciones de todas
+// sus líneas. Dos líneas del mismo tipo de habitación se juntan en un único claim por noche. Las
+// reservas de un cupo ocupan el contador del cupo.
+func claimsOf(reservation reservations.Reservation) []claim {
+	claims := make([]claim, 0)
+	for _, item := range reservation.LineItems() {
+		for night := reservation.StartDate; night.Before(reservation.EndDate.Time); night = night.AddDays(1) {
+			claims = append(claims, claim{roomTypeID: item.RoomTypeID, allotmentID: reservation.AllotmentID, night: night, units: item.Quantity})
+		}
+	}
+	merged, _ := diffClaims(nil, claims)
+	return merged
+}
+
+// rangeClaims devuelve las noches de [from, to) de units habitaciones de un tipo.
+func rangeClaims(roomTypeID string, from, to reservations.Date, units int) []claim {
+	claims := make([]claim, 0)
+	for night := from; night.Before(to.Time); night = night.AddDays(1) {
+		claims = append(claims, claim{roomTypeID: roomTypeID, night: night, units: units})
+	}
+	return claims
+}
+
+// diffClaims compara la ocupación anterior y la nueva de una reserva y devuelve lo que hay que
+// tomar y lo que hay que liberar, para no soltar nunca noches que la reserva sigue usando.
+func diffClaims(previous, next []claim) (acquire []claim, free []claim) {
+	deltas := make(map[string]claim)
+	keys := make([]string, 0)
+	add := func(c claim, units int) {
+		delta, ok := deltas[c.key()]
+		if !ok {
+			delta = claim{roomTypeID: c.roomTypeID, allotmentID: c.allotmentID, night: c.night}
+			keys = append(keys, c.key())
+		}
+		delta.units += units
+		deltas[c.key()] = delta
+	}
+	for _, c := range previous {
+		add(c, -c.units)
+	}
+	for _, c := range next {
+		add(c, c.units)
+	}
+
+	for _, key := range keys {
+		delta := deltas[key]
+		switch {
+		case delta.units > 0:
+			acquire = append(acquire, delta)
+		case delta.units < 0:
+			delta.units = -delta.units
+			free = append(free, delta)
+		}
+	}
+	return acquire, free
+}
+
+func (m Mongo) inventory() *mongo.Collection {
+	return m.client.Database(m.database).Collection(m.inventoryCollection)
+}
+
+// BackfillInventory arma los contadores por noche a partir de las reservas activas, para las bases
+// con reservas anteriores a los contadores. Solo actúa con el inventario vacío, porque desde que
+// existen los contadores los mantienen Book, Rebook y los cambios de estado, así que se puede
+// correr en cada arranque. Tiene que correr después de MigrateDates y antes de aceptar reservas.
+// Los bloqueos de canales y los cupos nacieron con los contadores, por eso no se reconstruyen.
+func (m Mongo) BackfillInventory(ctx context.Context) error {
+	existing, err := m.inventory().CountDocuments(ctx, bson.M{}, options.Count().SetLimit(1))
+	if err != nil {
+		return fmt.Errorf("error checking inventory: %w", err)
+	}
+	if existing > 0 {
+		return nil
+	}
+
+	cursor, err := m.client.Database(m.database).Collection(m.collection).Find(ctx, bson.M{"status": bson.M{"$nin": reservations.InactiveStatuses}})
+	if err != nil {
+		return fmt.Errorf("error getting active reservations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	claims := make([]claim, 0)
+	for cursor.Next(ctx) {
+		var reservation reservations.Reservation
+		if err := cursor.Decode(&reservation); err != nil {
+			return fmt.Errorf("error decoding reservation: %w", err)
+		}
+		claims = append(claims, claimsOf(reservation)...)
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("error reading active reservations: %w", err)
+	}
+
+	// diffClaims junta las noches de todas las reservas en un contador por clave
+	counters, _ := diffClaims(nil, claims)
+	for _, c := range counters {
+		counter := bson.M{"_id": c.key(), "room_type_id": c.roomTypeID, "night": c.night, "booked": c.units}
+		if c.allotmentID != "" {
+			counter = bson.M{"_id": c.key(), "allotment_id": c.allotmentID, "night": c.night, "booked": c.units}
+		}
+		// Otra instancia que arranca a la vez calcula los mismos contadores y el primero que llega queda
+		if _, err := m.inventory().InsertOne(ctx, counter); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("error backfilling inventory for %s: %w", c.key(), err)
+		}
+	}
+	return nil
+}
+
+// GetBooked devuelve las habitaciones tomadas de cada tipo por noche en [from, to), indexadas
+// por tipo de habitación y fecha. Las noches sin reservas no aparecen.
+func (m Mongo) GetBooked(ctx context.Context, roomTypeIDs []string, from, to reservations.Date) (map[string]map[string]int, error) {
+	query := bson.M{
+		"room_type_id": bson.M{"$in": roomTypeIDs},
+		"night":        bson.M{"$gte": from, "$lt": to},
+	}
+	cursor, err := m.inventory().Find(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting inventory: %w", err)
+	}
+
+	var counters []struct {
+		RoomTypeID string            `bson:"room_type_id"`
+		Night      reservations.Date `bson:"night"`
+		Booked     int               `bson:"booked"`
+	}
+	if err := cursor.All(ctx, &counters); err != nil {
+		return nil, fmt.Errorf("error decoding inventory: %w", err)
+	}
+
+	booked := make(map[string]map[string]int)
+	for _, counter := range counters {
+		if booked[counter.RoomTypeID] == nil {
+			booked[counter.RoomTypeID] = make(map[string]int)
+		}
+		booked[counter.RoomTypeID][counter.Night.String()] = counter.Booked
+	}
+	return booked, nil
+}
+
+// Hold toma units habitaciones del tipo en [from, to) sin crear una reserva, para los bloqueos de
+// otros canales. Si alguna noche no tiene lugar no toma ninguna.
+func (m Mongo) Hold(ctx context.Context, roomTypeID string, from, to reservations.Date, units int, capacity int) error {
+	return m.acquire(ctx, rangeClaims(roomTypeID, from, to, units), map[string]int{roomTypeID: capacity})
+}
+
+// Unhold devuelve las noches tomadas con Hold.
+func (m Mongo) Unhold(ctx context.Context, roomTypeID string, from, to reservations.Date, units int) error {
+	return m.free(ctx, rangeClaims(roomTypeID, from, to, units))
+}
+
+// acquire incrementa los contadores de cada noche solo si queda lugar según capacity. El filtro
+// sobre booked y el upsert hacen que cada incremento sea atómico: si la noche ya está llena el
+// upsert choca con el _id existente. Ante cualquier falla se devuelven las noches ya tomadas.
+// Los contadores de un cupo liberado están cerrados y no aceptan más reservas.
+func (m Mongo) acquire(ctx context.Context, claims []claim, capacity map[string]int) error {
+	for i, c := range claims {
+		limit := capacity[c.capacityKey()] - c.units
+		if limit < 0 {
+			m.free(ctx, claims[:i])
+			return fmt.Errorf("room type %s has no rooms on %s: %w", c.roomTypeID, c.night, reservations.ErrNoAvailability)
+		}
+		filter := bson.M{"_id": c.key(), "booked": bson.M{"$lte": limit}}
+		onInsert := bson.M{"room_type_id": c.roomTypeID, "night": c.night}
+		if c.allotmentID != "" {
+			// Sin room_type_id para que el cupo no cuente dos veces en la disponibilidad del tipo
+			filter["sealed"] = bson.M{"$ne": true}
+			onInsert = bson.M{"allotment_id": c.allotmentID, "night": c.night}
+		}
+		update := bson.M{
+			"$inc":         bson.M{"booked": c.units},
+			"$setOnInsert": onInsert,
+		}
+		_, err := m.inventory().UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+		if mongo.IsDuplicateKeyError(err) {
+			m.free(ctx, claims[:i])
+			return fmt.Errorf("room type %s is full on %s: %w", c.roomTypeID, c.night, reservations.ErrNoAvailability)
+		}
+		if err != nil {
+			m.free(ctx, claims[:i])
+			return fmt.Errorf("error reserving inventory: %w", err)
+		}
+	}
+	return nil
+}
+
+// free descuenta las noches de los contadores. Si el cupo ya se liberó, la habitación que deja una
+// reserva del cupo vuelve directamente al inventario general del tipo.
+func (m Mongo) free(ctx context.Context, claims []claim) error {
+	for _, c := range claims {
+		var counter blockCounter
+		err := m.inventory().FindOneAndUpdate(ctx, bson.M{"_id": c.key()}, bson.M{"$inc": bson.M{"booked": -c.units}}).Decode(&counter)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return fmt.Errorf("error releasing inventory for %s: %w", c.key(), err)
+		}
+		if c.allotmentID != "" && counter.Sealed {
+			if err := m.free(ctx, []claim{c.general()}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// blockCounter es el contador de una noche de un cupo. Sealed indica que el cupo ya se liberó.
+type blockCounter struct {
+	Booked int  `bson:"booked"`
+	Sealed bool `bson:"sealed"`
+}
+
+// ReleaseAllotment cierra el cupo noche por noche y devuelve al inventario general del tipo las
+// habitaciones que no se usaron. Las noches ya cerradas se saltean, así que repetirla no libera
+// dos veces. Devuelve cuántas noches-habitación se liberaron.
+func (m Mongo) ReleaseAllotment(ctx context.Context, allotmentID, roomTypeID string, from, to reservations.Date, rooms int) (int, error) {
+	released := 0
+	for night := from; night.Before(to.Time); night = night.AddDays(1) {
+		c := claim{roomTypeID: roomTypeID, allotmentID: allotmentID, night: night}
+		unused, err := m.seal(ctx, c, rooms)
+		if err != nil {
+			return released, err
+		}
+		if unused > 0 {
+			c.units = unused
+			if err := m.free(ctx, []claim{c.general()}); err != nil {
+				return released, err
+			}
+			released += unused
+		}
+	}
+	return released, nil
+}
+
+// seal cierra el contador de una noche del cupo y devuelve cuántas de sus habitaciones quedaron
+// sin usar. El cierre se condiciona al valor leído para no perder una reserva que entre a la vez.
+func (m Mongo) seal(ctx context.Context, c claim, rooms int) (int, error) {
+	for {
+		var counter blockCounter
+		err := m.inventory().FindOne(ctx, bson.M{"_id": c.key()}).Decode(&counter)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			_, err := m.inventory().InsertOne(ctx, bson.M{"_id": c.key(), "allotment_id": c.allotmentID, "night": c.night, "booked": 0, "sealed": true})
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+			if err != nil {
+				return 0, fmt.Errorf("error closing allotment night %s: %w", c.key(), err)
+			}
+			return rooms, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error getting allotment night %s: %w", c.key(), err)
+		}
+		if counter.Sealed {
+			return 0, nil
+		}
+
+		filter := bson.M{"_id": c.key(), "booked": counter.Booked, "sealed": bson.M{"$ne": true}}
+		result, err := m.inventory().UpdateOne(ctx, filter, bson.M{"$set": bson.M{"sealed": true}})
+		if err != nil {
+			return 0, fmt.Errorf("error closing allotment night %s: %w", c.key(), err)
+		}
+		if result.ModifiedCount == 1 {
+			return max(rooms-counter.Booked, 0), nil
+		}
+	}
+}
+
+// GetPickup devuelve cuántas habitaciones del cupo se usan cada noche, indexadas por fecha.
+func (m Mongo) GetPickup(ctx context.Context, allotmentID string, from, to reservations.Date) (map[string]int, error) {
+	query := bson.M{
+		"allotment_id": allotmentID,
+		"night":        bson.M{"$gte": from, "$lt": to},
+	}
+	cursor, err := m.inventory().Find(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting allotment pickup: %w", err)
+	}
+	var counters []struct {
+		Night  reservations.Date `bson:"night"`
+		Booked int               `bson:"booked"`
+	}
+	if err := cursor.All(ctx, &counters); err != nil {
+		return nil, fmt.Errorf("error decoding allotment pickup: %w", err)
+	}
+
+	pickup := make(map[string]int)
+	for _, counter := range counters {
+		pickup[counter.Night.String()] = counter.Booked
+	}
+	return pickup, nil
+}
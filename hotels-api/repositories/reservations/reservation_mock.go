@@ -0,0 +1,303 @@
+package reservations
+
+import (
+	"context"
+	"fmt"
+	"hotels-api/domain/reservations"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mock es una implementación en memoria del repositorio de reservas, con las mismas garantías
+// de inventario que Mongo, pensada para tests.
+type Mock struct {
+	mutex     *sync.Mutex
+	docs      map[string]reservations.Reservation
+	inventory map[string]int
+	sealed    map[string]bool
+}
+
+func NewMock() Mock {
+	return Mock{
+		mutex:     &sync.Mutex{},
+		docs:      make(map[string]reservations.Reservation),
+		inventory: make(map[string]int),
+		sealed:    make(map[string]bool),
+	}
+}
+
+func (repository Mock) Book(ctx context.Context, reservation reservations.Reservation, capacity map[string]int) (string, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if reservation.ID != "" {
+		// Mongo guardaría el _id recibido en lugar de generarlo
+		return "", fmt.Errorf("unexpected reservation ID %q", reservation.ID)
+	}
+	if err := repository.acquire(claimsOf(reservation), capacity); err != nil {
+		return "", err
+	}
+	reservation.ID = primitive.NewObjectID().Hex()
+	repository.docs[reservation.ID] = reservation
+	return reservation.ID, nil
+}
+
+func (repository Mock) Rebook(ctx context.Context, reservation reservations.Reservation, previous reservations.Reservation, capacity map[string]int) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	current, exists := repository.docs[reservation.ID]
+	if !exists {
+		return fmt.Errorf("reservation %s: %w", reservation.ID, reservations.ErrNotFound)
+	}
+	if current.Version != previous.Version || !contains(reservations.ModifiableStatuses, current.Status) {
+		return fmt.Errorf("reservation %s: %w", reservation.ID, reservations.ErrConcurrentUpdate)
+	}
+
+	acquire, free := diffClaims(claimsOf(previous), claimsOf(reservation))
+	if err := repository.acquire(acquire, capacity); err != nil {
+		return err
+	}
+	repository.free(free)
+	// Igual que el $set de Mongo: solo cambian los campos que guarda una modificación
+	current.RoomTypeID = reservation.RoomTypeID
+	current.StartDate, current.EndDate = reservation.StartDate, reservation.EndDate
+	current.Guests, current.Items = reservation.Guests, reservation.Items
+	current.Currency, current.Nights = reservation.Currency, reservation.Nights
+	current.Discount, current.Total = reservation.Discount, reservation.Total
+	current.PromotionID, current.PromoDiscount = reservation.PromotionID, reservation.PromoDiscount
+	current.PointsDiscount = reservation.PointsDiscount
+	current.Modifications = reservation.Modifications
+	current.Version = previous.Version + 1
+	repository.docs[reservation.ID] = current
+	return nil
+}
+
+func (repository Mock) GetByID(ctx context.Context, id string) (reservations.Reservation, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	reservation, exists := repository.docs[id]
+	if !exists {
+		return reservations.Reservation{}, fmt.Errorf("reservation %s: %w", id, reservations.ErrNotFound)
+	}
+	return reservation, nil
+}
+
+func (repository Mock) GetByUserID(ctx context.Context, userID string) ([]reservations.Reservation, error) {
+	return repository.find(reservations.SearchFilter{UserID: userID}), nil
+}
+
+func (repository Mock) GetExpired(ctx context.Context, now time.Time, createdBefore time.Time, limit int) ([]reservations.Reservation, error) {
+	pending := repository.find(reservations.SearchFilter{Statuses: []string{reservations.StatusPending}})
+	sort.Slice(pending, func(i, j int) bool { return pending[i].CreatedAt.Before(pending[j].CreatedAt) })
+	result := make([]reservations.Reservation, 0)
+	for _, reservation := range pending {
+		if len(result) == limit {
+			break
+		}
+		if reservation.ExpiresAt != nil && reservation.ExpiresAt.Before(now) || reservation.ExpiresAt == nil && reservation.CreatedAt.Before(createdBefore) {
+			result = append(result, reservation)
+		}
+	}
+	return result, nil
+}
+
+func (repository Mock) UpdateStatus(ctx context.Context, id string, from, to string, at time.Time) error {
+	return repository.changeStatus(id, from, to, func(reservation *reservations.Reservation) {
+		reservation.History = append(reservation.History, reservations.StatusChange{From: from, To: to, At: at})
+	})
+}
+
+func (repository Mock) Cancel(ctx context.Context, id string, from string, cancellation reservations.Cancellation) error {
+	return repository.changeStatus(id, from, reservations.StatusCancelled, func(reservation *reservations.Reservation) {
+		reservation.Cancellation = &cancellation
+		reservation.History = append(reservation.History, reservations.StatusChange{From: from, To: reservations.StatusCancelled, At: cancellation.CancelledAt})
+	})
+}
+
+func (repository Mock) Search(ctx context.Context, filter reservations.SearchFilter) ([]reservations.Reservation, int64, error) {
+	results := repository.find(filter)
+	total := int64(len(results))
+	if filter.Offset >= len(results) {
+		return []reservations.Reservation{}, total, nil
+	}
+	results = results[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(results) {
+		results = results[:filter.Limit]
+	}
+	return results, total, nil
+}
+
+func (repository Mock) Iterate(ctx context.Context, filter reservations.SearchFilter, fn func(reservations.Reservation) error) error {
+	for _, reservation := range repository.find(filter) {
+		if err := fn(reservation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repository Mock) GetBooked(ctx context.Context, roomTypeIDs []string, from, to reservations.Date) (map[string]map[string]int, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	booked := make(map[string]map[string]int)
+	for _, roomTypeID := range roomTypeIDs {
+		for night := from; night.Before(to.Time); night = night.AddDays(1) {
+			units := repository.inventory[claim{roomTypeID: roomTypeID, night: night}.key()]
+			if units == 0 {
+				continue
+			}
+			if booked[roomTypeID] == nil {
+				booked[roomTypeID] = make(map[string]int)
+			}
+			booked[roomTypeID][night.String()] = units
+		}
+	}
+	return booked, nil
+}
+
+// Booked devuelve cuántas habitaciones del tipo están tomadas en la noche indicada.
+func (repository Mock) Booked(roomTypeID string, night reservations.Date) int {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	return repository.inventory[claim{roomTypeID: roomTypeID, night: night}.key()]
+}
+
+func (repository Mock) changeStatus(id string, from, to string, apply func(*reservations.Reservation)) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	reservation, exists := repository.docs[id]
+	if !exists {
+		return fmt.Errorf("reservation %s: %w", id, reservations.ErrNotFound)
+	}
+	if reservation.Status != from {
+		return fmt.Errorf("reservation %s is no longer %s: %w", id, from, reservations.ErrInvalidTransition)
+	}
+	reservation.Status = to
+	apply(&reservation)
+	repository.docs[id] = reservation
+
+	if reservations.IsInactive(to) && !reservations.IsInactive(from) {
+		repository.free(claimsOf(reservation))
+	}
+	return nil
+}
+
+func (repository Mock) Hold(ctx context.Context, roomTypeID string, from, to reservations.Date, units int, capacity int) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	return repository.acquire(rangeClaims(roomTypeID, from, to, units), map[string]int{roomTypeID: capacity})
+}
+
+func (repository Mock) Unhold(ctx context.Context, roomTypeID string, from, to reservations.Date, units int) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.free(rangeClaims(roomTypeID, from, to, units))
+	return nil
+}
+
+func (repository Mock) ReleaseAllotment(ctx context.Context, allotmentID, roomTypeID string, from, to reservations.Date, rooms int) (int, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	released := 0
+	for night := from; night.Before(to.Time); night = night.AddDays(1) {
+		c := claim{roomTypeID: roomTypeID, allotmentID: allotmentID, night: night}
+		if repository.sealed[c.key()] {
+			continue
+		}
+		repository.sealed[c.key()] = true
+		if unused := rooms - repository.inventory[c.key()]; unused > 0 {
+			repository.inventory[c.general().key()] -= unused
+			released += unused
+		}
+	}
+	return released, nil
+}
+
+func (repository Mock) GetPickup(ctx context.Context, allotmentID string, from, to reservations.Date) (map[string]int, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	pickup := make(map[string]int)
+	for night := from; night.Before(to.Time); night = night.AddDays(1) {
+		if units := repository.inventory[claim{allotmentID: allotmentID, night: night}.key()]; units != 0 {
+			pickup[night.String()] = units
+		}
+	}
+	return pickup, nil
+}
+
+// acquire y free deben llamarse con el mutex tomado.
+func (repository Mock) acquire(claims []claim, capacity map[string]int) error {
+	for _, c := range claims {
+		if repository.sealed[c.key()] || repository.inventory[c.key()]+c.units > capacity[c.capacityKey()] {
+			return fmt.Errorf("room type %s is full on %s: %w", c.roomTypeID, c.night, reservations.ErrNoAvailability)
+		}
+	}
+	for _, c := range claims {
+		repository.inventory[c.key()] += c.units
+	}
+	return nil
+}
+
+func (repository Mock) free(claims []claim) {
+	for _, c := range claims {
+		repository.inventory[c.key()] -= c.units
+		if c.allotmentID != "" && repository.sealed[c.key()] {
+			repository.inventory[c.general().key()] -= c.units
+		}
+	}
+}
+
+func (repository Mock) find(filter reservations.SearchFilter) []reservations.Reservation {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	results := make([]reservations.Reservation, 0)
+	for _, reservation := range repository.docs {
+		if filter.HotelID != "" && reservation.HotelID != filter.HotelID {
+			continue
+		}
+		if filter.UserID != "" && reservation.UserID != filter.UserID {
+			continue
+		}
+		if len(filter.Statuses) > 0 && !contains(filter.Statuses, reservation.Status) {
+			continue
+		}
+		if !filter.To.IsZero() && !reservation.StartDate.Before(filter.To.Time) {
+			continue
+		}
+		if !filter.From.IsZero() && !reservation.EndDate.After(filter.From.Time) {
+			continue
+		}
+		results = append(results, reservation)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		less := results[i].StartDate.Before(results[j].StartDate.Time) ||
+			(results[i].StartDate.Equal(results[j].StartDate.Time) && results[i].ID < results[j].ID)
+		if filter.SortDesc {
+			return !less
+		}
+		return less
+	})
+	return results
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
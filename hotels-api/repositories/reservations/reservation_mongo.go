@@ -3,32 +3,124 @@ package reservations
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"hotels-api/domain/reservations"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Mongo struct {
-	client     *mongo.Client
-	database   string
-	collection string
+	client              *mongo.Client
+	database            string
+	collection          string
+	inventoryCollection string
 }
 
-func NewMongo(client *mongo.Client, database, collection string) Mongo {
-	return Mongo{client: client, database: database, collection: collection}
+func NewMongo(client *mongo.Client, database, collection, inventoryCollection string) Mongo {
+	return Mongo{client: client, database: database, collection: collection, inventoryCollection: inventoryCollection}
 }
 
-func (m Mongo) Create(ctx context.Context, reservation reservations.Reservation) (string, error) {
+// EnsureIndexes crea los índices que usan la disponibilidad, el calendario y las búsquedas del personal.
+func (m Mongo) EnsureIndexes(ctx context.Context) error {
+	_, err := m.client.Database(m.database).Collection(m.collection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "room_type_id", Value: 1}, {Key: "start_date", Value: 1}, {Key: "end_date", Value: 1}}},
+		{Keys: bson.D{{Key: "hotel_id", Value: 1}, {Key: "start_date", Value: 1}}},
+		{Keys: bson.D{{Key: "hotel_id", Value: 1}, {Key: "status", Value: 1}, {Key: "start_date", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "start_date", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "start_date", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating reservation indexes: %w", err)
+	}
+
+	_, err = m.inventory().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "room_type_id", Value: 1}, {Key: "night", Value: 1}}},
+		{Keys: bson.D{{Key: "allotment_id", Value: 1}, {Key: "night", Value: 1}}, Options: options.Index().SetSparse(true)},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating inventory indexes: %w", err)
+	}
+	return nil
+}
+
+// MigrateDates pasa a fecha BSON las start_date y end_date que las reservas anteriores guardaban
+// como texto "2006-01-02", para que entren en las búsquedas por rango. Se puede correr en cada
+// arranque: las reservas ya migradas no se tocan.
+func (m Mongo) MigrateDates(ctx context.Context) error {
+	for _, field := range []string{"start_date", "end_date"} {
+		_, err := m.client.Database(m.database).Collection(m.collection).UpdateMany(ctx,
+			bson.M{field: bson.M{"$type": "string"}},
+			mongo.Pipeline{{{Key: "$set", Value: bson.M{
+				field: bson.M{"$dateFromString": bson.M{"dateString": "$" + field, "format": "%Y-%m-%d", "timezone": "UTC"}},
+			}}}},
+		)
+		if err != nil {
+			return fmt.Errorf("error migrating %s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// Book toma las noches de la reserva en el inventario y recién entonces la inserta, de modo que
+// dos reservas concurrentes nunca puedan quedarse con la misma última habitación.
+func (m Mongo) Book(ctx context.Context, reservation reservations.Reservation, capacity map[string]int) (string, error) {
+	claims := claimsOf(reservation)
+	if err := m.acquire(ctx, claims, capacity); err != nil {
+		return "", err
+	}
+
 	result, err := m.client.Database(m.database).Collection(m.collection).InsertOne(ctx, reservation)
 	if err != nil {
+		m.free(ctx, claims)
 		return "", fmt.Errorf("error creating reservation: %w", err)
 	}
 
-	id := result.InsertedID.(primitive.ObjectID).Hex()
-	return id, nil
+	id, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		// El _id lo genera Mongo; si vino cargado la reserva no se puede usar y devuelve sus noches
+		m.client.Database(m.database).Collection(m.collection).DeleteOne(ctx, bson.M{"_id": result.InsertedID})
+		m.free(ctx, claims)
+		return "", fmt.Errorf("unexpected reservation ID %v", result.InsertedID)
+	}
+	return id.Hex(), nil
+}
+
+// Rebook aplica una modificación: toma primero las noches nuevas, guarda la reserva condicionada a
+// la versión leída y solo después libera las noches que dejó de usar. Si algo falla, devuelve lo tomado.
+func (m Mongo) Rebook(ctx context.Context, reservation reservations.Reservation, previous reservations.Reservation, capacity map[string]int) error {
+	acquire, free := diffClaims(claimsOf(previous), claimsOf(reservation))
+	if err := m.acquire(ctx, acquire, capacity); err != nil {
+		return err
+	}
+	if err := m.modify(ctx, reservation, previous.Version); err != nil {
+		m.free(ctx, acquire)
+		return err
+	}
+	return m.free(ctx, free)
+}
+
+func (m Mongo) GetByID(ctx context.Context, id string) (reservations.Reservation, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return reservations.Reservation{}, fmt.Errorf("invalid ID %q: %w", id, reservations.ErrNotFound)
+	}
+
+	var reservation reservations.Reservation
+	err = m.client.Database(m.database).Collection(m.collection).FindOne(ctx, bson.M{"_id": objectID}).Decode(&reservation)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return reservations.Reservation{}, fmt.Errorf("reservation %s: %w", id, reservations.ErrNotFound)
+	}
+	if err != nil {
+		return reservations.Reservation{}, fmt.Errorf("error getting reservation: %w", err)
+	}
+	return reservation, nil
 }
 
 func (m Mongo) GetByUserID(ctx context.Context, userID string) ([]reservations.Reservation, error) {
@@ -43,3 +135,185 @@ func (m Mongo) GetByUserID(ctx context.Context, userID string) ([]reservations.R
 	}
 	return reservations, nil
 }
+
+// GetExpired devuelve hasta limit reservas pendientes vencidas, de la más vieja a la más nueva:
+// las ofertas cuyo expires_at pasó antes de now y las demás creadas antes de createdBefore.
+func (m Mongo) GetExpired(ctx context.Context, now time.Time, createdBefore time.Time, limit int) ([]reservations.Reservation, error) {
+	query := bson.M{
+		"status": reservations.StatusPending,
+		"$or": []bson.M{
+			{"expires_at": bson.M{"$lt": now}},
+			{"expires_at": bson.M{"$exists": false}, "created_at": bson.M{"$lt": createdBefore}},
+		},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := m.client.Database(m.database).Collection(m.collection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error getting expired reservations: %w", err)
+	}
+
+	result := make([]reservations.Reservation, 0)
+	if err = cursor.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("error decoding reservations: %w", err)
+	}
+	return result, nil
+}
+
+// UpdateStatus cambia el estado solo si la reserva sigue en el estado from, para no pisar cambios concurrentes.
+func (m Mongo) UpdateStatus(ctx context.Context, id string, from, to string, at time.Time) error {
+	update := bson.M{
+		"$set":  bson.M{"status": to},
+		"$push": bson.M{"history": reservations.StatusChange{From: from, To: to, At: at}},
+	}
+	return m.changeStatus(ctx, id, from, to, update)
+}
+
+// Cancel marca la reserva como cancelada y guarda la penalidad en una única operación.
+func (m Mongo) Cancel(ctx context.Context, id string, from string, cancellation reservations.Cancellation) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":       reservations.StatusCancelled,
+			"cancellation": cancellation,
+		},
+		"$push": bson.M{"history": reservations.StatusChange{From: from, To: reservations.StatusCancelled, At: cancellation.CancelledAt}},
+	}
+	return m.changeStatus(ctx, id, from, reservations.StatusCancelled, update)
+}
+
+// changeStatus aplica update si la reserva sigue en from y, si el nuevo estado ya no ocupa
+// inventario, libera sus noches.
+func (m Mongo) changeStatus(ctx context.Context, id string, from, to string, update bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q: %w", id, reservations.ErrNotFound)
+	}
+
+	var previous reservations.Reservation
+	err = m.client.Database(m.database).Collection(m.collection).
+		FindOneAndUpdate(ctx, bson.M{"_id": objectID, "status": from}, update).
+		Decode(&previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return fmt.Errorf("reservation %s is no longer %s: %w", id, from, reservations.ErrInvalidTransition)
+	}
+	if err != nil {
+		return fmt.Errorf("error updating reservation status: %w", err)
+	}
+
+	if reservations.IsInactive(to) && !reservations.IsInactive(from) {
+		return m.free(ctx, claimsOf(previous))
+	}
+	return nil
+}
+
+// Search devuelve una página de reservas que cumplen el filtro y el total de coincidencias.
+func (m Mongo) Search(ctx context.Context, filter reservations.SearchFilter) ([]reservations.Reservation, int64, error) {
+	collection := m.client.Database(m.database).Collection(m.collection)
+	query := searchQuery(filter)
+
+	total, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting reservations: %w", err)
+	}
+
+	opts := searchOptions(filter).SetSkip(int64(filter.Offset)).SetLimit(int64(filter.Limit))
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error searching reservations: %w", err)
+	}
+
+	result := make([]reservations.Reservation, 0)
+	if err = cursor.All(ctx, &result); err != nil {
+		return nil, 0, fmt.Errorf("error decoding reservations: %w", err)
+	}
+	return result, total, nil
+}
+
+// Iterate recorre todas las reservas que cumplen el filtro sin cargarlas en memoria.
+func (m Mongo) Iterate(ctx context.Context, filter reservations.SearchFilter, fn func(reservations.Reservation) error) error {
+	cursor, err := m.client.Database(m.database).Collection(m.collection).Find(ctx, searchQuery(filter), searchOptions(filter))
+	if err != nil {
+		return fmt.Errorf("error searching reservations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var reservation reservations.Reservation
+		if err := cursor.Decode(&reservation); err != nil {
+			return fmt.Errorf("error decoding reservation: %w", err)
+		}
+		if err := fn(reservation); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func searchQuery(filter reservations.SearchFilter) bson.M {
+	query := bson.M{}
+	if filter.HotelID != "" {
+		query["hotel_id"] = filter.HotelID
+	}
+	if filter.UserID != "" {
+		query["user_id"] = filter.UserID
+	}
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+	if !filter.To.IsZero() {
+		query["start_date"] = bson.M{"$lt": filter.To}
+	}
+	if !filter.From.IsZero() {
+		query["end_date"] = bson.M{"$gt": filter.From}
+	}
+	return query
+}
+
+func searchOptions(filter reservations.SearchFilter) *options.FindOptions {
+	direction := 1
+	if filter.SortDesc {
+		direction = -1
+	}
+	return options.Find().SetSort(bson.D{{Key: filter.SortBy, Value: direction}, {Key: "_id", Value: direction}})
+}
+
+// modify guarda fechas, habitación, precio y descuentos nuevos solo si la reserva sigue en la versión leída.
+func (m Mongo) modify(ctx context.Context, reservation reservations.Reservation, version int) error {
+	objectID, err := primitive.ObjectIDFromHex(reservation.ID)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q: %w", reservation.ID, reservations.ErrNotFound)
+	}
+
+	filter := bson.M{
+		"_id":     objectID,
+		"version": version,
+		"status":  bson.M{"$in": reservations.ModifiableStatuses},
+	}
+	if version == 0 {
+		// Reservas anteriores al versionado no tienen el campo
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+	update := bson.M{"$set": bson.M{
+		"room_type_id":    reservation.RoomTypeID,
+		"start_date":      reservation.StartDate,
+		"end_date":        reservation.EndDate,
+		"guests":          reservation.Guests,
+		"items":           reservation.Items,
+		"currency":        reservation.Currency,
+		"nights":          reservation.Nights,
+		"discount":        reservation.Discount,
+		"total":           reservation.Total,
+		"promotion_id":    reservation.PromotionID,
+		"promo_discount":  reservation.PromoDiscount,
+		"points_discount": reservation.PointsDiscount,
+		"modifications":   reservation.Modifications,
+		"version":         version + 1,
+	}}
+	result, err := m.client.Database(m.database).Collection(m.collection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("error modifying reservation: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("reservation %s: %w", reservation.ID, reservations.ErrConcurrentUpdate)
+	}
+	return nil
+}
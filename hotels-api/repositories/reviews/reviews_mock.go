@@ -0,0 +1,114 @@
+package reviews
+
+import (
+	"context"
+	"fmt"
+	"hotels-api/domain/reviews"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Mock struct {
+	mutex *sync.Mutex
+	docs  map[string]reviews.Review
+}
+
+func NewMock() Mock {
+	return Mock{
+		mutex: &sync.Mutex{},
+		docs:  make(map[string]reviews.Review),
+	}
+}
+
+func (repository Mock) Create(ctx context.Context, review reviews.Review) (string, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, existing := range repository.docs {
+		if existing.ReservationID == review.ReservationID {
+			return "", fmt.Errorf("reservation %s: %w", review.ReservationID, reviews.ErrAlreadyReviewed)
+		}
+	}
+	review.ID = primitive.NewObjectID().Hex()
+	repository.docs[review.ID] = review
+	return review.ID, nil
+}
+
+func (repository Mock) GetByID(ctx context.Context, id string) (reviews.Review, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	review, exists := repository.docs[id]
+	if !exists {
+		return reviews.Review{}, fmt.Errorf("review %s: %w", id, reviews.ErrNotFound)
+	}
+	return review, nil
+}
+
+func (repository Mock) GetByHotelID(ctx context.Context, hotelID, status string) ([]reviews.Review, error) {
+	result := repository.find(func(r reviews.Review) bool { return r.HotelID == hotelID && r.Status == status })
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (repository Mock) GetByStatus(ctx context.Context, status string) ([]reviews.Review, error) {
+	result := repository.find(func(r reviews.Review) bool { return r.Status == status })
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (repository Mock) find(match func(reviews.Review) bool) []reviews.Review {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	result := make([]reviews.Review, 0)
+	for _, review := range repository.docs {
+		if match(review) {
+			result = append(result, review)
+		}
+	}
+	return result
+}
+
+func (repository Mock) Moderate(ctx context.Context, id string, from, to string, decision reviews.Decision) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	review, exists := repository.docs[id]
+	if !exists {
+		return fmt.Errorf("review %s: %w", id, reviews.ErrNotFound)
+	}
+	if review.Status != from {
+		return fmt.Errorf("review %s is no longer %s: %w", id, from, reviews.ErrInvalidModeration)
+	}
+	review.Status, review.Moderation = to, &decision
+	repository.docs[id] = review
+	return nil
+}
+
+func (repository Mock) SetReply(ctx context.Context, id string, reply reviews.Reply) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	review, exists := repository.docs[id]
+	if !exists {
+		return fmt.Errorf("review %s: %w", id, reviews.ErrNotFound)
+	}
+	review.Reply = &reply
+	repository.docs[id] = review
+	return nil
+}
+
+func (repository Mock) GetSummary(ctx context.Context, hotelID string) (reviews.Summary, error) {
+	approved := repository.find(func(r reviews.Review) bool { return r.HotelID == hotelID && r.Status == reviews.StatusApproved })
+	if len(approved) == 0 {
+		return reviews.Summary{}, nil
+	}
+	total := 0
+	for _, review := range approved {
+		total += review.Score
+	}
+	return reviews.Summary{Rating: float64(total) / float64(len(approved)), Count: len(approved)}, nil
+}
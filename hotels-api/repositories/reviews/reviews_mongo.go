@@ -0,0 +1,147 @@
+package reviews
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/reviews"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	client     *mongo.Client
+	database   string
+	collection string
+}
+
+func NewMongo(client *mongo.Client, database, collection string) Mongo {
+	return Mongo{client: client, database: database, collection: collection}
+}
+
+// EnsureIndexes admite una sola reseña por reserva y acelera las reseñas publicadas de cada hotel
+// y la cola de moderación.
+func (m Mongo) EnsureIndexes(ctx context.Context) error {
+	_, err := m.reviews().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "reservation_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "hotel_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating review indexes: %w", err)
+	}
+	return nil
+}
+
+func (m Mongo) reviews() *mongo.Collection {
+	return m.client.Database(m.database).Collection(m.collection)
+}
+
+func (m Mongo) Create(ctx context.Context, review reviews.Review) (string, error) {
+	result, err := m.reviews().InsertOne(ctx, review)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", fmt.Errorf("reservation %s: %w", review.ReservationID, reviews.ErrAlreadyReviewed)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error creating review: %w", err)
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (m Mongo) GetByID(ctx context.Context, id string) (reviews.Review, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return reviews.Review{}, fmt.Errorf("invalid ID %q: %w", id, reviews.ErrNotFound)
+	}
+	var review reviews.Review
+	err = m.reviews().FindOne(ctx, bson.M{"_id": objectID}).Decode(&review)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return reviews.Review{}, fmt.Errorf("review %s: %w", id, reviews.ErrNotFound)
+	}
+	if err != nil {
+		return reviews.Review{}, fmt.Errorf("error getting review: %w", err)
+	}
+	return review, nil
+}
+
+// GetByHotelID devuelve las reseñas del hotel en el estado pedido, de la más nueva a la más vieja.
+func (m Mongo) GetByHotelID(ctx context.Context, hotelID, status string) ([]reviews.Review, error) {
+	return m.find(ctx, bson.M{"hotel_id": hotelID, "status": status}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+}
+
+// GetByStatus devuelve las reseñas de todos los hoteles en el estado pedido, primero las más viejas.
+func (m Mongo) GetByStatus(ctx context.Context, status string) ([]reviews.Review, error) {
+	return m.find(ctx, bson.M{"status": status}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+}
+
+func (m Mongo) find(ctx context.Context, query bson.M, opts *options.FindOptions) ([]reviews.Review, error) {
+	cursor, err := m.reviews().Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error getting reviews: %w", err)
+	}
+	result := make([]reviews.Review, 0)
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("error decoding reviews: %w", err)
+	}
+	return result, nil
+}
+
+// Moderate cambia el estado de la reseña solo si sigue en from, para que dos moderadores no
+// decidan sobre la misma reseña a la vez.
+func (m Mongo) Moderate(ctx context.Context, id string, from, to string, decision reviews.Decision) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q: %w", id, reviews.ErrNotFound)
+	}
+	filter := bson.M{"_id": objectID, "status": from}
+	update := bson.M{"$set": bson.M{"status": to, "moderation": decision}}
+	result, err := m.reviews().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("error moderating review: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("review %s is no longer %s: %w", id, from, reviews.ErrInvalidModeration)
+	}
+	return nil
+}
+
+func (m Mongo) SetReply(ctx context.Context, id string, reply reviews.Reply) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q: %w", id, reviews.ErrNotFound)
+	}
+	result, err := m.reviews().UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{"reply": reply}})
+	if err != nil {
+		return fmt.Errorf("error replying to review: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("review %s: %w", id, reviews.ErrNotFound)
+	}
+	return nil
+}
+
+// GetSummary promedia en la base las reseñas aprobadas del hotel.
+func (m Mongo) GetSummary(ctx context.Context, hotelID string) (reviews.Summary, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"hotel_id": hotelID, "status": reviews.StatusApproved}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "rating": bson.M{"$avg": "$score"}, "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err := m.reviews().Aggregate(ctx, pipeline)
+	if err != nil {
+		return reviews.Summary{}, fmt.Errorf("error aggregating reviews: %w", err)
+	}
+	var result []struct {
+		Rating float64 `bson:"rating"`
+		Count  int     `bson:"count"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return reviews.Summary{}, fmt.Errorf("error decoding review summary: %w", err)
+	}
+	if len(result) == 0 {
+		return reviews.Summary{}, nil
+	}
+	return reviews.Summary{Rating: result[0].Rating, Count: result[0].Count}, nil
+}
@@ -0,0 +1,77 @@
+package rooms
+
+import (
+	"context"
+	"fmt"
+	roomsDAO "hotels-api/dao/rooms"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Mock struct {
+	docs map[string]roomsDAO.RoomType
+}
+
+func NewMock() Mock {
+	return Mock{
+		docs: make(map[string]roomsDAO.RoomType),
+	}
+}
+
+func (repository Mock) GetByHotelID(ctx context.Context, hotelID string) ([]roomsDAO.RoomType, error) {
+	roomTypes := make([]roomsDAO.RoomType, 0)
+	for _, roomType := range repository.docs {
+		if roomType.HotelID == hotelID {
+			roomTypes = append(roomTypes, roomType)
+		}
+	}
+	return roomTypes, nil
+}
+
+func (repository Mock) GetByID(ctx context.Context, id string) (roomsDAO.RoomType, error) {
+	roomType, exists := repository.docs[id]
+	if !exists {
+		return roomsDAO.RoomType{}, fmt.Errorf("room type with ID %s not found", id)
+	}
+	return roomType, nil
+}
+
+func (repository Mock) Create(ctx context.Context, roomType roomsDAO.RoomType) (string, error) {
+	roomType.ID = primitive.NewObjectID()
+	repository.docs[roomType.ID.Hex()] = roomType
+	return roomType.ID.Hex(), nil
+}
+
+func (repository Mock) Update(ctx context.Context, roomType roomsDAO.RoomType) error {
+	current, exists := repository.docs[roomType.ID.Hex()]
+	if !exists || current.HotelID != roomType.HotelID {
+		return fmt.Errorf("room type with ID %s not found", roomType.ID.Hex())
+	}
+
+	if roomType.Name != "" {
+		current.Name = roomType.Name
+	}
+	if roomType.Capacity > 0 {
+		current.Capacity = roomType.Capacity
+	}
+	if len(roomType.Beds) > 0 {
+		current.Beds = roomType.Beds
+	}
+	if roomType.Count > 0 {
+		current.Count = roomType.Count
+	}
+	if roomType.RatePlan != nil {
+		current.RatePlan = roomType.RatePlan
+	}
+	repository.docs[roomType.ID.Hex()] = current
+	return nil
+}
+
+func (repository Mock) Delete(ctx context.Context, hotelID string, id string) error {
+	current, exists := repository.docs[id]
+	if !exists || current.HotelID != hotelID {
+		return fmt.Errorf("room type with ID %s not found", id)
+	}
+	delete(repository.docs, id)
+	return nil
+}
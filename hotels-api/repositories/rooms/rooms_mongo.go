@@ -0,0 +1,109 @@
+package rooms
+
+import (
+	"context"
+	"fmt"
+	roomsDAO "hotels-api/dao/rooms"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type Mongo struct {
+	client     *mongo.Client
+	database   string
+	collection string
+}
+
+func NewMongo(client *mongo.Client, database, collection string) Mongo {
+	return Mongo{client: client, database: database, collection: collection}
+}
+
+func (m Mongo) GetByHotelID(ctx context.Context, hotelID string) ([]roomsDAO.RoomType, error) {
+	cursor, err := m.client.Database(m.database).Collection(m.collection).Find(ctx, bson.M{"hotel_id": hotelID})
+	if err != nil {
+		return nil, fmt.Errorf("error getting room types by hotel ID: %w", err)
+	}
+
+	roomTypes := make([]roomsDAO.RoomType, 0)
+	if err = cursor.All(ctx, &roomTypes); err != nil {
+		return nil, fmt.Errorf("error decoding room types: %w", err)
+	}
+	return roomTypes, nil
+}
+
+func (m Mongo) GetByID(ctx context.Context, id string) (roomsDAO.RoomType, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return roomsDAO.RoomType{}, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	result := m.client.Database(m.database).Collection(m.collection).FindOne(ctx, bson.M{"_id": objectID})
+	if result.Err() != nil {
+		return roomsDAO.RoomType{}, fmt.Errorf("error finding room type: %w", result.Err())
+	}
+
+	var roomType roomsDAO.RoomType
+	if err := result.Decode(&roomType); err != nil {
+		return roomsDAO.RoomType{}, fmt.Errorf("error decoding room type: %w", err)
+	}
+	return roomType, nil
+}
+
+func (m Mongo) Create(ctx context.Context, roomType roomsDAO.RoomType) (string, error) {
+	roomType.ID = primitive.NewObjectID()
+	if _, err := m.client.Database(m.database).Collection(m.collection).InsertOne(ctx, roomType); err != nil {
+		return "", fmt.Errorf("error creating room type: %w", err)
+	}
+	return roomType.ID.Hex(), nil
+}
+
+func (m Mongo) Update(ctx context.Context, roomType roomsDAO.RoomType) error {
+	// Solo se actualizan los campos informados
+	update := bson.M{}
+	if roomType.Name != "" {
+		update["name"] = roomType.Name
+	}
+	if roomType.Capacity > 0 {
+		update["capacity"] = roomType.Capacity
+	}
+	if len(roomType.Beds) > 0 {
+		update["beds"] = roomType.Beds
+	}
+	if roomType.Count > 0 {
+		update["count"] = roomType.Count
+	}
+	if roomType.RatePlan != nil {
+		update["rate_plan"] = roomType.RatePlan
+	}
+	if len(update) == 0 {
+		return fmt.Errorf("no fields to update for room type ID %s", roomType.ID.Hex())
+	}
+
+	filter := bson.M{"_id": roomType.ID, "hotel_id": roomType.HotelID}
+	result, err := m.client.Database(m.database).Collection(m.collection).UpdateOne(ctx, filter, bson.M{"$set": update})
+	if err != nil {
+		return fmt.Errorf("error updating room type: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no room type found with ID %s", roomType.ID.Hex())
+	}
+	return nil
+}
+
+func (m Mongo) Delete(ctx context.Context, hotelID string, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("error converting id to mongo ID: %w", err)
+	}
+
+	result, err := m.client.Database(m.database).Collection(m.collection).DeleteOne(ctx, bson.M{"_id": objectID, "hotel_id": hotelID})
+	if err != nil {
+		return fmt.Errorf("error deleting room type: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("no room type found with ID %s", id)
+	}
+	return nil
+}
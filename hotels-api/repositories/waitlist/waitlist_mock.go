@@ -0,0 +1,112 @@
+package waitlist
+
+import (
+	"context"
+	"fmt"
+	"hotels-api/domain/reservations"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Mock struct {
+	mutex *sync.Mutex
+	docs  map[string]reservations.WaitlistEntry
+}
+
+func NewMock() Mock {
+	return Mock{
+		mutex: &sync.Mutex{},
+		docs:  make(map[string]reservations.WaitlistEntry),
+	}
+}
+
+func (repository Mock) Create(ctx context.Context, entry reservations.WaitlistEntry) (string, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	entry.ID = primitive.NewObjectID().Hex()
+	repository.docs[entry.ID] = entry
+	return entry.ID, nil
+}
+
+func (repository Mock) GetByID(ctx context.Context, id string) (reservations.WaitlistEntry, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	entry, exists := repository.docs[id]
+	if !exists {
+		return reservations.WaitlistEntry{}, fmt.Errorf("waitlist entry %s: %w", id, reservations.ErrWaitlistNotFound)
+	}
+	return entry, nil
+}
+
+func (repository Mock) GetByUserID(ctx context.Context, userID string) ([]reservations.WaitlistEntry, error) {
+	return repository.find(func(entry reservations.WaitlistEntry) bool {
+		return entry.UserID == userID
+	}), nil
+}
+
+func (repository Mock) GetByHotelID(ctx context.Context, hotelID string) ([]reservations.WaitlistEntry, error) {
+	return repository.find(func(entry reservations.WaitlistEntry) bool {
+		return entry.HotelID == hotelID
+	}), nil
+}
+
+func (repository Mock) GetWaiting(ctx context.Context, roomTypeID string, from, to reservations.Date) ([]reservations.WaitlistEntry, error) {
+	return repository.find(func(entry reservations.WaitlistEntry) bool {
+		return entry.RoomTypeID == roomTypeID && entry.Status == reservations.WaitlistWaiting &&
+			entry.StartDate.Before(to.Time) && entry.EndDate.After(from.Time)
+	}), nil
+}
+
+func (repository Mock) Offer(ctx context.Context, id string, reservationID string, at time.Time) error {
+	return repository.changeStatus(id, func(entry *reservations.WaitlistEntry) {
+		entry.Status = reservations.WaitlistOffered
+		entry.ReservationID = reservationID
+		entry.OfferedAt = &at
+	})
+}
+
+func (repository Mock) Leave(ctx context.Context, id string) error {
+	return repository.changeStatus(id, func(entry *reservations.WaitlistEntry) {
+		entry.Status = reservations.WaitlistLeft
+	})
+}
+
+func (repository Mock) changeStatus(id string, apply func(*reservations.WaitlistEntry)) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	entry, exists := repository.docs[id]
+	if !exists {
+		return fmt.Errorf("waitlist entry %s: %w", id, reservations.ErrWaitlistNotFound)
+	}
+	if entry.Status != reservations.WaitlistWaiting {
+		return fmt.Errorf("waitlist entry %s is no longer waiting: %w", id, reservations.ErrInvalidTransition)
+	}
+	apply(&entry)
+	repository.docs[id] = entry
+	return nil
+}
+
+func (repository Mock) find(match func(reservations.WaitlistEntry) bool) []reservations.WaitlistEntry {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	result := make([]reservations.WaitlistEntry, 0)
+	for _, entry := range repository.docs {
+		if match(entry) {
+			result = append(result, entry)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
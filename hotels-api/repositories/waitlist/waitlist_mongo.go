@@ -0,0 +1,128 @@
+package waitlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/reservations"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	client     *mongo.Client
+	database   string
+	collection string
+}
+
+func NewMongo(client *mongo.Client, database, collection string) Mongo {
+	return Mongo{client: client, database: database, collection: collection}
+}
+
+// EnsureIndexes crea los índices para buscar la entrada más antigua de un tipo de habitación.
+func (m Mongo) EnsureIndexes(ctx context.Context) error {
+	_, err := m.client.Database(m.database).Collection(m.collection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "room_type_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "hotel_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating waitlist indexes: %w", err)
+	}
+	return nil
+}
+
+func (m Mongo) Create(ctx context.Context, entry reservations.WaitlistEntry) (string, error) {
+	result, err := m.client.Database(m.database).Collection(m.collection).InsertOne(ctx, entry)
+	if err != nil {
+		return "", fmt.Errorf("error creating waitlist entry: %w", err)
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (m Mongo) GetByID(ctx context.Context, id string) (reservations.WaitlistEntry, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return reservations.WaitlistEntry{}, fmt.Errorf("invalid ID %q: %w", id, reservations.ErrWaitlistNotFound)
+	}
+
+	var entry reservations.WaitlistEntry
+	err = m.client.Database(m.database).Collection(m.collection).FindOne(ctx, bson.M{"_id": objectID}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return reservations.WaitlistEntry{}, fmt.Errorf("waitlist entry %s: %w", id, reservations.ErrWaitlistNotFound)
+	}
+	if err != nil {
+		return reservations.WaitlistEntry{}, fmt.Errorf("error getting waitlist entry: %w", err)
+	}
+	return entry, nil
+}
+
+func (m Mongo) GetByUserID(ctx context.Context, userID string) ([]reservations.WaitlistEntry, error) {
+	return m.find(ctx, bson.M{"user_id": userID})
+}
+
+func (m Mongo) GetByHotelID(ctx context.Context, hotelID string) ([]reservations.WaitlistEntry, error) {
+	return m.find(ctx, bson.M{"hotel_id": hotelID})
+}
+
+// GetWaiting devuelve, de la más antigua a la más nueva, las entradas en espera del tipo de
+// habitación cuyas fechas se superponen con [from, to).
+func (m Mongo) GetWaiting(ctx context.Context, roomTypeID string, from, to reservations.Date) ([]reservations.WaitlistEntry, error) {
+	return m.find(ctx, bson.M{
+		"room_type_id": roomTypeID,
+		"status":       reservations.WaitlistWaiting,
+		"start_date":   bson.M{"$lt": to},
+		"end_date":     bson.M{"$gt": from},
+	})
+}
+
+// Offer asocia la reserva ofrecida a la entrada, solo si sigue esperando, para que dos
+// liberaciones simultáneas no le ofrezcan dos habitaciones al mismo huésped.
+func (m Mongo) Offer(ctx context.Context, id string, reservationID string, at time.Time) error {
+	update := bson.M{"$set": bson.M{
+		"status":         reservations.WaitlistOffered,
+		"reservation_id": reservationID,
+		"offered_at":     at,
+	}}
+	return m.changeStatus(ctx, id, update)
+}
+
+// Leave saca de la lista una entrada que sigue esperando.
+func (m Mongo) Leave(ctx context.Context, id string) error {
+	return m.changeStatus(ctx, id, bson.M{"$set": bson.M{"status": reservations.WaitlistLeft}})
+}
+
+func (m Mongo) changeStatus(ctx context.Context, id string, update bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q: %w", id, reservations.ErrWaitlistNotFound)
+	}
+
+	result, err := m.client.Database(m.database).Collection(m.collection).
+		UpdateOne(ctx, bson.M{"_id": objectID, "status": reservations.WaitlistWaiting}, update)
+	if err != nil {
+		return fmt.Errorf("error updating waitlist entry: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("waitlist entry %s is no longer waiting: %w", id, reservations.ErrInvalidTransition)
+	}
+	return nil
+}
+
+func (m Mongo) find(ctx context.Context, query bson.M) ([]reservations.WaitlistEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := m.client.Database(m.database).Collection(m.collection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error getting waitlist entries: %w", err)
+	}
+
+	result := make([]reservations.WaitlistEntry, 0)
+	if err = cursor.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("error decoding waitlist entries: %w", err)
+	}
+	return result, nil
+}
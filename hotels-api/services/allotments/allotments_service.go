@@ -0,0 +1,210 @@
+package allotments
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	hotelsDAO "hotels-api/dao/hotels"
+	roomsDAO "hotels-api/dao/rooms"
+	"hotels-api/domain/allotments"
+	hotelsDomain "hotels-api/domain/hotels"
+	"hotels-api/domain/reservations"
+	"log"
+	"strings"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, allotment allotments.Allotment) (string, error)
+	GetByID(ctx context.Context, id string) (allotments.Allotment, error)
+	GetByHotelID(ctx context.Context, hotelID string) ([]allotments.Allotment, error)
+	GetDue(ctx context.Context, before reservations.Date) ([]allotments.Allotment, error)
+	MarkReleased(ctx context.Context, id string, releasedRooms int, at time.Time) error
+}
+
+type InventoryRepository interface {
+	Hold(ctx context.Context, roomTypeID string, from, to reservations.Date, units int, capacity int) error
+	Unhold(ctx context.Context, roomTypeID string, from, to reservations.Date, units int) error
+	ReleaseAllotment(ctx context.Context, allotmentID, roomTypeID string, from, to reservations.Date, rooms int) (int, error)
+	GetPickup(ctx context.Context, allotmentID string, from, to reservations.Date) (map[string]int, error)
+}
+
+type RoomsRepository interface {
+	GetByID(ctx context.Context, id string) (roomsDAO.RoomType, error)
+}
+
+type HotelsRepository interface {
+	GetHotelByID(ctx context.Context, id string) (hotelsDAO.Hotel, error)
+}
+
+type AvailabilityCache interface {
+	DeleteAvailability(ctx context.Context, hotelID string) error
+}
+
+type Service struct {
+	repository          Repository
+	inventoryRepository InventoryRepository
+	roomsRepository     RoomsRepository
+	hotelsRepository    HotelsRepository
+	availabilityCache   AvailabilityCache
+}
+
+func NewService(repository Repository, inventoryRepository InventoryRepository, roomsRepository RoomsRepository, hotelsRepository HotelsRepository, availabilityCache AvailabilityCache) Service {
+	return Service{
+		repository:          repository,
+		inventoryRepository: inventoryRepository,
+		roomsRepository:     roomsRepository,
+		hotelsRepository:    hotelsRepository,
+		availabilityCache:   availabilityCache,
+	}
+}
+
+// Create aparta las habitaciones del cupo en el inventario general y lo guarda. Si el hotel no
+// tiene esas habitaciones libres todas las noches, el cupo no se crea. Sin código se genera uno.
+func (s Service) Create(ctx context.Context, allotment allotments.Allotment, now time.Time) (allotments.Allotment, error) {
+	allotment.Name = strings.TrimSpace(allotment.Name)
+	allotment.Code = strings.ToUpper(strings.TrimSpace(allotment.Code))
+	if allotment.Rooms <= 0 {
+		return allotments.Allotment{}, fmt.Errorf("rooms must be positive: %w", allotments.ErrInvalidAllotment)
+	}
+	if allotment.StartDate.IsZero() || allotment.EndDate.IsZero() || !allotment.EndDate.After(allotment.StartDate.Time) {
+		return allotments.Allotment{}, fmt.Errorf("end_date must be after start_date: %w", allotments.ErrInvalidAllotment)
+	}
+	if allotment.ReleaseDate.IsZero() || allotment.ReleaseDate.After(allotment.StartDate.Time) {
+		return allotments.Allotment{}, fmt.Errorf("release_date is required and cannot be after start_date: %w", allotments.ErrInvalidAllotment)
+	}
+
+	hotel, err := s.hotelsRepository.GetHotelByID(ctx, allotment.HotelID)
+	if err != nil {
+		return allotments.Allotment{}, fmt.Errorf("error getting hotel: %w", err)
+	}
+	if today := reservations.DateOf(now.In(hotelsDomain.Location(hotel.TimeZone))); !today.Before(allotment.ReleaseDate.Time) {
+		return allotments.Allotment{}, fmt.Errorf("release_date %s must be after today: %w", allotment.ReleaseDate, allotments.ErrInvalidAllotment)
+	}
+	roomType, err := s.roomsRepository.GetByID(ctx, allotment.RoomTypeID)
+	if err != nil || roomType.HotelID != allotment.HotelID {
+		return allotments.Allotment{}, fmt.Errorf("room type %q: %w", allotment.RoomTypeID, reservations.ErrUnknownRoomType)
+	}
+	if allotment.Code == "" {
+		if allotment.Code, err = newCode(); err != nil {
+			return allotments.Allotment{}, err
+		}
+	}
+
+	allotment.ID = ""
+	allotment.Status = allotments.StatusActive
+	allotment.CreatedAt = now
+	allotment.ReleasedAt, allotment.ReleasedRooms, allotment.Pickup = nil, 0, nil
+	if err := s.inventoryRepository.Hold(ctx, allotment.RoomTypeID, allotment.StartDate, allotment.EndDate, allotment.Rooms, roomType.Count); err != nil {
+		return allotments.Allotment{}, fmt.Errorf("error holding rooms for the block: %w", err)
+	}
+	id, err := s.repository.Create(ctx, allotment)
+	if err != nil {
+		if unholdErr := s.inventoryRepository.Unhold(ctx, allotment.RoomTypeID, allotment.StartDate, allotment.EndDate, allotment.Rooms); unholdErr != nil {
+			log.Printf("Error releasing rooms of unsaved allotment %s: %v", allotment.Code, unholdErr)
+		}
+		return allotments.Allotment{}, err
+	}
+	s.invalidate(ctx, allotment.HotelID)
+
+	allotment.ID = id
+	return allotment, nil
+}
+
+func (s Service) GetByHotelID(ctx context.Context, hotelID string) ([]allotments.Allotment, error) {
+	return s.repository.GetByHotelID(ctx, hotelID)
+}
+
+// Get devuelve el cupo del hotel con las habitaciones usadas cada noche.
+func (s Service) Get(ctx context.Context, hotelID, id string) (allotments.Allotment, error) {
+	allotment, err := s.allotmentOf(ctx, hotelID, id)
+	if err != nil {
+		return allotments.Allotment{}, err
+	}
+	allotment.Pickup, err = s.inventoryRepository.GetPickup(ctx, allotment.ID, allotment.StartDate, allotment.EndDate)
+	if err != nil {
+		return allotments.Allotment{}, err
+	}
+	return allotment, nil
+}
+
+// Release libera el cupo antes de su fecha, a pedido del hotel.
+func (s Service) Release(ctx context.Context, hotelID, id string, now time.Time) (allotments.Allotment, error) {
+	allotment, err := s.allotmentOf(ctx, hotelID, id)
+	if err != nil {
+		return allotments.Allotment{}, err
+	}
+	if allotment.Status != allotments.StatusActive {
+		return allotments.Allotment{}, fmt.Errorf("room block %s was released: %w", id, allotments.ErrBlockClosed)
+	}
+	return s.release(ctx, allotment, now)
+}
+
+// ReleaseDue libera los cupos que llegaron a su fecha de liberación en la fecha de su hotel. El
+// error de un cupo solo se registra para no frenar a los demás; se reintenta en la próxima corrida.
+func (s Service) ReleaseDue(ctx context.Context, now time.Time) error {
+	// La fecha de ningún hotel está más de un día adelante de la fecha UTC
+	due, err := s.repository.GetDue(ctx, reservations.DateOf(now).AddDays(2))
+	if err != nil {
+		return err
+	}
+	for _, allotment := range due {
+		hotel, err := s.hotelsRepository.GetHotelByID(ctx, allotment.HotelID)
+		if err != nil {
+			log.Printf("Error getting hotel %s of allotment %s: %v", allotment.HotelID, allotment.ID, err)
+			continue
+		}
+		if allotment.IsOpen(reservations.DateOf(now.In(hotelsDomain.Location(hotel.TimeZone)))) {
+			continue
+		}
+		if _, err := s.release(ctx, allotment, now); err != nil {
+			log.Printf("Error releasing allotment %s: %v", allotment.ID, err)
+		}
+	}
+	return nil
+}
+
+// release cierra el cupo y devuelve a la venta las habitaciones que no se usaron. El inventario
+// se libera antes de marcar el cupo, y liberarlo de nuevo no devuelve dos veces las mismas noches,
+// así que un corte a mitad de camino se completa en la próxima corrida.
+func (s Service) release(ctx context.Context, allotment allotments.Allotment, now time.Time) (allotments.Allotment, error) {
+	released, err := s.inventoryRepository.ReleaseAllotment(ctx, allotment.ID, allotment.RoomTypeID, allotment.StartDate, allotment.EndDate, allotment.Rooms)
+	if err != nil {
+		return allotments.Allotment{}, fmt.Errorf("error releasing block rooms: %w", err)
+	}
+	if err := s.repository.MarkReleased(ctx, allotment.ID, released, now); err != nil {
+		return allotments.Allotment{}, err
+	}
+	s.invalidate(ctx, allotment.HotelID)
+
+	allotment.Status, allotment.ReleasedAt, allotment.ReleasedRooms = allotments.StatusReleased, &now, released
+	return allotment, nil
+}
+
+// allotmentOf devuelve el cupo solo si pertenece al hotel de la ruta.
+func (s Service) allotmentOf(ctx context.Context, hotelID, id string) (allotments.Allotment, error) {
+	allotment, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return allotments.Allotment{}, err
+	}
+	if allotment.HotelID != hotelID {
+		return allotments.Allotment{}, fmt.Errorf("room block %s: %w", id, allotments.ErrNotFound)
+	}
+	return allotment, nil
+}
+
+func (s Service) invalidate(ctx context.Context, hotelID string) {
+	if err := s.availabilityCache.DeleteAvailability(ctx, hotelID); err != nil {
+		log.Printf("Error invalidating availability of hotel %s: %v", hotelID, err)
+	}
+}
+
+// newCode genera un código de 8 caracteres fácil de dictar, sin 0/O ni 1/I.
+func newCode() (string, error) {
+	buffer := make([]byte, 5)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", fmt.Errorf("error generating block code: %w", err)
+	}
+	return base32.NewEncoding("ABCDEFGHJKLMNPQRSTUVWXYZ23456789").EncodeToString(buffer), nil
+}
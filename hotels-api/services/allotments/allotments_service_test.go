@@ -0,0 +1,164 @@
+package allotments_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hotelsDAO "hotels-api/dao/hotels"
+	roomsDAO "hotels-api/dao/rooms"
+	"hotels-api/domain/allotments"
+	"hotels-api/domain/reservations"
+	repositoriesAllotments "hotels-api/repositories/allotments"
+	repositoriesHotels "hotels-api/repositories/hotels"
+	repositoriesReservations "hotels-api/repositories/reservations"
+	repositoriesRooms "hotels-api/repositories/rooms"
+	service "hotels-api/services/allotments"
+)
+
+type fixture struct {
+	service      service.Service
+	reservations repositoriesReservations.Mock
+	hotelID      string
+	roomTypeID   string
+	start        reservations.Date
+	now          time.Time
+}
+
+func newFixture(t *testing.T, count int) fixture {
+	t.Helper()
+	ctx := context.Background()
+	hotelsRepo := repositoriesHotels.NewMock()
+	hotelID, err := hotelsRepo.Create(ctx, hotelsDAO.Hotel{Name: "Hotel Sierras", TimeZone: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roomsRepo := repositoriesRooms.NewMock()
+	roomTypeID, err := roomsRepo.Create(ctx, roomsDAO.RoomType{HotelID: hotelID, Name: "Doble", Capacity: 2, Count: count})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := fixture{
+		reservations: repositoriesReservations.NewMock(),
+		hotelID:      hotelID,
+		roomTypeID:   roomTypeID,
+		now:          time.Now().UTC(),
+	}
+	f.start = reservations.DateOf(f.now).AddDays(30)
+	f.service = service.NewService(repositoriesAllotments.NewMock(), f.reservations, roomsRepo, hotelsRepo, repositoriesHotels.NewCache(repositoriesHotels.CacheConfig{
+		MaxSize:      1000,
+		ItemsToPrune: 10,
+		Duration:     time.Minute,
+	}))
+	return f
+}
+
+func (f fixture) allotment(rooms int) allotments.Allotment {
+	return allotments.Allotment{
+		HotelID:     f.hotelID,
+		RoomTypeID:  f.roomTypeID,
+		Name:        "Congreso de cardiología",
+		StartDate:   f.start,
+		EndDate:     f.start.AddDays(2),
+		Rooms:       rooms,
+		ReleaseDate: f.start.AddDays(-7),
+	}
+}
+
+func TestCreateHoldsTheBlockRooms(t *testing.T) {
+	f := newFixture(t, 3)
+	ctx := context.Background()
+
+	allotment, err := f.service.Create(ctx, f.allotment(2), f.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(allotment.Code) != 8 || allotment.Status != allotments.StatusActive {
+		t.Fatalf("expected an active block with a generated code, got %+v", allotment)
+	}
+	if booked := f.reservations.Booked(f.roomTypeID, f.start); booked != 2 {
+		t.Fatalf("expected 2 rooms held, got %d", booked)
+	}
+
+	// Queda 1 habitación libre por noche: un segundo cupo de 2 no entra
+	if _, err := f.service.Create(ctx, f.allotment(2), f.now); !errors.Is(err, reservations.ErrNoAvailability) {
+		t.Fatalf("expected no availability, got %v", err)
+	}
+	if booked := f.reservations.Booked(f.roomTypeID, f.start); booked != 2 {
+		t.Fatalf("expected the failed block not to hold rooms, got %d", booked)
+	}
+}
+
+func TestCreateValidatesTheBlock(t *testing.T) {
+	f := newFixture(t, 3)
+	cases := map[string]func(*allotments.Allotment){
+		"no rooms":            func(a *allotments.Allotment) { a.Rooms = 0 },
+		"inverted dates":      func(a *allotments.Allotment) { a.EndDate = a.StartDate },
+		"release after start": func(a *allotments.Allotment) { a.ReleaseDate = a.StartDate.AddDays(1) },
+		"release in the past": func(a *allotments.Allotment) { a.ReleaseDate = reservations.DateOf(f.now) },
+		"foreign room type":   func(a *allotments.Allotment) { a.RoomTypeID = "000000000000000000000000" },
+	}
+	for name, change := range cases {
+		t.Run(name, func(t *testing.T) {
+			allotment := f.allotment(1)
+			change(&allotment)
+			if _, err := f.service.Create(context.Background(), allotment, f.now); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestReleaseDueReturnsUnusedRooms(t *testing.T) {
+	f := newFixture(t, 3)
+	ctx := context.Background()
+	allotment, err := f.service.Create(ctx, f.allotment(2), f.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Un huésped del grupo usa la primera noche
+	if _, err := f.reservations.Book(ctx, reservations.Reservation{
+		HotelID:     f.hotelID,
+		UserID:      "7",
+		StartDate:   f.start,
+		EndDate:     f.start.AddDays(1),
+		Status:      reservations.StatusConfirmed,
+		AllotmentID: allotment.ID,
+		Items:       []reservations.LineItem{{RoomTypeID: f.roomTypeID, Quantity: 1, Adults: 2}},
+	}, map[string]int{f.roomTypeID: 3, allotment.ID: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Antes de la fecha de liberación no se toca
+	if err := f.service.ReleaseDue(ctx, f.now); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := f.service.Get(ctx, f.hotelID, allotment.ID); err != nil || got.Status != allotments.StatusActive || got.Pickup[f.start.String()] != 1 {
+		t.Fatalf("expected the block open with 1 room picked up, got %+v, %v", got, err)
+	}
+
+	due := allotment.ReleaseDate.Add(time.Hour)
+	if err := f.service.ReleaseDue(ctx, due); err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.service.Get(ctx, f.hotelID, allotment.ID)
+	if err != nil || got.Status != allotments.StatusReleased || got.ReleasedRooms != 3 {
+		t.Fatalf("expected 3 room-nights released, got %+v, %v", got, err)
+	}
+	if first, second := f.reservations.Booked(f.roomTypeID, f.start), f.reservations.Booked(f.roomTypeID, f.start.AddDays(1)); first != 1 || second != 0 {
+		t.Fatalf("expected nights booked 1 and 0, got %d and %d", first, second)
+	}
+
+	// Una segunda corrida no devuelve dos veces las mismas habitaciones
+	if err := f.service.ReleaseDue(ctx, due); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Release(ctx, f.hotelID, allotment.ID, due); !errors.Is(err, allotments.ErrBlockClosed) {
+		t.Fatalf("expected the block already released, got %v", err)
+	}
+	if booked := f.reservations.Booked(f.roomTypeID, f.start); booked != 1 {
+		t.Fatalf("expected the first night unchanged, got %d", booked)
+	}
+}
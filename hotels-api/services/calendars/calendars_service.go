@@ -0,0 +1,176 @@
+package calendars
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	hotelsDAO "hotels-api/dao/hotels"
+	roomsDAO "hotels-api/dao/rooms"
+	"hotels-api/domain/calendars"
+	"hotels-api/domain/reservations"
+	"strings"
+	"time"
+)
+
+type Repository interface {
+	Save(ctx context.Context, feed calendars.Feed) error
+	Get(ctx context.Context, scope, subjectID string) (calendars.Feed, error)
+}
+
+type ReservationsRepository interface {
+	Iterate(ctx context.Context, filter reservations.SearchFilter, fn func(reservations.Reservation) error) error
+}
+
+type HotelsRepository interface {
+	GetHotelByID(ctx context.Context, id string) (hotelsDAO.Hotel, error)
+}
+
+type RoomsRepository interface {
+	GetByID(ctx context.Context, id string) (roomsDAO.RoomType, error)
+}
+
+type Service struct {
+	repository             Repository
+	reservationsRepository ReservationsRepository
+	hotelsRepository       HotelsRepository
+	roomsRepository        RoomsRepository
+}
+
+func NewService(repository Repository, reservationsRepository ReservationsRepository, hotelsRepository HotelsRepository, roomsRepository RoomsRepository) Service {
+	return Service{
+		repository:             repository,
+		reservationsRepository: reservationsRepository,
+		hotelsRepository:       hotelsRepository,
+		roomsRepository:        roomsRepository,
+	}
+}
+
+// IssueToken genera el token de suscripción del huésped u hotel y reemplaza al anterior.
+func (s Service) IssueToken(ctx context.Context, scope, subjectID string) (calendars.Feed, error) {
+	if scope != calendars.ScopeUser && scope != calendars.ScopeHotel {
+		return calendars.Feed{}, fmt.Errorf("scope %q: %w", scope, calendars.ErrInvalidScope)
+	}
+	if scope == calendars.ScopeHotel {
+		if _, err := s.hotelsRepository.GetHotelByID(ctx, subjectID); err != nil {
+			return calendars.Feed{}, fmt.Errorf("error getting hotel: %w", err)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return calendars.Feed{}, fmt.Errorf("error generating calendar token: %w", err)
+	}
+	feed := calendars.Feed{
+		Scope:     scope,
+		SubjectID: subjectID,
+		Token:     hex.EncodeToString(secret),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repository.Save(ctx, feed); err != nil {
+		return calendars.Feed{}, err
+	}
+	return feed, nil
+}
+
+// CheckToken verifica el token de la URL de suscripción contra el vigente del huésped u hotel.
+func (s Service) CheckToken(ctx context.Context, scope, subjectID, token string) error {
+	feed, err := s.repository.Get(ctx, scope, subjectID)
+	if errors.Is(err, calendars.ErrNotFound) {
+		return fmt.Errorf("no feed for %s %s: %w", scope, subjectID, calendars.ErrInvalidToken)
+	}
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(feed.Token), []byte(token)) != 1 {
+		return calendars.ErrInvalidToken
+	}
+	return nil
+}
+
+// Events recorre las estadías del huésped u hotel que terminaron hace menos de PastDays días o
+// todavía no terminaron. Las canceladas y los no-show no se incluyen: al desaparecer del feed las
+// apps las quitan del calendario.
+func (s Service) Events(ctx context.Context, scope, subjectID string, now time.Time, fn func(calendars.Event) error) error {
+	filter := reservations.SearchFilter{
+		Statuses: []string{reservations.StatusPending, reservations.StatusConfirmed, reservations.StatusCheckedIn, reservations.StatusCheckedOut},
+		From:     reservations.DateOf(now).AddDays(-calendars.PastDays),
+		SortBy:   "start_date",
+	}
+	switch scope {
+	case calendars.ScopeUser:
+		filter.UserID = subjectID
+	case calendars.ScopeHotel:
+		filter.HotelID = subjectID
+	default:
+		return fmt.Errorf("scope %q: %w", scope, calendars.ErrInvalidScope)
+	}
+
+	// Un feed suele tener pocos hoteles y tipos de habitación: se buscan una sola vez
+	hotels := make(map[string]hotelsDAO.Hotel)
+	roomNames := make(map[string]string)
+	return s.reservationsRepository.Iterate(ctx, filter, func(reservation reservations.Reservation) error {
+		hotel, exists := hotels[reservation.HotelID]
+		if !exists {
+			found, err := s.hotelsRepository.GetHotelByID(ctx, reservation.HotelID)
+			if err != nil {
+				// El hotel se borró: el evento se muestra igual, sin nombre ni dirección
+				found = hotelsDAO.Hotel{}
+			}
+			hotel, hotels[reservation.HotelID] = found, found
+		}
+
+		event := calendars.Event{
+			Reservation:  reservation,
+			HotelName:    hotel.Name,
+			Location:     address(hotel),
+			RoomTypes:    make([]string, 0),
+			LastModified: lastModified(reservation),
+		}
+		for _, item := range reservation.LineItems() {
+			name, exists := roomNames[item.RoomTypeID]
+			if !exists {
+				name = item.RoomTypeID
+				if roomType, err := s.roomsRepository.GetByID(ctx, item.RoomTypeID); err == nil {
+					name = roomType.Name
+				}
+				roomNames[item.RoomTypeID] = name
+			}
+			if item.Quantity > 1 {
+				name = fmt.Sprintf("%d x %s", item.Quantity, name)
+			}
+			event.RoomTypes = append(event.RoomTypes, name)
+		}
+		return fn(event)
+	})
+}
+
+// address arma la dirección del hotel para el campo LOCATION del evento.
+func address(hotel hotelsDAO.Hotel) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{hotel.Address, hotel.City, hotel.State} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// lastModified es el último cambio registrado en la reserva; las apps lo usan para saber si el
+// evento se actualizó.
+func lastModified(reservation reservations.Reservation) time.Time {
+	last := reservation.CreatedAt
+	for _, change := range reservation.History {
+		if change.At.After(last) {
+			last = change.At
+		}
+	}
+	for _, modification := range reservation.Modifications {
+		if modification.At.After(last) {
+			last = modification.At
+		}
+	}
+	return last
+}
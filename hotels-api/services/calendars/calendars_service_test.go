@@ -0,0 +1,97 @@
+package calendars_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hotelsDAO "hotels-api/dao/hotels"
+	roomsDAO "hotels-api/dao/rooms"
+	"hotels-api/domain/calendars"
+	"hotels-api/domain/reservations"
+	repositoriesCalendars "hotels-api/repositories/calendars"
+	repositoriesHotels "hotels-api/repositories/hotels"
+	repositoriesReservations "hotels-api/repositories/reservations"
+	repositoriesRooms "hotels-api/repositories/rooms"
+	service "hotels-api/services/calendars"
+)
+
+func TestIssueTokenRevokesPreviousURL(t *testing.T) {
+	s := service.NewService(repositoriesCalendars.NewMock(), repositoriesReservations.NewMock(), repositoriesHotels.NewMock(), repositoriesRooms.NewMock())
+	ctx := context.Background()
+
+	if err := s.CheckToken(ctx, calendars.ScopeUser, "7", "anything"); !errors.Is(err, calendars.ErrInvalidToken) {
+		t.Fatalf("expected no token before issuing one, got %v", err)
+	}
+	first, err := s.IssueToken(ctx, calendars.ScopeUser, "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.IssueToken(ctx, calendars.ScopeUser, "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.CheckToken(ctx, calendars.ScopeUser, "7", first.Token); !errors.Is(err, calendars.ErrInvalidToken) {
+		t.Fatalf("expected the previous token to be revoked, got %v", err)
+	}
+	if err := s.CheckToken(ctx, calendars.ScopeUser, "8", second.Token); !errors.Is(err, calendars.ErrInvalidToken) {
+		t.Fatalf("expected the token to be valid only for its user, got %v", err)
+	}
+	if err := s.CheckToken(ctx, calendars.ScopeUser, "7", second.Token); err != nil {
+		t.Fatalf("expected the current token to be valid, got %v", err)
+	}
+}
+
+func TestEventsListsActiveStaysOfTheFeed(t *testing.T) {
+	ctx := context.Background()
+	hotelsRepo := repositoriesHotels.NewMock()
+	hotelID, err := hotelsRepo.Create(ctx, hotelsDAO.Hotel{Name: "Hotel Sierras", Address: "San Martín 100", City: "Córdoba"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roomsRepo := repositoriesRooms.NewMock()
+	roomTypeID, err := roomsRepo.Create(ctx, roomsDAO.RoomType{HotelID: hotelID, Name: "Doble", Capacity: 2, Count: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reservationsRepo := repositoriesReservations.NewMock()
+	today := reservations.DateOf(time.Now().UTC())
+	book := func(userID string, start reservations.Date, status string) string {
+		id, err := reservationsRepo.Book(ctx, reservations.Reservation{
+			HotelID:   hotelID,
+			UserID:    userID,
+			StartDate: start,
+			EndDate:   start.AddDays(2),
+			Status:    status,
+			Items:     []reservations.LineItem{{RoomTypeID: roomTypeID, Quantity: 2, Adults: 2}},
+		}, map[string]int{roomTypeID: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	upcoming := book("7", today.AddDays(5), reservations.StatusConfirmed)
+	book("7", today.AddDays(8), reservations.StatusCancelled)
+	book("7", today.AddDays(-calendars.PastDays-10), reservations.StatusCheckedOut)
+	book("8", today.AddDays(5), reservations.StatusConfirmed)
+
+	s := service.NewService(repositoriesCalendars.NewMock(), reservationsRepo, hotelsRepo, roomsRepo)
+	events := make([]calendars.Event, 0)
+	err = s.Events(ctx, calendars.ScopeUser, "7", time.Now().UTC(), func(event calendars.Event) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 1 || events[0].Reservation.ID != upcoming {
+		t.Fatalf("expected only the upcoming stay of user 7, got %+v", events)
+	}
+	if events[0].HotelName != "Hotel Sierras" || events[0].Location != "San Martín 100, Córdoba" || events[0].RoomTypes[0] != "2 x Doble" {
+		t.Fatalf("expected hotel and room details in the event, got %+v", events[0])
+	}
+}
@@ -0,0 +1,405 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/clients/ical"
+	hotelsDAO "hotels-api/dao/hotels"
+	roomsDAO "hotels-api/dao/rooms"
+	"hotels-api/domain/channels"
+	hotelsDomain "hotels-api/domain/hotels"
+	"hotels-api/domain/reservations"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Repository interface {
+	CreateFeed(ctx context.Context, feed channels.Feed) (string, error)
+	GetFeedByID(ctx context.Context, id string) (channels.Feed, error)
+	GetFeedsByHotelID(ctx context.Context, hotelID string) ([]channels.Feed, error)
+	GetFeeds(ctx context.Context) ([]channels.Feed, error)
+	UpdateSync(ctx context.Context, id string, at time.Time, events, skipped int, lastError string) error
+	DeleteFeed(ctx context.Context, id string) error
+	GetHolds(ctx context.Context, feedID string) ([]channels.Hold, error)
+	CreateHold(ctx context.Context, hold channels.Hold) (string, error)
+	UpdateHold(ctx context.Context, hold channels.Hold) error
+	DeleteHold(ctx context.Context, id string) error
+}
+
+type InventoryRepository interface {
+	Hold(ctx context.Context, roomTypeID string, from, to reservations.Date, units int, capacity int) error
+	Unhold(ctx context.Context, roomTypeID string, from, to reservations.Date, units int) error
+}
+
+type RoomsRepository interface {
+	GetByHotelID(ctx context.Context, hotelID string) ([]roomsDAO.RoomType, error)
+	GetByID(ctx context.Context, id string) (roomsDAO.RoomType, error)
+}
+
+type HotelsRepository interface {
+	GetHotelByID(ctx context.Context, id string) (hotelsDAO.Hotel, error)
+}
+
+type AvailabilityCache interface {
+	DeleteAvailability(ctx context.Context, hotelID string) error
+}
+
+type Fetcher interface {
+	Fetch(ctx context.Context, url string, location *time.Location) (ical.Calendar, error)
+}
+
+type Service struct {
+	repository          Repository
+	inventoryRepository InventoryRepository
+	roomsRepository     RoomsRepository
+	hotelsRepository    HotelsRepository
+	availabilityCache   AvailabilityCache
+	fetcher             Fetcher
+}
+
+func NewService(repository Repository, inventoryRepository InventoryRepository, roomsRepository RoomsRepository, hotelsRepository HotelsRepository, availabilityCache AvailabilityCache, fetcher Fetcher) Service {
+	return Service{
+		repository:          repository,
+		inventoryRepository: inventoryRepository,
+		roomsRepository:     roomsRepository,
+		hotelsRepository:    hotelsRepository,
+		availabilityCache:   availabilityCache,
+		fetcher:             fetcher,
+	}
+}
+
+// CreateFeed registra el calendario de otro canal para el hotel o uno de sus tipos de habitación.
+// Los bloqueos se crean en la próxima sincronización.
+func (s Service) CreateFeed(ctx context.Context, feed channels.Feed, now time.Time) (channels.Feed, error) {
+	feed.Name = strings.TrimSpace(feed.Name)
+	feed.URL = strings.TrimSpace(feed.URL)
+	parsed, err := url.Parse(feed.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return channels.Feed{}, fmt.Errorf("url %q must be an http or https URL: %w", feed.URL, channels.ErrInvalidFeed)
+	}
+	if _, err := s.hotelsRepository.GetHotelByID(ctx, feed.HotelID); err != nil {
+		return channels.Feed{}, fmt.Errorf("error getting hotel: %w", err)
+	}
+	if feed.RoomTypeID != "" {
+		roomType, err := s.roomsRepository.GetByID(ctx, feed.RoomTypeID)
+		if err != nil || roomType.HotelID != feed.HotelID {
+			return channels.Feed{}, fmt.Errorf("room type %s: %w", feed.RoomTypeID, reservations.ErrUnknownRoomType)
+		}
+	}
+
+	feed.ID = ""
+	feed.CreatedAt = now
+	feed.SyncedAt = nil
+	feed.Events, feed.Skipped, feed.LastError = 0, 0, ""
+	id, err := s.repository.CreateFeed(ctx, feed)
+	if err != nil {
+		return channels.Feed{}, err
+	}
+	feed.ID = id
+	return feed, nil
+}
+
+func (s Service) GetFeedsByHotelID(ctx context.Context, hotelID string) ([]channels.Feed, error) {
+	return s.repository.GetFeedsByHotelID(ctx, hotelID)
+}
+
+// GetHolds devuelve los bloqueos de un feed del hotel.
+func (s Service) GetHolds(ctx context.Context, hotelID, feedID string) ([]channels.Hold, error) {
+	if _, err := s.feedOf(ctx, hotelID, feedID); err != nil {
+		return nil, err
+	}
+	return s.repository.GetHolds(ctx, feedID)
+}
+
+// DeleteFeed borra el feed y devuelve al inventario las noches que bloqueaban sus eventos.
+func (s Service) DeleteFeed(ctx context.Context, hotelID, feedID string) error {
+	if _, err := s.feedOf(ctx, hotelID, feedID); err != nil {
+		return err
+	}
+	holds, err := s.repository.GetHolds(ctx, feedID)
+	if err != nil {
+		return err
+	}
+	for _, hold := range holds {
+		if err := s.removeHold(ctx, hold); err != nil {
+			return err
+		}
+	}
+	if err := s.repository.DeleteFeed(ctx, feedID); err != nil {
+		return err
+	}
+	s.invalidate(ctx, hotelID)
+	return nil
+}
+
+// SyncFeed sincroniza un feed del hotel a pedido del administrador.
+func (s Service) SyncFeed(ctx context.Context, hotelID, feedID string, now time.Time) (channels.Sync, error) {
+	feed, err := s.feedOf(ctx, hotelID, feedID)
+	if err != nil {
+		return channels.Sync{}, err
+	}
+	return s.sync(ctx, feed, now)
+}
+
+// SyncAll sincroniza todos los feeds. El error de un feed solo se registra, para que un canal
+// caído no frene la importación de los demás.
+func (s Service) SyncAll(ctx context.Context, now time.Time) error {
+	feeds, err := s.repository.GetFeeds(ctx)
+	if err != nil {
+		return err
+	}
+	for _, feed := range feeds {
+		if _, err := s.sync(ctx, feed, now); err != nil {
+			log.Printf("Error syncing iCal feed %s of hotel %s: %v", feed.ID, feed.HotelID, err)
+		}
+	}
+	return nil
+}
+
+// sync lleva los bloqueos del feed a lo que publica hoy el canal: crea los de los eventos nuevos,
+// mueve los que cambiaron de fechas y borra los de los eventos que desaparecieron o se cancelaron.
+// Si el feed no se puede descargar los bloqueos quedan como estaban: un corte del canal no debe
+// liberar noches que siguen vendidas.
+func (s Service) sync(ctx context.Context, feed channels.Feed, now time.Time) (channels.Sync, error) {
+	hotel, err := s.hotelsRepository.GetHotelByID(ctx, feed.HotelID)
+	if err != nil {
+		return channels.Sync{}, fmt.Errorf("error getting hotel: %w", err)
+	}
+	roomTypes, err := s.targets(ctx, feed)
+	if err != nil {
+		return channels.Sync{}, err
+	}
+	location := hotelsDomain.Location(hotel.TimeZone)
+
+	calendar, err := s.fetcher.Fetch(ctx, feed.URL, location)
+	if err != nil {
+		if updateErr := s.repository.UpdateSync(ctx, feed.ID, now, feed.Events, feed.Skipped, err.Error()); updateErr != nil {
+			log.Printf("Error saving sync error of iCal feed %s: %v", feed.ID, updateErr)
+		}
+		return channels.Sync{}, fmt.Errorf("error fetching feed %s: %w", feed.ID, err)
+	}
+
+	result := channels.Sync{FeedID: feed.ID, Events: len(calendar.Events), Skipped: calendar.Skipped}
+	capacity := make(map[string]int, len(roomTypes))
+	for _, roomType := range roomTypes {
+		capacity[roomType.ID.Hex()] = roomType.Count
+	}
+	desired := s.desiredHolds(feed, calendar, roomTypes, reservations.DateOf(now.In(location)), now)
+
+	existing, err := s.repository.GetHolds(ctx, feed.ID)
+	if err != nil {
+		return channels.Sync{}, err
+	}
+	for _, hold := range existing {
+		key := holdKey(hold.UID, hold.RoomTypeID)
+		want, wanted := desired[key]
+		delete(desired, key)
+		if !wanted {
+			if err := s.removeHold(ctx, hold); err != nil {
+				return result, err
+			}
+			result.Removed++
+			continue
+		}
+		if hold.Status == channels.HoldActive && hold.StartDate.Equal(want.StartDate.Time) && hold.EndDate.Equal(want.EndDate.Time) && hold.Quantity == want.Quantity {
+			if hold.Summary != want.Summary {
+				hold.Summary, hold.UpdatedAt = want.Summary, now
+				if err := s.repository.UpdateHold(ctx, hold); err != nil {
+					return result, err
+				}
+			}
+			continue
+		}
+		moved, err := s.moveHold(ctx, hold, want, capacity[hold.RoomTypeID])
+		if err != nil {
+			return result, err
+		}
+		if moved.Status == channels.HoldConflict {
+			result.Conflicts++
+		}
+		if moved.Status != hold.Status || !moved.StartDate.Equal(hold.StartDate.Time) || !moved.EndDate.Equal(hold.EndDate.Time) || moved.Quantity != hold.Quantity {
+			result.Updated++
+		}
+	}
+
+	// Los nuevos se crean en orden de fecha para que, si no entran todos, queden los primeros
+	created := make([]channels.Hold, 0, len(desired))
+	for _, hold := range desired {
+		created = append(created, hold)
+	}
+	sort.Slice(created, func(i, j int) bool {
+		if !created[i].StartDate.Equal(created[j].StartDate.Time) {
+			return created[i].StartDate.Before(created[j].StartDate.Time)
+		}
+		return holdKey(created[i].UID, created[i].RoomTypeID) < holdKey(created[j].UID, created[j].RoomTypeID)
+	})
+	for _, hold := range created {
+		hold, err := s.createHold(ctx, hold, capacity[hold.RoomTypeID])
+		if err != nil {
+			return result, err
+		}
+		result.Created++
+		if hold.Status == channels.HoldConflict {
+			result.Conflicts++
+		}
+	}
+
+	if result.Created+result.Updated+result.Removed > 0 {
+		s.invalidate(ctx, feed.HotelID)
+	}
+	if err := s.repository.UpdateSync(ctx, feed.ID, now, result.Events, result.Skipped, ""); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// targets devuelve los tipos de habitación que bloquea el feed: el suyo o todos los del hotel.
+func (s Service) targets(ctx context.Context, feed channels.Feed) ([]roomsDAO.RoomType, error) {
+	if feed.RoomTypeID == "" {
+		roomTypes, err := s.roomsRepository.GetByHotelID(ctx, feed.HotelID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting room types: %w", err)
+		}
+		return roomTypes, nil
+	}
+	roomType, err := s.roomsRepository.GetByID(ctx, feed.RoomTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting room type: %w", err)
+	}
+	return []roomsDAO.RoomType{roomType}, nil
+}
+
+// desiredHolds arma los bloqueos que corresponden a los eventos vigentes del feed, por UID y tipo
+// de habitación. Los eventos cancelados o ya terminados no bloquean nada.
+func (s Service) desiredHolds(feed channels.Feed, calendar ical.Calendar, roomTypes []roomsDAO.RoomType, today reservations.Date, now time.Time) map[string]channels.Hold {
+	desired := make(map[string]channels.Hold)
+	for _, event := range calendar.Events {
+		if event.Status == "CANCELLED" || !event.End.After(today.Time) {
+			continue
+		}
+		for _, roomType := range roomTypes {
+			quantity := 1
+			if feed.RoomTypeID == "" {
+				quantity = roomType.Count
+			}
+			if quantity <= 0 {
+				continue
+			}
+			desired[holdKey(event.UID, roomType.ID.Hex())] = channels.Hold{
+				FeedID:     feed.ID,
+				HotelID:    feed.HotelID,
+				RoomTypeID: roomType.ID.Hex(),
+				UID:        event.UID,
+				Summary:    event.Summary,
+				StartDate:  event.Start,
+				EndDate:    event.End,
+				Quantity:   quantity,
+				UpdatedAt:  now,
+			}
+		}
+	}
+	return desired
+}
+
+// createHold toma el inventario y guarda el bloqueo. Si las noches ya están vendidas se guarda
+// como conflicto; si no se puede guardar se devuelve lo tomado.
+func (s Service) createHold(ctx context.Context, hold channels.Hold, capacity int) (channels.Hold, error) {
+	status, err := s.take(ctx, hold, capacity)
+	if err != nil {
+		return channels.Hold{}, err
+	}
+	hold.Status = status
+	id, err := s.repository.CreateHold(ctx, hold)
+	if err != nil {
+		if hold.Status == channels.HoldActive {
+			s.give(ctx, hold)
+		}
+		return channels.Hold{}, err
+	}
+	hold.ID = id
+	return hold, nil
+}
+
+// moveHold lleva un bloqueo existente a las fechas y cantidad nuevas, o reintenta uno en conflicto.
+func (s Service) moveHold(ctx context.Context, hold channels.Hold, want channels.Hold, capacity int) (channels.Hold, error) {
+	if hold.Status == channels.HoldActive {
+		if err := s.inventoryRepository.Unhold(ctx, hold.RoomTypeID, hold.StartDate, hold.EndDate, hold.Quantity); err != nil {
+			return channels.Hold{}, fmt.Errorf("error releasing hold %s: %w", hold.ID, err)
+		}
+	}
+	status, err := s.take(ctx, want, capacity)
+	if err != nil {
+		return channels.Hold{}, err
+	}
+	previous := hold
+	hold.Summary, hold.StartDate, hold.EndDate, hold.Quantity = want.Summary, want.StartDate, want.EndDate, want.Quantity
+	hold.Status, hold.UpdatedAt = status, want.UpdatedAt
+	if err := s.repository.UpdateHold(ctx, hold); err != nil {
+		if status == channels.HoldActive {
+			s.give(ctx, hold)
+		}
+		if previous.Status == channels.HoldActive {
+			if _, takeErr := s.take(ctx, previous, capacity); takeErr != nil {
+				log.Printf("Error restoring hold %s: %v", previous.ID, takeErr)
+			}
+		}
+		return channels.Hold{}, err
+	}
+	return hold, nil
+}
+
+// removeHold devuelve las noches del bloqueo, si las tenía, y lo borra.
+func (s Service) removeHold(ctx context.Context, hold channels.Hold) error {
+	if hold.Status == channels.HoldActive {
+		if err := s.inventoryRepository.Unhold(ctx, hold.RoomTypeID, hold.StartDate, hold.EndDate, hold.Quantity); err != nil {
+			return fmt.Errorf("error releasing hold %s: %w", hold.ID, err)
+		}
+	}
+	return s.repository.DeleteHold(ctx, hold.ID)
+}
+
+// take intenta tomar el inventario del bloqueo y devuelve el estado que le corresponde.
+func (s Service) take(ctx context.Context, hold channels.Hold, capacity int) (string, error) {
+	err := s.inventoryRepository.Hold(ctx, hold.RoomTypeID, hold.StartDate, hold.EndDate, hold.Quantity, capacity)
+	if errors.Is(err, reservations.ErrNoAvailability) {
+		log.Printf("iCal hold %s of feed %s overlaps sold nights of room type %s from %s to %s", hold.UID, hold.FeedID, hold.RoomTypeID, hold.StartDate, hold.EndDate)
+		return channels.HoldConflict, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error holding inventory: %w", err)
+	}
+	return channels.HoldActive, nil
+}
+
+// give compensa un take cuyo bloqueo no se pudo guardar.
+func (s Service) give(ctx context.Context, hold channels.Hold) {
+	if err := s.inventoryRepository.Unhold(ctx, hold.RoomTypeID, hold.StartDate, hold.EndDate, hold.Quantity); err != nil {
+		log.Printf("Error releasing unsaved hold %s of feed %s: %v", hold.UID, hold.FeedID, err)
+	}
+}
+
+// feedOf devuelve el feed solo si pertenece al hotel de la ruta.
+func (s Service) feedOf(ctx context.Context, hotelID, feedID string) (channels.Feed, error) {
+	feed, err := s.repository.GetFeedByID(ctx, feedID)
+	if err != nil {
+		return channels.Feed{}, err
+	}
+	if feed.HotelID != hotelID {
+		return channels.Feed{}, fmt.Errorf("feed %s: %w", feedID, channels.ErrFeedNotFound)
+	}
+	return feed, nil
+}
+
+func (s Service) invalidate(ctx context.Context, hotelID string) {
+	if err := s.availabilityCache.DeleteAvailability(ctx, hotelID); err != nil {
+		log.Printf("Error invalidating availability of hotel %s: %v", hotelID, err)
+	}
+}
+
+func holdKey(uid, roomTypeID string) string {
+	return uid + "|" + roomTypeID
+}
@@ -0,0 +1,218 @@
+package channels_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"hotels-api/clients/ical"
+	hotelsDAO "hotels-api/dao/hotels"
+	roomsDAO "hotels-api/dao/rooms"
+	"hotels-api/domain/channels"
+	"hotels-api/domain/reservations"
+	repositoriesChannels "hotels-api/repositories/channels"
+	repositoriesHotels "hotels-api/repositories/hotels"
+	repositoriesReservations "hotels-api/repositories/reservations"
+	repositoriesRooms "hotels-api/repositories/rooms"
+	service "hotels-api/services/channels"
+)
+
+// fakeFetcher devuelve el calendario que el test publica en cada momento, o err si el canal está caído.
+type fakeFetcher struct {
+	calendar *ical.Calendar
+	err      *error
+}
+
+func (f fakeFetcher) Fetch(ctx context.Context, url string, location *time.Location) (ical.Calendar, error) {
+	if *f.err != nil {
+		return ical.Calendar{}, *f.err
+	}
+	return *f.calendar, nil
+}
+
+type fixture struct {
+	service      service.Service
+	reservations repositoriesReservations.Mock
+	channels     repositoriesChannels.Mock
+	calendar     *ical.Calendar
+	err          *error
+	hotelID      string
+	roomTypeID   string
+	today        reservations.Date
+	now          time.Time
+}
+
+func newFixture(t *testing.T, count int) fixture {
+	t.Helper()
+	ctx := context.Background()
+	hotelsRepo := repositoriesHotels.NewMock()
+	hotelID, err := hotelsRepo.Create(ctx, hotelsDAO.Hotel{Name: "Hotel Sierras", TimeZone: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roomsRepo := repositoriesRooms.NewMock()
+	roomTypeID, err := roomsRepo.Create(ctx, roomsDAO.RoomType{HotelID: hotelID, Name: "Doble", Capacity: 2, Count: count})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := fixture{
+		reservations: repositoriesReservations.NewMock(),
+		channels:     repositoriesChannels.NewMock(),
+		calendar:     &ical.Calendar{},
+		err:          new(error),
+		hotelID:      hotelID,
+		roomTypeID:   roomTypeID,
+		now:          time.Now().UTC(),
+	}
+	f.today = reservations.DateOf(f.now)
+	f.service = service.NewService(f.channels, f.reservations, roomsRepo, hotelsRepo, repositoriesHotels.NewCache(repositoriesHotels.CacheConfig{
+		MaxSize:      1000,
+		ItemsToPrune: 10,
+		Duration:     time.Minute,
+	}), fakeFetcher{calendar: f.calendar, err: f.err})
+	return f
+}
+
+func (f fixture) feed(t *testing.T) channels.Feed {
+	t.Helper()
+	feed, err := f.service.CreateFeed(context.Background(), channels.Feed{
+		HotelID:    f.hotelID,
+		RoomTypeID: f.roomTypeID,
+		Name:       "Airbnb",
+		URL:        "https://www.airbnb.com/calendar/ical/1.ics",
+	}, f.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return feed
+}
+
+func (f fixture) publish(events ...ical.Event) {
+	*f.calendar = ical.Calendar{Events: events}
+}
+
+func (f fixture) booked(from, to reservations.Date) string {
+	nights := ""
+	for night := from; night.Before(to.Time); night = night.AddDays(1) {
+		nights += fmt.Sprint(f.reservations.Booked(f.roomTypeID, night))
+	}
+	return nights
+}
+
+func (f fixture) sync(t *testing.T, feed channels.Feed) channels.Sync {
+	t.Helper()
+	result, err := f.service.SyncFeed(context.Background(), f.hotelID, feed.ID, f.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestSyncFollowsTheFeed(t *testing.T) {
+	f := newFixture(t, 2)
+	feed := f.feed(t)
+	start := f.today.AddDays(3)
+
+	f.publish(
+		ical.Event{UID: "a@airbnb", Start: start, End: start.AddDays(2)},
+		ical.Event{UID: "b@airbnb", Start: start.AddDays(1), End: start.AddDays(3)},
+		ical.Event{UID: "c@airbnb", Status: "CANCELLED", Start: start, End: start.AddDays(1)},
+		ical.Event{UID: "d@airbnb", Start: f.today.AddDays(-5), End: f.today.AddDays(-2)},
+	)
+	if result := f.sync(t, feed); result.Created != 2 || result.Conflicts != 0 {
+		t.Fatalf("expected 2 holds created, got %+v", result)
+	}
+	if got := f.booked(start, start.AddDays(4)); got != "1210" {
+		t.Fatalf("expected nights booked 1210, got %s", got)
+	}
+
+	// Sin cambios en el feed no se toca el inventario
+	if result := f.sync(t, feed); result.Created+result.Updated+result.Removed != 0 {
+		t.Fatalf("expected an idempotent sync, got %+v", result)
+	}
+
+	// a se alarga una noche y b desaparece del feed
+	f.publish(ical.Event{UID: "a@airbnb", Start: start, End: start.AddDays(3)})
+	if result := f.sync(t, feed); result.Updated != 1 || result.Removed != 1 {
+		t.Fatalf("expected 1 hold moved and 1 removed, got %+v", result)
+	}
+	if got := f.booked(start, start.AddDays(4)); got != "1110" {
+		t.Fatalf("expected nights booked 1110, got %s", got)
+	}
+
+	// Si el canal no responde los bloqueos quedan como estaban
+	*f.err = fmt.Errorf("timeout: %w", ical.ErrUnavailable)
+	if _, err := f.service.SyncFeed(context.Background(), f.hotelID, feed.ID, f.now); err == nil {
+		t.Fatal("expected the fetch error")
+	}
+	if got := f.booked(start, start.AddDays(4)); got != "1110" {
+		t.Fatalf("expected holds kept while the feed is down, got %s", got)
+	}
+	feeds, err := f.service.GetFeedsByHotelID(context.Background(), f.hotelID)
+	if err != nil || len(feeds) != 1 || feeds[0].LastError == "" {
+		t.Fatalf("expected the sync error on the feed, got %+v, %v", feeds, err)
+	}
+
+	// Borrar el feed libera todo
+	if err := f.service.DeleteFeed(context.Background(), f.hotelID, feed.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.booked(start, start.AddDays(4)); got != "0000" {
+		t.Fatalf("expected nights released, got %s", got)
+	}
+}
+
+func TestSyncRecordsConflictsWithSoldNights(t *testing.T) {
+	f := newFixture(t, 1)
+	feed := f.feed(t)
+	start := f.today.AddDays(3)
+	if _, err := f.reservations.Book(context.Background(), reservations.Reservation{
+		HotelID:   f.hotelID,
+		UserID:    "7",
+		StartDate: start,
+		EndDate:   start.AddDays(1),
+		Status:    reservations.StatusConfirmed,
+		Items:     []reservations.LineItem{{RoomTypeID: f.roomTypeID, Quantity: 1, Adults: 2}},
+	}, map[string]int{f.roomTypeID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	f.publish(ical.Event{UID: "a@booking", Start: start, End: start.AddDays(2)})
+	if result := f.sync(t, feed); result.Created != 1 || result.Conflicts != 1 {
+		t.Fatalf("expected a conflicting hold, got %+v", result)
+	}
+	holds, err := f.service.GetHolds(context.Background(), f.hotelID, feed.ID)
+	if err != nil || len(holds) != 1 || holds[0].Status != channels.HoldConflict {
+		t.Fatalf("expected the hold in conflict, got %+v, %v", holds, err)
+	}
+	if got := f.booked(start, start.AddDays(2)); got != "10" {
+		t.Fatalf("expected the conflict not to take inventory, got %s", got)
+	}
+
+	// Cuando el canal corrige las fechas el bloqueo entra
+	f.publish(ical.Event{UID: "a@booking", Start: start.AddDays(1), End: start.AddDays(2)})
+	if result := f.sync(t, feed); result.Updated != 1 || result.Conflicts != 0 {
+		t.Fatalf("expected the hold to be placed, got %+v", result)
+	}
+	if got := f.booked(start, start.AddDays(2)); got != "11" {
+		t.Fatalf("expected nights booked 11, got %s", got)
+	}
+}
+
+func TestCreateFeedValidatesURLAndRoomType(t *testing.T) {
+	f := newFixture(t, 1)
+	cases := map[string]channels.Feed{
+		"ftp url":          {HotelID: f.hotelID, URL: "ftp://example.com/a.ics"},
+		"relative url":     {HotelID: f.hotelID, URL: "/a.ics"},
+		"foreign roomtype": {HotelID: f.hotelID, URL: "https://example.com/a.ics", RoomTypeID: "000000000000000000000000"},
+	}
+	for name, feed := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := f.service.CreateFeed(context.Background(), feed, f.now); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
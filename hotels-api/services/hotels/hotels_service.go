@@ -5,8 +5,11 @@ import (
 	"fmt"
 	hotelsDAO "hotels-api/dao/hotels"
 	hotelsDomain "hotels-api/domain/hotels"
+	pricingDomain "hotels-api/domain/pricing"
 
 	"log"
+	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -16,6 +19,7 @@ type Repository interface {
 	Create(ctx context.Context, hotel hotelsDAO.Hotel) (string, error)
 	Update(ctx context.Context, hotel hotelsDAO.Hotel) error
 	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, filter hotelsDomain.ListFilter) ([]hotelsDAO.Hotel, int64, error)
 }
 
 type Queue interface {
@@ -51,27 +55,113 @@ func (service Service) GetHotelByID(ctx context.Context, id string) (hotelsDomai
 	}
 
 	// Convert DAO to DTO
-	return hotelsDomain.Hotel{
-		ID:          hotelDAO.ID.Hex(),
-		Name:        hotelDAO.Name,
-		Address:     hotelDAO.Address,
-		City:        hotelDAO.City,
-		State:       hotelDAO.State,
-		Rating:      hotelDAO.Rating,
-		Amenities:   hotelDAO.Amenities,
-		Descripcion: hotelDAO.Descripcion,
+	return hotelToDomain(hotelDAO), nil
+}
+
+// List lista los hoteles directamente desde Mongo, sin pasar por la cache ni por el índice de
+// búsqueda, para que las herramientas de administración vean siempre los datos vigentes.
+func (service Service) List(ctx context.Context, filter hotelsDomain.ListFilter) (hotelsDomain.ListResult, error) {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return hotelsDomain.ListResult{}, err
+	}
+
+	found, total, err := service.mainRepository.List(ctx, filter)
+	if err != nil {
+		return hotelsDomain.ListResult{}, fmt.Errorf("error listing hotels: %w", err)
+	}
+	results := make([]hotelsDomain.Hotel, 0, len(found))
+	for _, hotelDAO := range found {
+		results = append(results, hotelToDomain(hotelDAO))
+	}
+	return hotelsDomain.ListResult{
+		Results: results,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
 	}, nil
 }
 
+func normalizeFilter(filter hotelsDomain.ListFilter) (hotelsDomain.ListFilter, error) {
+	filter.City = strings.TrimSpace(filter.City)
+	filter.State = strings.TrimSpace(filter.State)
+	amenities := make([]string, 0, len(filter.Amenities))
+	for _, amenity := range filter.Amenities {
+		if amenity = strings.TrimSpace(amenity); amenity != "" {
+			amenities = append(amenities, amenity)
+		}
+	}
+	filter.Amenities = amenities
+
+	if filter.MinRating < 0 || filter.MinRating > 5 {
+		return filter, fmt.Errorf("min_rating must be between 0 and 5: %w", hotelsDomain.ErrInvalidFilter)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = hotelsDomain.DefaultListLimit
+	}
+	if filter.Limit < 0 || filter.Limit > hotelsDomain.MaxListLimit || filter.Offset < 0 {
+		return filter, fmt.Errorf("limit must be between 1 and %d and offset cannot be negative: %w", hotelsDomain.MaxListLimit, hotelsDomain.ErrInvalidFilter)
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = "name"
+	}
+	valid := false
+	for _, field := range hotelsDomain.ListSortFields {
+		valid = valid || field == filter.SortBy
+	}
+	if !valid {
+		return filter, fmt.Errorf("cannot sort by %q: %w", filter.SortBy, hotelsDomain.ErrInvalidFilter)
+	}
+	return filter, nil
+}
+
+func hotelToDomain(hotelDAO hotelsDAO.Hotel) hotelsDomain.Hotel {
+	return hotelsDomain.Hotel{
+		ID:            hotelDAO.ID.Hex(),
+		Name:          hotelDAO.Name,
+		Address:       hotelDAO.Address,
+		City:          hotelDAO.City,
+		State:         hotelDAO.State,
+		Rating:        hotelDAO.Rating,
+		ReviewCount:   hotelDAO.ReviewCount,
+		Amenities:     hotelDAO.Amenities,
+		Descripcion:   hotelDAO.Descripcion,
+		Policy:        policyToDomain(hotelDAO.Policy),
+		TimeZone:      hotelDAO.TimeZone,
+		CheckInTime:   hotelDAO.CheckInTime,
+		BookingCutoff: hotelDAO.BookingCutoff,
+		MaxStayNights: hotelDAO.MaxStayNights,
+		TaxID:         hotelDAO.TaxID,
+		TaxPercent:    hotelDAO.TaxPercent,
+	}
+}
+
 func (service Service) Create(ctx context.Context, hotel hotelsDomain.Hotel) (string, error) {
+	if err := validatePolicy(hotel.Policy); err != nil {
+		return "", err
+	}
+	if err := validateSchedule(hotel); err != nil {
+		return "", err
+	}
+	if hotel.TaxPercent < 0 || hotel.TaxPercent > 100 {
+		return "", fmt.Errorf("tax_percent must be between 0 and 100: %w", hotelsDomain.ErrInvalidTax)
+	}
+	// La calificación no se carga a mano: arranca en 0 y se calcula con las reseñas
 	record := hotelsDAO.Hotel{
-		Name:        hotel.Name,
-		Address:     hotel.Address,
-		City:        hotel.City,
-		State:       hotel.State,
-		Rating:      hotel.Rating,
-		Amenities:   hotel.Amenities,
-		Descripcion: hotel.Descripcion,
+		Name:          hotel.Name,
+		Address:       hotel.Address,
+		City:          hotel.City,
+		State:         hotel.State,
+		Amenities:     hotel.Amenities,
+		Descripcion:   hotel.Descripcion,
+		Policy:        policyToDAO(hotel.Policy),
+		TimeZone:      hotel.TimeZone,
+		CheckInTime:   hotel.CheckInTime,
+		BookingCutoff: hotel.BookingCutoff,
+		MaxStayNights: hotel.MaxStayNights,
+		TaxID:         hotel.TaxID,
+		TaxPercent:    hotel.TaxPercent,
 	}
 	id, err := service.mainRepository.Create(ctx, record)
 	if err != nil {
@@ -97,22 +187,42 @@ func (service Service) Create(ctx context.Context, hotel hotelsDomain.Hotel) (st
 }
 
 func (service Service) Update(ctx context.Context, hotel hotelsDomain.Hotel) error {
+	if err := validatePolicy(hotel.Policy); err != nil {
+		return err
+	}
+	if err := validateSchedule(hotel); err != nil {
+		return err
+	}
+	if hotel.TaxPercent < 0 || hotel.TaxPercent > 100 {
+		return fmt.Errorf("tax_percent must be between 0 and 100: %w", hotelsDomain.ErrInvalidTax)
+	}
+	if err := validateClear(hotel); err != nil {
+		return err
+	}
+
 	// Convertir modelo de dominio a modelo DAO
 	objectID, err := primitive.ObjectIDFromHex(hotel.ID)
 	if err != nil {
 		return fmt.Errorf("invalid ID format: %w", err)
 	}
 
-	// Crear registro del hotel para actualizar
+	// Crear registro del hotel para actualizar. La calificación no se edita: sale de las reseñas
 	record := hotelsDAO.Hotel{
-		ID:          objectID,
-		Name:        hotel.Name,
-		Address:     hotel.Address,
-		City:        hotel.City,
-		State:       hotel.State,
-		Rating:      hotel.Rating,
-		Amenities:   hotel.Amenities,
-		Descripcion: hotel.Descripcion,
+		ID:            objectID,
+		Name:          hotel.Name,
+		Address:       hotel.Address,
+		City:          hotel.City,
+		State:         hotel.State,
+		Amenities:     hotel.Amenities,
+		Descripcion:   hotel.Descripcion,
+		Policy:        policyToDAO(hotel.Policy),
+		TimeZone:      hotel.TimeZone,
+		CheckInTime:   hotel.CheckInTime,
+		BookingCutoff: hotel.BookingCutoff,
+		MaxStayNights: hotel.MaxStayNights,
+		TaxID:         hotel.TaxID,
+		TaxPercent:    hotel.TaxPercent,
+		Clear:         hotel.Clear,
 	}
 
 	// 1. Actualizar el hotel en el repositorio principal (MongoDB)
@@ -169,3 +279,101 @@ func (service Service) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+func validatePolicy(policy *hotelsDomain.CancellationPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.DepositPercent < 0 || policy.DepositPercent > 100 {
+		return fmt.Errorf("deposit_percent must be between 0 and 100: %w", hotelsDomain.ErrInvalidPolicy)
+	}
+	for _, tier := range policy.Tiers {
+		if tier.HoursBeforeCheckIn <= 0 {
+			return fmt.Errorf("hours_before_check_in must be positive: %w", hotelsDomain.ErrInvalidPolicy)
+		}
+		if tier.PenaltyPercent < 0 || tier.PenaltyPercent > 100 {
+			return fmt.Errorf("penalty_percent must be between 0 and 100: %w", hotelsDomain.ErrInvalidPolicy)
+		}
+	}
+	return nil
+}
+
+func validateSchedule(hotel hotelsDomain.Hotel) error {
+	if hotel.TimeZone != "" {
+		if _, err := time.LoadLocation(hotel.TimeZone); err != nil {
+			return fmt.Errorf("unknown time_zone %q: %w", hotel.TimeZone, hotelsDomain.ErrInvalidSchedule)
+		}
+	}
+	for _, value := range []string{hotel.CheckInTime, hotel.BookingCutoff} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(hotelsDomain.TimeOfDayLayout, value); err != nil {
+			return fmt.Errorf("time %q must be HH:MM: %w", value, hotelsDomain.ErrInvalidSchedule)
+		}
+	}
+	if hotel.MaxStayNights < 0 || hotel.MaxStayNights > pricingDomain.MaxStayNights {
+		return fmt.Errorf("max_stay_nights must be between 0 and %d: %w", pricingDomain.MaxStayNights, hotelsDomain.ErrInvalidSchedule)
+	}
+	return nil
+}
+
+// validateClear acepta solo los campos que se pueden borrar y rechaza borrar un campo que la misma
+// actualización también escribe.
+func validateClear(hotel hotelsDomain.Hotel) error {
+	for _, field := range hotel.Clear {
+		var set bool
+		switch field {
+		case hotelsDomain.FieldPolicy:
+			set = hotel.Policy != nil
+		case hotelsDomain.FieldMaxStayNights:
+			set = hotel.MaxStayNights != 0
+		case hotelsDomain.FieldTaxPercent:
+			set = hotel.TaxPercent != 0
+		default:
+			return fmt.Errorf("%q cannot be cleared, only %s: %w", field, strings.Join(hotelsDomain.ClearableFields, ", "), hotelsDomain.ErrInvalidClear)
+		}
+		if set {
+			return fmt.Errorf("%s cannot be set and cleared at once: %w", field, hotelsDomain.ErrInvalidClear)
+		}
+	}
+	return nil
+}
+
+func policyToDomain(policy *hotelsDAO.CancellationPolicy) *hotelsDomain.CancellationPolicy {
+	if policy == nil {
+		return nil
+	}
+	tiers := make([]hotelsDomain.PenaltyTier, 0, len(policy.Tiers))
+	for _, tier := range policy.Tiers {
+		tiers = append(tiers, hotelsDomain.PenaltyTier{
+			HoursBeforeCheckIn: tier.HoursBeforeCheckIn,
+			PenaltyPercent:     tier.PenaltyPercent,
+		})
+	}
+	return &hotelsDomain.CancellationPolicy{
+		Description:    policy.Description,
+		NonRefundable:  policy.NonRefundable,
+		Tiers:          tiers,
+		DepositPercent: policy.DepositPercent,
+	}
+}
+
+func policyToDAO(policy *hotelsDomain.CancellationPolicy) *hotelsDAO.CancellationPolicy {
+	if policy == nil {
+		return nil
+	}
+	tiers := make([]hotelsDAO.PenaltyTier, 0, len(policy.Tiers))
+	for _, tier := range policy.Tiers {
+		tiers = append(tiers, hotelsDAO.PenaltyTier{
+			HoursBeforeCheckIn: tier.HoursBeforeCheckIn,
+			PenaltyPercent:     tier.PenaltyPercent,
+		})
+	}
+	return &hotelsDAO.CancellationPolicy{
+		Description:    policy.Description,
+		NonRefundable:  policy.NonRefundable,
+		Tiers:          tiers,
+		DepositPercent: policy.DepositPercent,
+	}
+}
@@ -1 +1,178 @@
 package hotels
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hotels-api/clients/queues"
+	hotelsDAO "hotels-api/dao/hotels"
+	hotelsDomain "hotels-api/domain/hotels"
+	repositoriesHotels "hotels-api/repositories/hotels"
+)
+
+func newService() Service {
+	cache := repositoriesHotels.NewCache(repositoriesHotels.CacheConfig{
+		MaxSize:      1000,
+		ItemsToPrune: 10,
+		Duration:     time.Minute,
+	})
+	return NewService(repositoriesHotels.NewMock(), cache, queues.NewMock())
+}
+
+func newListService(t *testing.T) Service {
+	t.Helper()
+	repository := repositoriesHotels.NewMock()
+	hotels := []hotelsDAO.Hotel{
+		{Name: "Hotel Sierras", City: "Córdoba", State: "Córdoba", Rating: 4.5, Amenities: []string{"Pileta", "WiFi"}},
+		{Name: "Amerian", City: "córdoba", State: "Córdoba", Rating: 3.8, Amenities: []string{"wifi"}},
+		{Name: "Patios de San Telmo", City: "Buenos Aires", State: "CABA", Rating: 4.9, Amenities: []string{"WiFi", "Spa"}},
+		{Name: "Dinosaurio", City: "Córdoba", State: "Córdoba", Rating: 4.1, Amenities: []string{"Pileta"}},
+	}
+	for _, hotel := range hotels {
+		if _, err := repository.Create(context.Background(), hotel); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache := repositoriesHotels.NewCache(repositoriesHotels.CacheConfig{
+		MaxSize:      1000,
+		ItemsToPrune: 10,
+		Duration:     time.Minute,
+	})
+	return NewService(repository, cache, queues.NewMock())
+}
+
+func names(result hotelsDomain.ListResult) []string {
+	names := make([]string, 0, len(result.Results))
+	for _, hotel := range result.Results {
+		names = append(names, hotel.Name)
+	}
+	return names
+}
+
+func TestListFiltersSortsAndPaginates(t *testing.T) {
+	service := newListService(t)
+	ctx := context.Background()
+
+	result, err := service.List(ctx, hotelsDomain.ListFilter{City: " CÓRDOBA ", Amenities: []string{"wifi"}, SortBy: "rating", SortDesc: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(result); result.Total != 2 || len(got) != 2 || got[0] != "Hotel Sierras" || got[1] != "Amerian" {
+		t.Fatalf("expected Hotel Sierras and Amerian by rating, got %v (total %d)", got, result.Total)
+	}
+	if result.Limit != hotelsDomain.DefaultListLimit {
+		t.Fatalf("expected the default limit, got %d", result.Limit)
+	}
+
+	result, err = service.List(ctx, hotelsDomain.ListFilter{MinRating: 4, Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Por nombre: Dinosaurio, Hotel Sierras, Patios de San Telmo
+	if got := names(result); result.Total != 3 || len(got) != 2 || got[0] != "Hotel Sierras" || got[1] != "Patios de San Telmo" {
+		t.Fatalf("expected the second page by name, got %v (total %d)", got, result.Total)
+	}
+}
+
+func TestListRejectsInvalidFilters(t *testing.T) {
+	service := newListService(t)
+	filters := []hotelsDomain.ListFilter{
+		{MinRating: 6},
+		{Limit: hotelsDomain.MaxListLimit + 1},
+		{Offset: -1},
+		{SortBy: "address"},
+	}
+	for _, filter := range filters {
+		if _, err := service.List(context.Background(), filter); !errors.Is(err, hotelsDomain.ErrInvalidFilter) {
+			t.Fatalf("expected ErrInvalidFilter for %+v, got %v", filter, err)
+		}
+	}
+}
+
+func TestCreateValidatesCancellationPolicy(t *testing.T) {
+	service := newService()
+	ctx := context.Background()
+
+	invalid := []hotelsDomain.CancellationPolicy{
+		{DepositPercent: -10},
+		{DepositPercent: 120},
+		{Tiers: []hotelsDomain.PenaltyTier{{HoursBeforeCheckIn: 0, PenaltyPercent: 50}}},
+		{Tiers: []hotelsDomain.PenaltyTier{{HoursBeforeCheckIn: 48, PenaltyPercent: -5}}},
+		{Tiers: []hotelsDomain.PenaltyTier{{HoursBeforeCheckIn: 48, PenaltyPercent: 150}}},
+	}
+	for _, policy := range invalid {
+		if _, err := service.Create(ctx, hotelsDomain.Hotel{Name: "Hotel Sierras", Policy: &policy}); !errors.Is(err, hotelsDomain.ErrInvalidPolicy) {
+			t.Fatalf("expected ErrInvalidPolicy for %+v, got %v", policy, err)
+		}
+	}
+
+	id, err := service.Create(ctx, hotelsDomain.Hotel{Name: "Hotel Sierras", Policy: &hotelsDomain.CancellationPolicy{
+		Description:    "Sin cargo hasta 48 horas antes",
+		DepositPercent: 30,
+		Tiers:          []hotelsDomain.PenaltyTier{{HoursBeforeCheckIn: 48, PenaltyPercent: 50}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hotel, err := service.GetHotelByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hotel.Policy == nil || hotel.Policy.DepositPercent != 30 || len(hotel.Policy.Tiers) != 1 || hotel.Policy.Tiers[0].PenaltyPercent != 50 {
+		t.Fatalf("expected the policy to be stored, got %+v", hotel.Policy)
+	}
+}
+
+func TestUpdateClearsFields(t *testing.T) {
+	service := newService()
+	ctx := context.Background()
+
+	id, err := service.Create(ctx, hotelsDomain.Hotel{
+		Name:          "Hotel Sierras",
+		Policy:        &hotelsDomain.CancellationPolicy{DepositPercent: 30},
+		MaxStayNights: 10,
+		TaxPercent:    21,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := []hotelsDomain.Hotel{
+		{ID: id, Clear: []string{"name"}},
+		{ID: id, TaxPercent: 10, Clear: []string{hotelsDomain.FieldTaxPercent}},
+		{ID: id, Policy: &hotelsDomain.CancellationPolicy{}, Clear: []string{hotelsDomain.FieldPolicy}},
+	}
+	for _, hotel := range invalid {
+		if err := service.Update(ctx, hotel); !errors.Is(err, hotelsDomain.ErrInvalidClear) {
+			t.Fatalf("expected ErrInvalidClear for %+v, got %v", hotel, err)
+		}
+	}
+
+	// Sin Clear los valores en cero no pisan lo guardado
+	if err := service.Update(ctx, hotelsDomain.Hotel{ID: id, City: "Córdoba"}); err != nil {
+		t.Fatal(err)
+	}
+	if hotel, _ := service.GetHotelByID(ctx, id); hotel.Policy == nil || hotel.MaxStayNights != 10 || hotel.TaxPercent != 21 {
+		t.Fatalf("expected the fields to be kept, got %+v", hotel)
+	}
+
+	if err := service.Update(ctx, hotelsDomain.Hotel{ID: id, Clear: hotelsDomain.ClearableFields}); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := service.GetHotelByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := service.mainRepository.GetHotelByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.Policy != nil || cached.MaxStayNights != 0 || cached.TaxPercent != 0 || cached.City != "Córdoba" {
+		t.Fatalf("expected the cached hotel to be cleared, got %+v", cached)
+	}
+	if stored.Policy != nil || stored.MaxStayNights != 0 || stored.TaxPercent != 0 || stored.City != "Córdoba" {
+		t.Fatalf("expected the stored hotel to be cleared, got %+v", stored)
+	}
+}
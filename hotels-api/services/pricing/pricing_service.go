@@ -0,0 +1,184 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	roomsDAO "hotels-api/dao/rooms"
+	pricingDomain "hotels-api/domain/pricing"
+	"hotels-api/domain/promotions"
+	"hotels-api/domain/reservations"
+	"math"
+	"strings"
+	"time"
+)
+
+type RoomsRepository interface {
+	GetByHotelID(ctx context.Context, hotelID string) ([]roomsDAO.RoomType, error)
+	GetByID(ctx context.Context, id string) (roomsDAO.RoomType, error)
+}
+
+type PromotionsRepository interface {
+	GetByCode(ctx context.Context, code string) (promotions.Promotion, error)
+}
+
+type Service struct {
+	roomsRepository      RoomsRepository
+	promotionsRepository PromotionsRepository
+}
+
+func NewService(roomsRepository RoomsRepository, promotionsRepository PromotionsRepository) Service {
+	return Service{
+		roomsRepository:      roomsRepository,
+		promotionsRepository: promotionsRepository,
+	}
+}
+
+// Quote cotiza la estadía para el tipo de habitación indicado o, si no se indica,
+// para todos los tipos del hotel con tarifa y capacidad suficiente para los huéspedes.
+// Con promoCode aplica el código; el límite de usos por usuario recién se verifica al reservar.
+func (service Service) Quote(ctx context.Context, hotelID string, roomTypeID string, startDate string, endDate string, guests int, promoCode string) ([]pricingDomain.Quote, error) {
+	startDay, err := reservations.ParseDate(startDate)
+	if err != nil {
+		return nil, fmt.Errorf("start %q: %w", startDate, pricingDomain.ErrInvalidQuote)
+	}
+	endDay, err := reservations.ParseDate(endDate)
+	if err != nil {
+		return nil, fmt.Errorf("end %q: %w", endDate, pricingDomain.ErrInvalidQuote)
+	}
+	start, end := startDay.Time, endDay.Time
+	if !end.After(start) {
+		return nil, fmt.Errorf("end must be after start: %w", pricingDomain.ErrInvalidQuote)
+	}
+	if nights := startDay.NightsUntil(endDay); nights > pricingDomain.MaxStayNights {
+		return nil, fmt.Errorf("stay of %d nights exceeds the maximum of %d: %w", nights, pricingDomain.MaxStayNights, pricingDomain.ErrInvalidQuote)
+	}
+	if guests < 1 {
+		return nil, fmt.Errorf("guests must be at least 1: %w", pricingDomain.ErrInvalidQuote)
+	}
+	var promotion *promotions.Promotion
+	if promoCode = strings.ToUpper(strings.TrimSpace(promoCode)); promoCode != "" {
+		found, err := service.promotionsRepository.GetByCode(ctx, promoCode)
+		if err != nil {
+			return nil, err
+		}
+		if !found.IsValidAt(time.Now().UTC()) {
+			return nil, fmt.Errorf("code %s is not valid today: %w", found.Code, promotions.ErrNotApplicable)
+		}
+		if err := found.Fits(hotelID, startDay, endDay); err != nil {
+			return nil, err
+		}
+		promotion = &found
+	}
+
+	if roomTypeID != "" {
+		roomType, err := service.roomsRepository.GetByID(ctx, roomTypeID)
+		if err != nil || roomType.HotelID != hotelID {
+			return nil, fmt.Errorf("room type %q not found in hotel: %w", roomTypeID, pricingDomain.ErrInvalidQuote)
+		}
+		if roomType.Capacity < guests {
+			return nil, fmt.Errorf("room type %s holds up to %d guests: %w", roomType.Name, roomType.Capacity, pricingDomain.ErrInvalidQuote)
+		}
+		quote, err := Calculate(roomType, start, end)
+		if err != nil {
+			return nil, err
+		}
+		quote.Guests = guests
+		if err := applyPromotion(&quote, promotion); err != nil {
+			return nil, err
+		}
+		return []pricingDomain.Quote{quote}, nil
+	}
+
+	roomTypes, err := service.roomsRepository.GetByHotelID(ctx, hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting room types: %w", err)
+	}
+	quotes := make([]pricingDomain.Quote, 0, len(roomTypes))
+	for _, roomType := range roomTypes {
+		if roomType.RatePlan == nil || roomType.Capacity < guests {
+			continue
+		}
+		quote, err := Calculate(roomType, start, end)
+		if err != nil {
+			return nil, err
+		}
+		quote.Guests = guests
+		// Un voucher en otra moneda no aplica a este tipo de habitación, que se cotiza sin él
+		if err := applyPromotion(&quote, promotion); err != nil && !errors.Is(err, promotions.ErrNotApplicable) {
+			return nil, err
+		}
+		quotes = append(quotes, quote)
+	}
+	return quotes, nil
+}
+
+// Calculate arma el desglose por noche de la estadía [start, end) con el plan de tarifas del tipo de habitación.
+func Calculate(roomType roomsDAO.RoomType, start time.Time, end time.Time) (pricingDomain.Quote, error) {
+	plan := roomType.RatePlan
+	if plan == nil {
+		return pricingDomain.Quote{}, fmt.Errorf("room type %s: %w", roomType.Name, pricingDomain.ErrNoRatePlan)
+	}
+	if nights := end.Sub(start).Hours() / 24; nights > pricingDomain.MaxStayNights {
+		return pricingDomain.Quote{}, fmt.Errorf("stay of %.0f nights exceeds the maximum of %d: %w", nights, pricingDomain.MaxStayNights, pricingDomain.ErrInvalidQuote)
+	}
+
+	quote := pricingDomain.Quote{
+		HotelID:      roomType.HotelID,
+		RoomTypeID:   roomType.ID.Hex(),
+		RoomTypeName: roomType.Name,
+		StartDate:    start.Format(reservations.DateLayout),
+		EndDate:      end.Format(reservations.DateLayout),
+		Currency:     plan.Currency,
+		Nights:       make([]pricingDomain.NightPrice, 0),
+	}
+	for night := start; night.Before(end); night = night.AddDate(0, 0, 1) {
+		date := night.Format(reservations.DateLayout)
+		price := pricingDomain.NightPrice{Date: date, Rate: plan.BaseRate}
+		for _, season := range plan.Seasons {
+			// Las fechas ISO se pueden comparar como texto
+			if season.StartDate <= date && date <= season.EndDate {
+				price.Season = season.Name
+				price.Rate = season.Rate
+				break
+			}
+		}
+		if weekday := night.Weekday(); weekday == time.Friday || weekday == time.Saturday {
+			price.Surcharge = roundCents(price.Rate * plan.WeekendSurchargePercent / 100)
+		}
+		price.Price = roundCents(price.Rate + price.Surcharge)
+		quote.Nights = append(quote.Nights, price)
+		quote.Subtotal += price.Price
+	}
+	quote.Subtotal = roundCents(quote.Subtotal)
+
+	minNights := 0
+	for _, discount := range plan.LengthOfStayDiscounts {
+		if len(quote.Nights) >= discount.MinNights && discount.MinNights > minNights {
+			minNights = discount.MinNights
+			quote.DiscountPercent = discount.DiscountPercent
+		}
+	}
+	quote.Discount = roundCents(quote.Subtotal * quote.DiscountPercent / 100)
+	quote.Total = roundCents(quote.Subtotal - quote.Discount)
+	return quote, nil
+}
+
+// applyPromotion descuenta el código del total de la cotización.
+func applyPromotion(quote *pricingDomain.Quote, promotion *promotions.Promotion) error {
+	if promotion == nil {
+		return nil
+	}
+	discount, err := promotion.DiscountFor(quote.Total, quote.Currency)
+	if err != nil {
+		return err
+	}
+	quote.PromoCode = promotion.Code
+	quote.PromoDiscount = discount
+	quote.Total = roundCents(quote.Total - discount)
+	return nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,97 @@
+package pricing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	roomsDAO "hotels-api/dao/rooms"
+	pricingDomain "hotels-api/domain/pricing"
+	repositoriesPromotions "hotels-api/repositories/promotions"
+	repositoriesRooms "hotels-api/repositories/rooms"
+	"hotels-api/services/pricing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func date(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestCalculate(t *testing.T) {
+	roomType := roomsDAO.RoomType{
+		ID:       primitive.NewObjectID(),
+		HotelID:  "hotel-1",
+		Name:     "Doble",
+		Capacity: 2,
+		RatePlan: &roomsDAO.RatePlan{
+			Currency:                "ARS",
+			BaseRate:                100,
+			WeekendSurchargePercent: 20,
+			Seasons: []roomsDAO.Season{
+				{Name: "Alta", StartDate: "2024-12-20", EndDate: "2024-12-31", Rate: 150},
+			},
+			LengthOfStayDiscounts: []roomsDAO.StayDiscount{
+				{MinNights: 3, DiscountPercent: 10},
+				{MinNights: 7, DiscountPercent: 15},
+			},
+		},
+	}
+
+	t.Run("Weekday nights use base rate", func(t *testing.T) {
+		// Lunes 2 y martes 3 de diciembre de 2024
+		quote, err := pricing.Calculate(roomType, date(t, "2024-12-02"), date(t, "2024-12-04"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(quote.Nights) != 2 || quote.Total != 200 || quote.Discount != 0 {
+			t.Fatalf("unexpected quote: %+v", quote)
+		}
+	})
+
+	t.Run("Weekend surcharge, season and length of stay discount", func(t *testing.T) {
+		// Jueves 19 a domingo 22: jueves base, viernes y sábado de temporada alta con recargo
+		quote, err := pricing.Calculate(roomType, date(t, "2024-12-19"), date(t, "2024-12-22"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedNights := []float64{100, 180, 180}
+		for i, night := range quote.Nights {
+			if night.Price != expectedNights[i] {
+				t.Fatalf("night %s: expected %v, got %v", night.Date, expectedNights[i], night.Price)
+			}
+		}
+		if quote.Subtotal != 460 || quote.DiscountPercent != 10 || quote.Discount != 46 || quote.Total != 414 {
+			t.Fatalf("unexpected totals: %+v", quote)
+		}
+	})
+
+	t.Run("Room type without rate plan", func(t *testing.T) {
+		_, err := pricing.Calculate(roomsDAO.RoomType{Name: "Suite"}, date(t, "2024-12-02"), date(t, "2024-12-03"))
+		if !errors.Is(err, pricingDomain.ErrNoRatePlan) {
+			t.Fatalf("expected ErrNoRatePlan, got %v", err)
+		}
+	})
+
+	t.Run("Stay longer than the maximum", func(t *testing.T) {
+		_, err := pricing.Calculate(roomType, date(t, "2024-12-02"), date(t, "9999-12-31"))
+		if !errors.Is(err, pricingDomain.ErrInvalidQuote) {
+			t.Fatalf("expected ErrInvalidQuote, got %v", err)
+		}
+	})
+}
+
+func TestQuoteRejectsStaysLongerThanTheMaximum(t *testing.T) {
+	service := pricing.NewService(repositoriesRooms.NewMock(), repositoriesPromotions.NewMock())
+
+	_, err := service.Quote(context.Background(), "hotel-1", "", "2024-12-02", "9999-12-31", 2, "")
+	if !errors.Is(err, pricingDomain.ErrInvalidQuote) {
+		t.Fatalf("expected ErrInvalidQuote, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package promotions
+
+import (
+	"context"
+	"fmt"
+	hotelsDAO "hotels-api/dao/hotels"
+	"hotels-api/domain/promotions"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, promotion promotions.Promotion) (string, error)
+	GetByID(ctx context.Context, id string) (promotions.Promotion, error)
+	GetAll(ctx context.Context) ([]promotions.Promotion, error)
+}
+
+type HotelsRepository interface {
+	GetHotelByID(ctx context.Context, id string) (hotelsDAO.Hotel, error)
+}
+
+type Service struct {
+	repository       Repository
+	hotelsRepository HotelsRepository
+}
+
+func NewService(repository Repository, hotelsRepository HotelsRepository) Service {
+	return Service{
+		repository:       repository,
+		hotelsRepository: hotelsRepository,
+	}
+}
+
+// codePattern son los códigos que se pueden dictar y tipear sin ambigüedad
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// Create valida y guarda un código nuevo. El código se guarda en mayúsculas y se busca igual.
+func (s Service) Create(ctx context.Context, promotion promotions.Promotion, now time.Time) (promotions.Promotion, error) {
+	promotion.Code = strings.ToUpper(strings.TrimSpace(promotion.Code))
+	promotion.Name = strings.TrimSpace(promotion.Name)
+	promotion.Currency = strings.ToUpper(strings.TrimSpace(promotion.Currency))
+	if err := validate(promotion); err != nil {
+		return promotions.Promotion{}, err
+	}
+	for _, hotelID := range promotion.HotelIDs {
+		if _, err := s.hotelsRepository.GetHotelByID(ctx, hotelID); err != nil {
+			return promotions.Promotion{}, fmt.Errorf("hotel %q: %v: %w", hotelID, err, promotions.ErrInvalidPromotion)
+		}
+	}
+
+	promotion.ID = ""
+	promotion.Redemptions = 0
+	promotion.CreatedAt = now
+	id, err := s.repository.Create(ctx, promotion)
+	if err != nil {
+		return promotions.Promotion{}, err
+	}
+
+	promotion.ID = id
+	return promotion, nil
+}
+
+func (s Service) GetAll(ctx context.Context) ([]promotions.Promotion, error) {
+	return s.repository.GetAll(ctx)
+}
+
+func (s Service) GetByID(ctx context.Context, id string) (promotions.Promotion, error) {
+	return s.repository.GetByID(ctx, id)
+}
+
+func validate(promotion promotions.Promotion) error {
+	if !codePattern.MatchString(promotion.Code) {
+		return fmt.Errorf("code must be 3 to 32 letters, digits, '-' or '_': %w", promotions.ErrInvalidPromotion)
+	}
+	percent, amount := promotion.DiscountPercent, promotion.DiscountAmount
+	switch {
+	case percent < 0 || percent > 100 || amount < 0:
+		return fmt.Errorf("discount_percent must be between 0 and 100 and discount_amount positive: %w", promotions.ErrInvalidPromotion)
+	case (percent > 0) == (amount > 0):
+		return fmt.Errorf("either discount_percent or discount_amount is required, not both: %w", promotions.ErrInvalidPromotion)
+	case amount > 0 && promotion.Currency == "":
+		return fmt.Errorf("a discount_amount needs its currency: %w", promotions.ErrInvalidPromotion)
+	}
+	if promotion.ValidFrom.IsZero() || !promotion.ValidUntil.After(promotion.ValidFrom) {
+		return fmt.Errorf("valid_until must be after valid_from: %w", promotions.ErrInvalidPromotion)
+	}
+	if promotion.StayFrom.IsZero() != promotion.StayUntil.IsZero() || !promotion.StayFrom.IsZero() && !promotion.StayUntil.After(promotion.StayFrom.Time) {
+		return fmt.Errorf("stay_from and stay_until go together and stay_until must be after stay_from: %w", promotions.ErrInvalidPromotion)
+	}
+	if promotion.MinNights < 0 || promotion.MaxRedemptions < 0 || promotion.MaxPerUser < 0 {
+		return fmt.Errorf("min_nights, max_redemptions and max_per_user cannot be negative: %w", promotions.ErrInvalidPromotion)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package promotions_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hotelsDAO "hotels-api/dao/hotels"
+	"hotels-api/domain/promotions"
+	"hotels-api/domain/reservations"
+	repositoriesPromotions "hotels-api/repositories/promotions"
+	service "hotels-api/services/promotions"
+)
+
+// knownHotels responde solo por los hoteles cargados, como el repositorio de Mongo.
+type knownHotels map[string]bool
+
+func (h knownHotels) GetHotelByID(ctx context.Context, id string) (hotelsDAO.Hotel, error) {
+	if !h[id] {
+		return hotelsDAO.Hotel{}, errors.New("hotel not found")
+	}
+	return hotelsDAO.Hotel{Name: id}, nil
+}
+
+func validPromotion(now time.Time) promotions.Promotion {
+	return promotions.Promotion{
+		Code:            "VERANO",
+		Name:            "Verano",
+		DiscountPercent: 10,
+		ValidFrom:       now,
+		ValidUntil:      now.AddDate(0, 1, 0),
+	}
+}
+
+func TestCreateNormalizesAndStoresPromotion(t *testing.T) {
+	s := service.NewService(repositoriesPromotions.NewMock(), knownHotels{"sierras": true})
+	ctx := context.Background()
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	promotion := validPromotion(now)
+	promotion.Code = " verano-26 "
+	promotion.Name = "  Verano 2026 "
+	promotion.DiscountPercent = 0
+	promotion.DiscountAmount = 5000
+	promotion.Currency = "ars"
+	promotion.HotelIDs = []string{"sierras"}
+	promotion.ID = "elegido-por-el-cliente"
+	promotion.Redemptions = 40
+
+	created, err := s.Create(ctx, promotion, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Code != "VERANO-26" || created.Name != "Verano 2026" || created.Currency != "ARS" {
+		t.Fatalf("expected code, name and currency normalized, got %+v", created)
+	}
+	if created.ID == "" || created.ID == "elegido-por-el-cliente" || created.Redemptions != 0 || !created.CreatedAt.Equal(now) {
+		t.Fatalf("expected a new ID, no redemptions and the creation time, got %+v", created)
+	}
+
+	stored, err := s.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Code != "VERANO-26" || stored.DiscountAmount != 5000 || stored.Redemptions != 0 {
+		t.Fatalf("unexpected stored promotion: %+v", stored)
+	}
+	if all, err := s.GetAll(ctx); err != nil || len(all) != 1 {
+		t.Fatalf("expected one promotion, got %d (%v)", len(all), err)
+	}
+
+	// El código se compara ya normalizado
+	duplicate := validPromotion(now)
+	duplicate.Code = "Verano-26"
+	if _, err := s.Create(ctx, duplicate, now); !errors.Is(err, promotions.ErrInvalidPromotion) {
+		t.Fatalf("expected ErrInvalidPromotion for a repeated code, got %v", err)
+	}
+	if _, err := s.GetByID(ctx, "otra"); !errors.Is(err, promotions.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCreateValidatesPromotion(t *testing.T) {
+	s := service.NewService(repositoriesPromotions.NewMock(), knownHotels{"sierras": true})
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	stayFrom := reservations.DateOf(now.AddDate(0, 2, 0))
+
+	tests := []struct {
+		name   string
+		modify func(*promotions.Promotion)
+	}{
+		{name: "short code", modify: func(p *promotions.Promotion) { p.Code = "AB" }},
+		{name: "code with spaces", modify: func(p *promotions.Promotion) { p.Code = "VERANO 26" }},
+		{name: "percent above 100", modify: func(p *promotions.Promotion) { p.DiscountPercent = 120 }},
+		{name: "no discount", modify: func(p *promotions.Promotion) { p.DiscountPercent = 0 }},
+		{name: "percent and amount", modify: func(p *promotions.Promotion) { p.DiscountAmount = 1000; p.Currency = "ARS" }},
+		{name: "amount without currency", modify: func(p *promotions.Promotion) { p.DiscountPercent = 0; p.DiscountAmount = 1000 }},
+		{name: "no validity start", modify: func(p *promotions.Promotion) { p.ValidFrom = time.Time{} }},
+		{name: "validity ends before it starts", modify: func(p *promotions.Promotion) { p.ValidUntil = p.ValidFrom.Add(-time.Hour) }},
+		{name: "stay from without stay until", modify: func(p *promotions.Promotion) { p.StayFrom = stayFrom }},
+		{name: "inverted stay window", modify: func(p *promotions.Promotion) { p.StayFrom, p.StayUntil = stayFrom, stayFrom.AddDays(-1) }},
+		{name: "negative min nights", modify: func(p *promotions.Promotion) { p.MinNights = -1 }},
+		{name: "negative max per user", modify: func(p *promotions.Promotion) { p.MaxPerUser = -1 }},
+		{name: "unknown hotel", modify: func(p *promotions.Promotion) { p.HotelIDs = []string{"sierras", "inexistente"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promotion := validPromotion(now)
+			tt.modify(&promotion)
+			if _, err := s.Create(context.Background(), promotion, now); !errors.Is(err, promotions.ErrInvalidPromotion) {
+				t.Fatalf("expected ErrInvalidPromotion, got %v", err)
+			}
+		})
+	}
+	if all, _ := s.GetAll(context.Background()); len(all) != 0 {
+		t.Fatalf("expected no promotion stored, got %+v", all)
+	}
+}
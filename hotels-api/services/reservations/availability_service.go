@@ -0,0 +1,93 @@
+package reservations
+
+import (
+	"context"
+	"fmt"
+	"hotels-api/domain/reservations"
+	"hotels-api/services/pricing"
+	"log"
+)
+
+// Availability arma el calendario del hotel para [from, to): por cada tipo de habitación con
+// tarifa, las unidades libres y el precio de cada noche. El resultado se cachea por hotel y se
+// descarta cada vez que una reserva toma o libera inventario. El cache es local a cada instancia,
+// así que otra réplica puede mostrar un calendario viejo hasta que le vence; es solo informativo,
+// la reserva siempre se valida contra el inventario.
+func (s Service) Availability(ctx context.Context, hotelID string, roomTypeID string, from, to reservations.Date) (reservations.Availability, error) {
+	if from.IsZero() || to.IsZero() || !to.After(from.Time) {
+		return reservations.Availability{}, fmt.Errorf("from and to are required and to must be after from: %w", reservations.ErrInvalidDates)
+	}
+	if nights := from.NightsUntil(to); nights > reservations.MaxAvailabilityNights {
+		return reservations.Availability{}, fmt.Errorf("range of %d nights exceeds the maximum of %d: %w", nights, reservations.MaxAvailabilityNights, reservations.ErrInvalidDates)
+	}
+
+	if cached, err := s.availabilityCache.GetAvailability(ctx, hotelID, roomTypeID, from, to); err == nil {
+		return cached, nil
+	}
+
+	roomTypes, err := s.roomsRepository.GetByHotelID(ctx, hotelID)
+	if err != nil {
+		return reservations.Availability{}, fmt.Errorf("error getting room types: %w", err)
+	}
+	ids := make([]string, 0, len(roomTypes))
+	for _, roomType := range roomTypes {
+		ids = append(ids, roomType.ID.Hex())
+	}
+	booked, err := s.repository.GetBooked(ctx, ids, from, to)
+	if err != nil {
+		return reservations.Availability{}, err
+	}
+
+	availability := reservations.Availability{
+		HotelID:   hotelID,
+		From:      from,
+		To:        to,
+		RoomTypes: make([]reservations.RoomTypeAvailability, 0, len(roomTypes)),
+	}
+	for _, roomType := range roomTypes {
+		if roomTypeID != "" && roomType.ID.Hex() != roomTypeID {
+			continue
+		}
+		// Sin tarifa el tipo de habitación no se puede reservar
+		if roomType.RatePlan == nil {
+			continue
+		}
+		quote, err := pricing.Calculate(roomType, from.Time, to.Time)
+		if err != nil {
+			return reservations.Availability{}, fmt.Errorf("error pricing room type %s: %w", roomType.Name, err)
+		}
+
+		calendar := reservations.RoomTypeAvailability{
+			RoomTypeID: roomType.ID.Hex(),
+			Name:       roomType.Name,
+			Capacity:   roomType.Capacity,
+			Currency:   quote.Currency,
+			Nights:     make([]reservations.NightAvailability, 0, len(quote.Nights)),
+		}
+		for i, night := range quote.Nights {
+			date := from.AddDays(i)
+			remaining := roomType.Count - booked[calendar.RoomTypeID][date.String()]
+			if remaining < 0 {
+				remaining = 0
+			}
+			calendar.Nights = append(calendar.Nights, reservations.NightAvailability{
+				Date:      date,
+				Remaining: remaining,
+				Price:     night.Price,
+			})
+		}
+		availability.RoomTypes = append(availability.RoomTypes, calendar)
+	}
+
+	if err := s.availabilityCache.SetAvailability(ctx, roomTypeID, availability); err != nil {
+		log.Printf("error caching availability for hotel %s: %v", hotelID, err)
+	}
+	return availability, nil
+}
+
+// invalidateAvailability descarta los calendarios cacheados del hotel después de un cambio de inventario.
+func (s Service) invalidateAvailability(ctx context.Context, hotelID string) {
+	if err := s.availabilityCache.DeleteAvailability(ctx, hotelID); err != nil {
+		log.Printf("error invalidating availability for hotel %s: %v", hotelID, err)
+	}
+}
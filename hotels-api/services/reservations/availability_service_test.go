@@ -0,0 +1,41 @@
+package reservations_test
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAvailabilityReflectsBookings(t *testing.T) {
+	f := newFixture(t, 2)
+	ctx := context.Background()
+	from, to := f.start, f.start.AddDays(3)
+
+	before, err := f.service.Availability(ctx, f.hotelID, "", from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before.RoomTypes) != 1 || len(before.RoomTypes[0].Nights) != 3 {
+		t.Fatalf("expected 3 nights for 1 room type, got %+v", before)
+	}
+	for _, night := range before.RoomTypes[0].Nights {
+		if night.Remaining != 2 || night.Price != 100 {
+			t.Fatalf("unexpected night before booking: %+v", night)
+		}
+	}
+
+	// La reserva invalida el calendario cacheado
+	if _, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start.AddDays(1), 1)); err != nil {
+		t.Fatal(err)
+	}
+	after, err := f.service.Availability(ctx, f.hotelID, "", from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remaining := []int{}
+	for _, night := range after.RoomTypes[0].Nights {
+		remaining = append(remaining, night.Remaining)
+	}
+	if remaining[0] != 2 || remaining[1] != 1 || remaining[2] != 2 {
+		t.Fatalf("expected remaining [2 1 2], got %v", remaining)
+	}
+}
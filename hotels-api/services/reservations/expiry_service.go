@@ -0,0 +1,40 @@
+package reservations
+
+import (
+	"context"
+	"errors"
+	"hotels-api/domain/reservations"
+	"time"
+)
+
+// expiryBatchSize acota cuántas reservas vencidas se cargan por consulta.
+const expiryBatchSize = 100
+
+// ExpirePending cancela las reservas pendientes que nadie confirmó ni pagó: las creadas hace más
+// de ttl y las ofertas de la lista de espera cuyo plazo venció. Cada una libera su inventario,
+// publica la cancelación y ofrece sus noches a la lista de espera. La cancelación solo se aplica
+// si la reserva sigue pendiente, así que dos instancias no pueden vencer la misma. Devuelve
+// cuántas reservas venció.
+func (s Service) ExpirePending(ctx context.Context, now time.Time, ttl time.Duration) (int, error) {
+	expired := 0
+	for {
+		batch, err := s.repository.GetExpired(ctx, now, now.Add(-ttl), expiryBatchSize)
+		if err != nil {
+			return expired, err
+		}
+		for _, reservation := range batch {
+			err := s.release(ctx, reservation, reservations.CancellationExpired, now)
+			if errors.Is(err, reservations.ErrInvalidTransition) {
+				// Se confirmó, pagó o canceló mientras tanto
+				continue
+			}
+			if err != nil {
+				return expired, err
+			}
+			expired++
+		}
+		if len(batch) < expiryBatchSize {
+			return expired, nil
+		}
+	}
+}
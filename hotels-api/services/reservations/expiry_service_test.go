@@ -0,0 +1,74 @@
+package reservations_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"hotels-api/domain/reservations"
+)
+
+func TestExpirePendingReleasesUnpaidReservations(t *testing.T) {
+	f := newFixture(t, 3)
+	ctx := context.Background()
+
+	unpaid, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmed, err := f.service.CreateReservation(ctx, f.reservation("user-2", f.start, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Transition(ctx, confirmed, reservations.StatusConfirmed); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().UTC().Add(time.Hour)
+	recent := f.reservation("user-3", f.start, 2)
+	recent.Status = reservations.StatusPending
+	recent.CreatedAt = later
+	if _, err := f.repository.Book(ctx, recent, map[string]int{f.roomTypeID: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Dos instancias a la vez vencen cada reserva una sola vez
+	var wg sync.WaitGroup
+	counts := make([]int, 2)
+	for i := range counts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			expired, err := f.service.ExpirePending(ctx, later.Add(time.Minute), 30*time.Minute)
+			if err != nil {
+				t.Error(err)
+			}
+			counts[i] = expired
+		}(i)
+	}
+	wg.Wait()
+
+	if counts[0]+counts[1] != 1 {
+		t.Fatalf("expected exactly one reservation expired, got %v", counts)
+	}
+	expired, _ := f.service.GetReservationByID(ctx, unpaid)
+	if expired.Status != reservations.StatusCancelled || expired.Cancellation == nil || expired.Cancellation.Reason != reservations.CancellationExpired {
+		t.Fatalf("expected the unpaid reservation cancelled as expired, got %+v", expired)
+	}
+	if count := f.repository.Booked(f.roomTypeID, f.start); count != 2 {
+		t.Fatalf("expected the expired room released, got %d rooms taken", count)
+	}
+
+	cancellations := 0
+	for _, event := range f.events.Events() {
+		if event.Type == reservations.EventCancelled {
+			cancellations++
+			if event.Reservation.ID != unpaid || event.Reservation.CancellationReason != reservations.CancellationExpired {
+				t.Fatalf("unexpected cancellation event %+v", event)
+			}
+		}
+	}
+	if cancellations != 1 {
+		t.Fatalf("expected one cancellation event, got %d", cancellations)
+	}
+}
@@ -0,0 +1,110 @@
+package reservations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/invoices"
+	"hotels-api/domain/payments"
+	"hotels-api/domain/reservations"
+	"slices"
+	"time"
+)
+
+// Invoice devuelve la factura de la reserva y la emite la primera vez que se pide. Una vez emitida
+// se devuelve siempre la misma, aunque después cambien la reserva, el hotel o los pagos.
+func (s Service) Invoice(ctx context.Context, id string) (invoices.Invoice, error) {
+	invoice, err := s.invoicesRepository.GetByReservationID(ctx, id)
+	if err == nil || !errors.Is(err, invoices.ErrNotFound) {
+		return invoice, err
+	}
+
+	reservation, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return invoices.Invoice{}, err
+	}
+	if !slices.Contains(invoices.InvoiceableStatuses, reservation.Status) {
+		return invoices.Invoice{}, fmt.Errorf("a %q reservation: %w", reservation.Status, invoices.ErrNotInvoiceable)
+	}
+	invoice, err = s.draftInvoice(ctx, reservation, time.Now().UTC())
+	if err != nil {
+		return invoices.Invoice{}, err
+	}
+
+	issued, err := s.invoicesRepository.Issue(ctx, invoice)
+	if err != nil {
+		return invoices.Invoice{}, fmt.Errorf("error issuing invoice: %w", err)
+	}
+	return issued, nil
+}
+
+// draftInvoice arma la factura con una línea por noche y tipo de habitación, el impuesto incluido
+// en el total y los cobros y reintegros aprobados hasta el momento.
+func (s Service) draftInvoice(ctx context.Context, reservation reservations.Reservation, now time.Time) (invoices.Invoice, error) {
+	hotel, err := s.hotelsRepository.GetHotelByID(ctx, reservation.HotelID)
+	if err != nil {
+		return invoices.Invoice{}, fmt.Errorf("error getting hotel: %w", err)
+	}
+
+	invoice := invoices.Invoice{
+		ReservationID:  reservation.ID,
+		IssuedAt:       now,
+		HotelID:        reservation.HotelID,
+		HotelName:      hotel.Name,
+		HotelAddress:   hotel.Address,
+		HotelTaxID:     hotel.TaxID,
+		UserID:         reservation.UserID,
+		StartDate:      reservation.StartDate,
+		EndDate:        reservation.EndDate,
+		Currency:       reservation.Currency,
+		Lines:          make([]invoices.Line, 0),
+		Discount:       reservation.Discount,
+		PromoCode:      reservation.PromoCode,
+		PromoDiscount:  reservation.PromoDiscount,
+		PointsRedeemed: reservation.PointsRedeemed,
+		PointsDiscount: reservation.PointsDiscount,
+		Total:          reservation.Total,
+		TaxPercent:     hotel.TaxPercent,
+		Payments:       make([]invoices.Payment, 0),
+	}
+	for _, item := range reservation.LineItems() {
+		// Si el tipo de habitación ya no existe la línea se identifica por su ID
+		description := item.RoomTypeID
+		if roomType, err := s.roomsRepository.GetByID(ctx, item.RoomTypeID); err == nil {
+			description = roomType.Name
+		}
+		for _, night := range item.Nights {
+			line := invoices.Line{
+				Description: description,
+				Date:        night.Date,
+				Quantity:    item.Quantity,
+				UnitPrice:   night.Price,
+				Amount:      roundCents(night.Price * float64(item.Quantity)),
+			}
+			invoice.Lines = append(invoice.Lines, line)
+			invoice.Subtotal += line.Amount
+		}
+	}
+	invoice.Subtotal = roundCents(invoice.Subtotal)
+	invoice.Net = roundCents(invoice.Total / (1 + invoice.TaxPercent/100))
+	invoice.Tax = roundCents(invoice.Total - invoice.Net)
+
+	payment, err := s.paymentsRepository.GetByReservationID(ctx, reservation.ID)
+	if err != nil && !errors.Is(err, payments.ErrNotFound) {
+		return invoices.Invoice{}, fmt.Errorf("error getting payment: %w", err)
+	}
+	for _, transaction := range payment.Transactions {
+		if !transaction.Approved {
+			continue
+		}
+		switch transaction.Operation {
+		case payments.OperationCapture:
+			invoice.Payments = append(invoice.Payments, invoices.Payment{Operation: transaction.Operation, Reference: transaction.Reference, Amount: transaction.Amount, At: transaction.At})
+		case payments.OperationRefund:
+			invoice.Payments = append(invoice.Payments, invoices.Payment{Operation: transaction.Operation, Reference: transaction.Reference, Amount: -transaction.Amount, At: transaction.At})
+		}
+	}
+	invoice.Paid = roundCents(payment.Captured - payment.Refunded)
+	invoice.BalanceDue = roundCents(invoice.Total - invoice.Paid)
+	return invoice, nil
+}
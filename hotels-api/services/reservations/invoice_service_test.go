@@ -0,0 +1,68 @@
+package reservations_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"hotels-api/domain/invoices"
+	"hotels-api/domain/reservations"
+)
+
+func TestInvoiceIsNumberedPerHotelAndImmutable(t *testing.T) {
+	f := newFixture(t, 3)
+	f.requireDeposit(t, 30, 0)
+	ctx := context.Background()
+	hotel, _ := f.hotels.GetHotelByID(ctx, f.hotelID)
+	hotel.TaxPercent = 21
+	if err := f.hotels.Update(ctx, hotel); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := f.book(t, "user-1", "tok_visa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := f.book(t, "user-2", "tok_visa")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invoice, err := f.service.Invoice(ctx, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invoice.Number != invoices.FormatNumber(1) || len(invoice.Lines) != 2 || invoice.Total != 200 {
+		t.Fatalf("expected invoice 1 with two nights for 200, got %+v", invoice)
+	}
+	if invoice.Net != 165.29 || invoice.Tax != 34.71 || invoice.Paid != 60 || invoice.BalanceDue != 140 {
+		t.Fatalf("expected 21%% tax included and 60 paid, got %+v", invoice)
+	}
+	if other, _ := f.service.Invoice(ctx, first); other.Number != invoices.FormatNumber(2) {
+		t.Fatalf("expected the next invoice of the hotel to be 2, got %s", other.Number)
+	}
+
+	// Lo que pase con la reserva después de emitida la factura no la cambia
+	if _, err := f.service.Transition(ctx, second, reservations.StatusCheckedIn); err != nil {
+		t.Fatal(err)
+	}
+	again, err := f.service.Invoice(ctx, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Number != invoice.Number || again.Paid != invoice.Paid || !again.IssuedAt.Equal(invoice.IssuedAt) {
+		t.Fatalf("expected the issued invoice to stay the same, got %+v", again)
+	}
+}
+
+func TestPendingReservationIsNotInvoiceable(t *testing.T) {
+	f := newFixture(t, 1)
+
+	id, err := f.service.CreateReservation(context.Background(), f.reservation("user-1", f.start, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Invoice(context.Background(), id); !errors.Is(err, invoices.ErrNotInvoiceable) {
+		t.Fatalf("expected pending reservation to be rejected, got %v", err)
+	}
+}
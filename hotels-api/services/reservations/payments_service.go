@@ -0,0 +1,351 @@
+package reservations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	hotelsDAO "hotels-api/dao/hotels"
+	"hotels-api/domain/payments"
+	"hotels-api/domain/reservations"
+	"log"
+	"math"
+	"time"
+)
+
+// Pay cobra la seña de una reserva pendiente y la confirma. Si la pasarela rechaza el pago la
+// reserva se cancela y su inventario vuelve a estar disponible.
+func (s Service) Pay(ctx context.Context, id string, paymentMethod string) (reservations.Reservation, error) {
+	if paymentMethod == "" {
+		return reservations.Reservation{}, fmt.Errorf("payment_method is required: %w", payments.ErrPaymentRequired)
+	}
+	reservation, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return reservations.Reservation{}, err
+	}
+	if reservation.Status != reservations.StatusPending {
+		return reservations.Reservation{}, fmt.Errorf("cannot pay a %q reservation: %w", reservation.Status, reservations.ErrInvalidTransition)
+	}
+	now := time.Now().UTC()
+	if err := s.checkOffer(ctx, reservation, now); err != nil {
+		return reservations.Reservation{}, err
+	}
+
+	hotel, err := s.hotelsRepository.GetHotelByID(ctx, reservation.HotelID)
+	if err != nil {
+		return reservations.Reservation{}, fmt.Errorf("error getting hotel: %w", err)
+	}
+	if err := s.charge(ctx, reservation, depositPercent(hotel), paymentMethod, now); err != nil {
+		if errors.Is(err, payments.ErrDeclined) {
+			if releaseErr := s.release(ctx, reservation, reservations.CancellationDeclined, now); releaseErr != nil {
+				return reservations.Reservation{}, fmt.Errorf("error releasing unpaid reservation: %w", releaseErr)
+			}
+		}
+		return reservations.Reservation{}, err
+	}
+
+	if err := s.repository.UpdateStatus(ctx, id, reservation.Status, reservations.StatusConfirmed, now); err != nil {
+		if errors.Is(err, reservations.ErrInvalidTransition) {
+			// Venció o se canceló mientras se cobraba: se devuelve la seña
+			if settleErr := s.settle(ctx, reservation, 0, now); settleErr != nil {
+				return reservations.Reservation{}, fmt.Errorf("error refunding payment of reservation no longer pending: %w", settleErr)
+			}
+		}
+		return reservations.Reservation{}, err
+	}
+	reservation.History = append(reservation.History, reservations.StatusChange{From: reservation.Status, To: reservations.StatusConfirmed, At: now})
+	reservation.Status = reservations.StatusConfirmed
+	return reservation, nil
+}
+
+func (s Service) GetPayment(ctx context.Context, reservationID string) (payments.Payment, error) {
+	return s.paymentsRepository.GetByReservationID(ctx, reservationID)
+}
+
+// checkOffer cancela la oferta de la lista de espera si ya venció, antes de confirmarla o pagarla.
+func (s Service) checkOffer(ctx context.Context, reservation reservations.Reservation, now time.Time) error {
+	if reservation.ExpiresAt == nil || !now.After(*reservation.ExpiresAt) {
+		return nil
+	}
+	if err := s.release(ctx, reservation, reservations.CancellationExpired, now); err != nil {
+		return err
+	}
+	return fmt.Errorf("offer expired at %s: %w", reservation.ExpiresAt.Format(time.RFC3339), reservations.ErrOfferExpired)
+}
+
+// charge autoriza el total de la reserva y captura la seña, y guarda el pago con el resultado. Se
+// puede reintentar sin cobrar dos veces: retoma el pago que haya dejado un intento anterior, que
+// no llama a la pasarela si ya tiene la seña, solo captura lo que falte si quedó autorizado y
+// vuelve a autorizar si se rechazó o se anuló.
+func (s Service) charge(ctx context.Context, reservation reservations.Reservation, percent float64, paymentMethod string, now time.Time) error {
+	payment, err := s.paymentsRepository.GetByReservationID(ctx, reservation.ID)
+	exists := err == nil
+	if err != nil && !errors.Is(err, payments.ErrNotFound) {
+		return err
+	}
+	if !exists {
+		payment = payments.Payment{
+			ReservationID: reservation.ID,
+			HotelID:       reservation.HotelID,
+			UserID:        reservation.UserID,
+			Currency:      reservation.Currency,
+			Status:        payments.StatusDeclined,
+			Transactions:  make([]payments.Transaction, 0),
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+	}
+
+	approved, reason, chargeErr := s.chargeDeposit(ctx, &payment, reservation, roundCents(reservation.Total*percent/100), paymentMethod, now)
+	if exists {
+		err = s.paymentsRepository.Update(ctx, payment)
+	} else {
+		_, err = s.paymentsRepository.Create(ctx, payment)
+	}
+	if err != nil {
+		// Sin el pago guardado nadie podría liberar lo cobrado: se reintegra y se anula antes de
+		// devolver el error
+		if declined, settleErr := s.settlePayment(ctx, &payment, 0, now); settleErr != nil || len(declined) > 0 {
+			log.Printf("error releasing unsaved payment of reservation %s: %v %v", reservation.ID, settleErr, declined)
+		}
+		return fmt.Errorf("error saving payment: %w", err)
+	}
+	if chargeErr != nil {
+		return chargeErr
+	}
+	if !approved {
+		return fmt.Errorf("%s: %w", reason, payments.ErrDeclined)
+	}
+	return nil
+}
+
+// chargeDeposit deja autorizado el total y capturada la seña. Si la seña no se puede capturar se
+// anula la autorización para que no quede abierta; si tampoco se puede anular, el pago queda
+// autorizado y settle la anula al liberar la reserva.
+func (s Service) chargeDeposit(ctx context.Context, payment *payments.Payment, reservation reservations.Reservation, deposit float64, paymentMethod string, now time.Time) (bool, string, error) {
+	if payment.Status != payments.StatusAuthorized && payment.Status != payments.StatusCaptured {
+		approved, reason, err := s.operate(payment, payments.OperationAuthorize, reservation.Total, now, func() (payments.Result, error) {
+			return s.paymentGateway.Authorize(ctx, payments.AuthorizeRequest{
+				Reference:     reservation.ID,
+				Amount:        reservation.Total,
+				Currency:      reservation.Currency,
+				PaymentMethod: paymentMethod,
+			})
+		})
+		if err != nil || !approved {
+			return false, reason, err
+		}
+		payment.AuthorizationID = payment.Transactions[len(payment.Transactions)-1].Reference
+		payment.Authorized = reservation.Total
+		payment.Status = payments.StatusAuthorized
+	}
+
+	missing := roundCents(deposit - payment.Captured)
+	if missing <= 0 {
+		return true, "", nil
+	}
+	approved, reason, err := s.capture(ctx, payment, missing, now)
+	if err == nil && approved {
+		return true, "", nil
+	}
+	// Sin seña no hay reserva: se suelta lo autorizado
+	voided, _, voidErr := s.void(ctx, payment, now)
+	switch {
+	case voidErr != nil || !voided:
+		log.Printf("error voiding authorization %s of reservation %s: %v", payment.AuthorizationID, reservation.ID, voidErr)
+	case err != nil:
+		payment.Status = payments.StatusVoided
+	default:
+		payment.Status = payments.StatusDeclined
+	}
+	return false, reason, err
+}
+
+// captureBalance cobra en el check-in el saldo del total actual de la reserva, que pudo cambiar con
+// una modificación después de autorizar. Lo que exceda lo autorizado se cobra en el hotel, igual
+// que en las reservas sin pago.
+func (s Service) captureBalance(ctx context.Context, reservation reservations.Reservation, now time.Time) error {
+	payment, err := s.paymentsRepository.GetByReservationID(ctx, reservation.ID)
+	if errors.Is(err, payments.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if payment.Status != payments.StatusAuthorized && payment.Status != payments.StatusCaptured {
+		return nil
+	}
+
+	balanceErr := s.collectBalance(ctx, &payment, reservation.Total, now)
+	if err := s.paymentsRepository.Update(ctx, payment); err != nil {
+		return err
+	}
+	return balanceErr
+}
+
+// collectBalance deja cobrado total sobre el pago: captura lo que falta hasta donde alcance lo
+// autorizado, reintegra lo cobrado de más si la estadía se acortó y anula lo que ya no se cobrará.
+func (s Service) collectBalance(ctx context.Context, payment *payments.Payment, total float64, now time.Time) error {
+	balance := roundCents(total - (payment.Captured - payment.Refunded))
+	switch {
+	case balance > 0 && payment.Remaining() > 0:
+		amount := roundCents(math.Min(balance, payment.Remaining()))
+		approved, reason, err := s.capture(ctx, payment, amount, now)
+		if err != nil {
+			return err
+		}
+		if !approved {
+			return fmt.Errorf("balance of %.2f %s: %s: %w", amount, payment.Currency, reason, payments.ErrDeclined)
+		}
+	case balance < 0:
+		// El reintegro rechazado no frena el check-in: queda registrado en el pago para revisarlo
+		approved, reason, err := s.refund(ctx, payment, -balance, now)
+		if err != nil {
+			return err
+		}
+		if !approved {
+			log.Printf("refund of %.2f %s for reservation %s declined: %s", -balance, payment.Currency, payment.ReservationID, reason)
+		}
+	}
+	if payment.Remaining() > 0 {
+		if _, _, err := s.void(ctx, payment, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settle deja cobrada exactamente la penalidad de una reserva cancelada y guarda el pago.
+func (s Service) settle(ctx context.Context, reservation reservations.Reservation, penalty float64, now time.Time) error {
+	payment, err := s.paymentsRepository.GetByReservationID(ctx, reservation.ID)
+	if errors.Is(err, payments.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if payment.Status != payments.StatusAuthorized && payment.Status != payments.StatusCaptured {
+		return nil
+	}
+
+	declined, settleErr := s.settlePayment(ctx, &payment, penalty, now)
+	if err := s.paymentsRepository.Update(ctx, payment); err != nil {
+		return err
+	}
+	if settleErr != nil {
+		return settleErr
+	}
+	if len(declined) > 0 {
+		return fmt.Errorf("%v: %w", declined, payments.ErrDeclined)
+	}
+	return nil
+}
+
+// settlePayment reintegra lo cobrado de más sobre penalty, captura lo que falte hasta donde alcance
+// lo autorizado y anula el resto de la autorización. Devuelve los motivos de lo que no se aprobó.
+func (s Service) settlePayment(ctx context.Context, payment *payments.Payment, penalty float64, now time.Time) ([]string, error) {
+	declined := make([]string, 0)
+	if payment.Status != payments.StatusAuthorized && payment.Status != payments.StatusCaptured {
+		return declined, nil
+	}
+	net := roundCents(payment.Captured - payment.Refunded)
+	switch {
+	case net > penalty:
+		approved, reason, err := s.refund(ctx, payment, roundCents(net-penalty), now)
+		if err != nil {
+			return declined, err
+		}
+		if !approved {
+			declined = append(declined, reason)
+		}
+	case net < penalty && payment.Remaining() > 0:
+		amount := roundCents(math.Min(penalty-net, payment.Remaining()))
+		approved, reason, err := s.capture(ctx, payment, amount, now)
+		if err != nil {
+			return declined, err
+		}
+		if !approved {
+			declined = append(declined, reason)
+		}
+	}
+	if payment.Remaining() > 0 {
+		approved, reason, err := s.void(ctx, payment, now)
+		if err != nil {
+			return declined, err
+		}
+		if !approved {
+			declined = append(declined, reason)
+		}
+	}
+
+	switch {
+	case payment.Captured-payment.Refunded > 0:
+		payment.Status = payments.StatusCaptured
+	case payment.Refunded > 0:
+		payment.Status = payments.StatusRefunded
+	default:
+		payment.Status = payments.StatusVoided
+	}
+	return declined, nil
+}
+
+func (s Service) capture(ctx context.Context, payment *payments.Payment, amount float64, now time.Time) (bool, string, error) {
+	approved, reason, err := s.operate(payment, payments.OperationCapture, amount, now, func() (payments.Result, error) {
+		return s.paymentGateway.Capture(ctx, payment.AuthorizationID, amount)
+	})
+	if approved {
+		payment.Captured = roundCents(payment.Captured + amount)
+		payment.Status = payments.StatusCaptured
+	}
+	return approved, reason, err
+}
+
+func (s Service) refund(ctx context.Context, payment *payments.Payment, amount float64, now time.Time) (bool, string, error) {
+	approved, reason, err := s.operate(payment, payments.OperationRefund, amount, now, func() (payments.Result, error) {
+		return s.paymentGateway.Refund(ctx, payment.AuthorizationID, amount)
+	})
+	if approved {
+		payment.Refunded = roundCents(payment.Refunded + amount)
+	}
+	return approved, reason, err
+}
+
+// void anula lo que queda de la autorización; desde entonces lo autorizado es lo capturado.
+func (s Service) void(ctx context.Context, payment *payments.Payment, now time.Time) (bool, string, error) {
+	approved, reason, err := s.operate(payment, payments.OperationVoid, payment.Remaining(), now, func() (payments.Result, error) {
+		return s.paymentGateway.Void(ctx, payment.AuthorizationID)
+	})
+	if approved {
+		payment.Authorized = payment.Captured
+	}
+	return approved, reason, err
+}
+
+// operate llama a la pasarela y registra la operación en el pago, se haya aprobado o no.
+func (s Service) operate(payment *payments.Payment, operation string, amount float64, now time.Time, call func() (payments.Result, error)) (bool, string, error) {
+	result, err := call()
+	if err != nil {
+		return false, "", fmt.Errorf("error calling payment gateway to %s: %w", operation, err)
+	}
+	payment.Transactions = append(payment.Transactions, payments.Transaction{
+		Operation: operation,
+		Amount:    amount,
+		Reference: result.Reference,
+		Approved:  result.Approved,
+		Reason:    result.Reason,
+		At:        now,
+	})
+	payment.UpdatedAt = now
+	return result.Approved, result.Reason, nil
+}
+
+// depositPercent devuelve la parte del total que el hotel cobra al reservar.
+func depositPercent(hotel hotelsDAO.Hotel) float64 {
+	if hotel.Policy == nil {
+		return 0
+	}
+	return hotel.Policy.DepositPercent
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,237 @@
+package reservations_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	clientsPayments "hotels-api/clients/payments"
+	hotelsDAO "hotels-api/dao/hotels"
+	"hotels-api/domain/payments"
+	"hotels-api/domain/reservations"
+)
+
+// requireDeposit configura el hotel para cobrar una seña y, opcionalmente, una penalidad fija
+// por cancelar en cualquier momento.
+func (f fixture) requireDeposit(t *testing.T, percent float64, penalty float64) {
+	t.Helper()
+	ctx := context.Background()
+	hotel, err := f.hotels.GetHotelByID(ctx, f.hotelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hotel.Policy = &hotelsDAO.CancellationPolicy{DepositPercent: percent}
+	if penalty > 0 {
+		hotel.Policy.Tiers = []hotelsDAO.PenaltyTier{{HoursBeforeCheckIn: 24 * 365, PenaltyPercent: penalty}}
+	}
+	if err := f.hotels.Update(ctx, hotel); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f fixture) book(t *testing.T, userID string, paymentMethod string) (string, error) {
+	t.Helper()
+	reservation := f.reservation(userID, f.start, 2)
+	reservation.PaymentMethod = paymentMethod
+	return f.service.CreateReservation(context.Background(), reservation)
+}
+
+func TestBookingChargesDeposit(t *testing.T) {
+	f := newFixture(t, 1)
+	f.requireDeposit(t, 30, 0)
+	ctx := context.Background()
+
+	if _, err := f.book(t, "user-1", ""); !errors.Is(err, payments.ErrPaymentRequired) {
+		t.Fatalf("expected payment to be required, got %v", err)
+	}
+	if _, err := f.book(t, "user-1", clientsPayments.DeclinedPaymentMethod); !errors.Is(err, payments.ErrDeclined) {
+		t.Fatalf("expected declined payment, got %v", err)
+	}
+	if count := f.repository.Booked(f.roomTypeID, f.start); count != 0 {
+		t.Fatalf("expected unpaid bookings to release the room, got %d taken", count)
+	}
+
+	id, err := f.book(t, "user-1", "tok_visa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reservation, err := f.service.GetReservationByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payment, err := f.service.GetPayment(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reservation.Status != reservations.StatusConfirmed || payment.Authorized != 200 || payment.Captured != 60 {
+		t.Fatalf("expected confirmed reservation with 60 of 200 captured, got %s and %+v", reservation.Status, payment)
+	}
+}
+
+func TestCheckInCapturesBalance(t *testing.T) {
+	f := newFixture(t, 2)
+	f.requireDeposit(t, 30, 0)
+	ctx := context.Background()
+
+	paid, err := f.book(t, "user-1", "tok_visa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Transition(ctx, paid, reservations.StatusCheckedIn); err != nil {
+		t.Fatal(err)
+	}
+	if payment, _ := f.service.GetPayment(ctx, paid); payment.Captured != 200 || payment.Remaining() != 0 {
+		t.Fatalf("expected the balance to be captured at check-in, got %+v", payment)
+	}
+
+	unpaid, err := f.book(t, "user-2", clientsPayments.CaptureDeclinedPaymentMethod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Transition(ctx, unpaid, reservations.StatusCheckedIn); !errors.Is(err, payments.ErrDeclined) {
+		t.Fatalf("expected declined balance to block check-in, got %v", err)
+	}
+	if reservation, _ := f.service.GetReservationByID(ctx, unpaid); reservation.Status != reservations.StatusConfirmed {
+		t.Fatalf("expected reservation to stay confirmed, got %s", reservation.Status)
+	}
+}
+
+func TestCancelSettlesPayment(t *testing.T) {
+	tests := []struct {
+		name     string
+		penalty  float64
+		captured float64
+		refunded float64
+		status   string
+	}{
+		{name: "free cancellation refunds the deposit", penalty: 0, captured: 60, refunded: 60, status: payments.StatusRefunded},
+		{name: "partial penalty refunds the difference", penalty: 20, captured: 60, refunded: 20, status: payments.StatusCaptured},
+		{name: "penalty above the deposit captures the rest", penalty: 50, captured: 100, refunded: 0, status: payments.StatusCaptured},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t, 1)
+			f.requireDeposit(t, 30, tt.penalty)
+			ctx := context.Background()
+
+			id, err := f.book(t, "user-1", "tok_visa")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.service.Cancel(ctx, id); err != nil {
+				t.Fatal(err)
+			}
+			payment, err := f.service.GetPayment(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if payment.Captured != tt.captured || payment.Refunded != tt.refunded || payment.Status != tt.status || payment.Remaining() != 0 {
+				t.Fatalf("expected %.2f captured and %.2f refunded (%s), got %+v", tt.captured, tt.refunded, tt.status, payment)
+			}
+		})
+	}
+}
+
+func TestFailedCaptureVoidsAuthorizationAndReleasesBooking(t *testing.T) {
+	f := newFixture(t, 1)
+	f.requireDeposit(t, 30, 0)
+	ctx := context.Background()
+
+	id, err := f.book(t, "user-1", clientsPayments.CaptureErrorPaymentMethod)
+	if err == nil || errors.Is(err, payments.ErrDeclined) || id != "" {
+		t.Fatalf("expected a gateway error without ID, got %q, %v", id, err)
+	}
+	if count := f.repository.Booked(f.roomTypeID, f.start); count != 0 {
+		t.Fatalf("expected the unpaid booking to release the room, got %d taken", count)
+	}
+	stored, err := f.service.GetReservationsByUserID(ctx, "user-1")
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("expected the rolled back reservation, got %+v, %v", stored, err)
+	}
+	if stored[0].Status != reservations.StatusCancelled || stored[0].Cancellation.Reason != reservations.CancellationPaymentFailed {
+		t.Fatalf("expected the reservation cancelled for the failed payment, got %+v", stored[0])
+	}
+	payment, err := f.service.GetPayment(ctx, stored[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.Status != payments.StatusVoided || payment.Remaining() != 0 || payment.Captured != 0 {
+		t.Fatalf("expected the authorization voided and recorded, got %+v", payment)
+	}
+}
+
+func TestPayRetryChargesOnce(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+	// Sin seña la reserva queda pendiente hasta que se pague
+	id, err := f.book(t, "user-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.requireDeposit(t, 30, 0)
+
+	if _, err := f.service.Pay(ctx, id, clientsPayments.CaptureErrorPaymentMethod); err == nil {
+		t.Fatal("expected the failed capture to be reported")
+	}
+	if _, err := f.service.Pay(ctx, id, "tok_visa"); err != nil {
+		t.Fatal(err)
+	}
+	paid, err := f.service.GetPayment(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Se cobró pero la confirmación no llegó a guardarse: el reintento no vuelve a cobrar
+	if err := f.repository.UpdateStatus(ctx, id, reservations.StatusConfirmed, reservations.StatusPending, time.Now().UTC()); err != nil {
+		t.Fatal(err)
+	}
+	reservation, err := f.service.Pay(ctx, id, "tok_visa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payment, err := f.service.GetPayment(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reservation.Status != reservations.StatusConfirmed || payment.Captured != 60 || payment.Authorized != 200 || len(payment.Transactions) != len(paid.Transactions) {
+		t.Fatalf("expected the retry to confirm without charging again, got %s and %+v", reservation.Status, payment)
+	}
+}
+
+func TestCheckInCapturesBalanceOfModifiedTotal(t *testing.T) {
+	tests := []struct {
+		name       string
+		nights     int
+		captured   float64
+		authorized float64
+	}{
+		{name: "longer stay captures what is authorized", nights: 3, captured: 200, authorized: 200},
+		{name: "shorter stay captures the new total and voids the rest", nights: 1, captured: 100, authorized: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t, 1)
+			f.requireDeposit(t, 30, 0)
+			ctx := context.Background()
+
+			id, err := f.book(t, "user-1", "tok_visa")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.service.Modify(ctx, id, reservations.ModifyRequest{EndDate: f.start.AddDays(tt.nights)}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.service.Transition(ctx, id, reservations.StatusCheckedIn); err != nil {
+				t.Fatal(err)
+			}
+			payment, err := f.service.GetPayment(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if payment.Captured != tt.captured || payment.Authorized != tt.authorized || payment.Remaining() != 0 {
+				t.Fatalf("expected %.2f captured of %.2f authorized, got %+v", tt.captured, tt.authorized, payment)
+			}
+		})
+	}
+}
@@ -3,32 +3,785 @@ package reservations
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	hotelsDAO "hotels-api/dao/hotels"
+	roomsDAO "hotels-api/dao/rooms"
+	"hotels-api/domain/allotments"
+	hotelsDomain "hotels-api/domain/hotels"
+	"hotels-api/domain/invoices"
+	"hotels-api/domain/loyalty"
+	"hotels-api/domain/payments"
+	"hotels-api/domain/promotions"
 	"hotels-api/domain/reservations"
+	"hotels-api/services/pricing"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Repository interface {
-	Create(ctx context.Context, reservation reservations.Reservation) (string, error)
+	Book(ctx context.Context, reservation reservations.Reservation, capacity map[string]int) (string, error)
+	Rebook(ctx context.Context, reservation reservations.Reservation, previous reservations.Reservation, capacity map[string]int) error
+	GetByID(ctx context.Context, id string) (reservations.Reservation, error)
 	GetByUserID(ctx context.Context, userID string) ([]reservations.Reservation, error)
+	GetExpired(ctx context.Context, now time.Time, createdBefore time.Time, limit int) ([]reservations.Reservation, error)
+	UpdateStatus(ctx context.Context, id string, from, to string, at time.Time) error
+	Cancel(ctx context.Context, id string, from string, cancellation reservations.Cancellation) error
+	Search(ctx context.Context, filter reservations.SearchFilter) ([]reservations.Reservation, int64, error)
+	Iterate(ctx context.Context, filter reservations.SearchFilter, fn func(reservations.Reservation) error) error
+	GetBooked(ctx context.Context, roomTypeIDs []string, from, to reservations.Date) (map[string]map[string]int, error)
+}
+
+type RoomsRepository interface {
+	GetByHotelID(ctx context.Context, hotelID string) ([]roomsDAO.RoomType, error)
+	GetByID(ctx context.Context, id string) (roomsDAO.RoomType, error)
+}
+
+type HotelsRepository interface {
+	GetHotelByID(ctx context.Context, id string) (hotelsDAO.Hotel, error)
+}
+
+type WaitlistRepository interface {
+	Create(ctx context.Context, entry reservations.WaitlistEntry) (string, error)
+	GetByID(ctx context.Context, id string) (reservations.WaitlistEntry, error)
+	GetByUserID(ctx context.Context, userID string) ([]reservations.WaitlistEntry, error)
+	GetByHotelID(ctx context.Context, hotelID string) ([]reservations.WaitlistEntry, error)
+	GetWaiting(ctx context.Context, roomTypeID string, from, to reservations.Date) ([]reservations.WaitlistEntry, error)
+	Offer(ctx context.Context, id string, reservationID string, at time.Time) error
+	Leave(ctx context.Context, id string) error
+}
+
+type AvailabilityCache interface {
+	GetAvailability(ctx context.Context, hotelID string, roomTypeID string, from, to reservations.Date) (reservations.Availability, error)
+	SetAvailability(ctx context.Context, roomTypeID string, availability reservations.Availability) error
+	DeleteAvailability(ctx context.Context, hotelID string) error
+}
+
+type Queue interface {
+	Publish(event reservations.Event) error
+}
+
+type PaymentsRepository interface {
+	Create(ctx context.Context, payment payments.Payment) (string, error)
+	GetByReservationID(ctx context.Context, reservationID string) (payments.Payment, error)
+	Update(ctx context.Context, payment payments.Payment) error
+}
+
+type PaymentGateway interface {
+	Authorize(ctx context.Context, request payments.AuthorizeRequest) (payments.Result, error)
+	Capture(ctx context.Context, authorizationID string, amount float64) (payments.Result, error)
+	Refund(ctx context.Context, authorizationID string, amount float64) (payments.Result, error)
+	Void(ctx context.Context, authorizationID string) (payments.Result, error)
+}
+
+type InvoicesRepository interface {
+	GetByReservationID(ctx context.Context, reservationID string) (invoices.Invoice, error)
+	Issue(ctx context.Context, invoice invoices.Invoice) (invoices.Invoice, error)
+}
+
+type AllotmentsRepository interface {
+	GetByID(ctx context.Context, id string) (allotments.Allotment, error)
+	GetByCode(ctx context.Context, hotelID, code string) (allotments.Allotment, error)
+}
+
+type PromotionsRepository interface {
+	GetByID(ctx context.Context, id string) (promotions.Promotion, error)
+	GetByCode(ctx context.Context, code string) (promotions.Promotion, error)
+	Redeem(ctx context.Context, promotion promotions.Promotion, userID string) error
+	Unredeem(ctx context.Context, promotion promotions.Promotion, userID string) error
+}
+
+// LoyaltyClient canjea y devuelve los puntos de fidelidad que lleva users-api.
+type LoyaltyClient interface {
+	Redeem(ctx context.Context, userID string, points int64, reference string) error
+	Refund(ctx context.Context, userID string, reference string) error
 }
 
 type Service struct {
-	repository Repository
+	repository           Repository
+	roomsRepository      RoomsRepository
+	hotelsRepository     HotelsRepository
+	waitlistRepository   WaitlistRepository
+	availabilityCache    AvailabilityCache
+	eventsQueue          Queue
+	paymentsRepository   PaymentsRepository
+	paymentGateway       PaymentGateway
+	invoicesRepository   InvoicesRepository
+	allotmentsRepository AllotmentsRepository
+	promotionsRepository PromotionsRepository
+	loyaltyClient        LoyaltyClient
 }
 
-func NewService(repository Repository) Service {
-	return Service{repository: repository}
+func NewService(repository Repository, roomsRepository RoomsRepository, hotelsRepository HotelsRepository, waitlistRepository WaitlistRepository, availabilityCache AvailabilityCache, eventsQueue Queue, paymentsRepository PaymentsRepository, paymentGateway PaymentGateway, invoicesRepository InvoicesRepository, allotmentsRepository AllotmentsRepository, promotionsRepository PromotionsRepository, loyaltyClient LoyaltyClient) Service {
+	return Service{
+		repository:           repository,
+		roomsRepository:      roomsRepository,
+		hotelsRepository:     hotelsRepository,
+		waitlistRepository:   waitlistRepository,
+		availabilityCache:    availabilityCache,
+		eventsQueue:          eventsQueue,
+		paymentsRepository:   paymentsRepository,
+		paymentGateway:       paymentGateway,
+		invoicesRepository:   invoicesRepository,
+		allotmentsRepository: allotmentsRepository,
+		promotionsRepository: promotionsRepository,
+		loyaltyClient:        loyaltyClient,
+	}
 }
 
+// CreateReservation toma la reserva y, si el hotel pide seña, la cobra en el momento: con el pago
+// aprobado la reserva queda confirmada y si se rechaza o falla se cancela y se libera el inventario.
 func (s Service) CreateReservation(ctx context.Context, reservation reservations.Reservation) (string, error) {
-	// Se puede agregar lógica para validar disponibilidad aquí.
-	id, err := s.repository.Create(ctx, reservation)
+	hotel, err := s.hotelsRepository.GetHotelByID(ctx, reservation.HotelID)
 	if err != nil {
+		return "", fmt.Errorf("error getting hotel: %w", err)
+	}
+	if depositPercent(hotel) > 0 && reservation.PaymentMethod == "" {
+		return "", fmt.Errorf("hotel requires a %.0f%% deposit: %w", depositPercent(hotel), payments.ErrPaymentRequired)
+	}
+
+	// Solo las ofertas de la lista de espera vencen
+	reservation.ExpiresAt = nil
+	reservation.WaitlistEntryID = ""
+	// El cupo y la promoción se resuelven a partir de sus códigos, nunca los elige el cliente
+	reservation.AllotmentID = ""
+	reservation.PromotionID, reservation.PromoDiscount = "", 0
+	reservation.PointsDiscount, reservation.PointsReference = 0, ""
+	id, err := s.book(ctx, reservation)
+	if err != nil || depositPercent(hotel) == 0 {
+		return id, err
+	}
+
+	if _, err := s.Pay(ctx, id, reservation.PaymentMethod); err != nil {
+		// El cliente no recibe el ID, así que la reserva sin pagar no puede seguir tomando inventario
+		s.releaseUnpaid(ctx, id)
+		return "", err
+	}
+	return id, nil
+}
+
+// releaseUnpaid cancela una reserva nueva que quedó pendiente porque falló su cobro. Si Pay ya la
+// canceló, por ejemplo porque se rechazó la tarjeta, no hace nada.
+func (s Service) releaseUnpaid(ctx context.Context, id string) {
+	reservation, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		log.Printf("error getting unpaid reservation %s: %v", id, err)
+		return
+	}
+	if reservation.Status != reservations.StatusPending {
+		return
+	}
+	if err := s.release(ctx, reservation, reservations.CancellationPaymentFailed, time.Now().UTC()); err != nil {
+		log.Printf("error releasing unpaid reservation %s: %v", id, err)
+	}
+}
+
+// book valida y cotiza la estadía y toma el inventario para una reserva nueva en estado pendiente.
+func (s Service) book(ctx context.Context, reservation reservations.Reservation) (string, error) {
+	hotel, err := s.hotelsRepository.GetHotelByID(ctx, reservation.HotelID)
+	if err != nil {
+		return "", fmt.Errorf("error getting hotel: %w", err)
+	}
+	now := time.Now().UTC()
+	if err := validateStay(hotel, reservation.StartDate, reservation.EndDate, now); err != nil {
+		return "", err
+	}
+
+	capacity, err := s.prepareStay(ctx, &reservation)
+	if err != nil {
+		return "", err
+	}
+	if reservation.BlockCode != "" {
+		allotment, err := s.allotmentsRepository.GetByCode(ctx, reservation.HotelID, strings.ToUpper(strings.TrimSpace(reservation.BlockCode)))
+		if err != nil {
+			return "", err
+		}
+		if !allotment.IsOpen(reservations.DateOf(now.In(hotelsDomain.Location(hotel.TimeZone)))) {
+			return "", fmt.Errorf("room block %s: %w", allotment.Code, allotments.ErrBlockClosed)
+		}
+		if err := fitsBlock(allotment, reservation); err != nil {
+			return "", err
+		}
+		reservation.AllotmentID, reservation.BlockCode = allotment.ID, allotment.Code
+		capacity[allotment.ID] = allotment.Rooms
+	}
+	var promotion *promotions.Promotion
+	if reservation.PromoCode != "" {
+		found, err := s.promotionsRepository.GetByCode(ctx, strings.ToUpper(strings.TrimSpace(reservation.PromoCode)))
+		if err != nil {
+			return "", err
+		}
+		if !found.IsValidAt(now) {
+			return "", fmt.Errorf("code %s is not valid today: %w", found.Code, promotions.ErrNotApplicable)
+		}
+		if err := applyPromotion(&reservation, found); err != nil {
+			return "", err
+		}
+		promotion = &found
+	}
+	if reservation.PointsRedeemed != 0 {
+		if err := applyPoints(&reservation); err != nil {
+			return "", err
+		}
+		reservation.PointsReference = primitive.NewObjectID().Hex()
+	}
+
+	// El ID y el estado inicial los define el servicio, nunca el cliente
+	reservation.ID = ""
+	reservation.Status = reservations.StatusPending
+	reservation.CreatedAt = now
+	reservation.History = []reservations.StatusChange{{To: reservations.StatusPending, At: now}}
+	reservation.Cancellation = nil
+	reservation.Modifications = nil
+	reservation.Version = 1
+
+	// Los usos del código, el saldo de puntos y la disponibilidad se verifican de forma atómica al
+	// contarlos, canjearlos y tomar el inventario; si algo falla se devuelve lo ya tomado
+	if promotion != nil {
+		if err := s.promotionsRepository.Redeem(ctx, *promotion, reservation.UserID); err != nil {
+			return "", err
+		}
+	}
+	if reservation.PointsRedeemed > 0 {
+		if err := s.loyaltyClient.Redeem(ctx, reservation.UserID, reservation.PointsRedeemed, reservation.PointsReference); err != nil {
+			// Si users-api no respondió el canje pudo haberse hecho igual
+			s.returnPromotion(ctx, reservation)
+			if errors.Is(err, loyalty.ErrUnavailable) {
+				s.returnPoints(ctx, reservation)
+			}
+			return "", err
+		}
+	}
+	id, err := s.repository.Book(ctx, reservation, capacity)
+	if err != nil {
+		s.returnPromotion(ctx, reservation)
+		s.returnPoints(ctx, reservation)
 		return "", fmt.Errorf("error creating reservation: %w", err)
 	}
+	s.invalidateAvailability(ctx, reservation.HotelID)
+
+	reservation.ID = id
+	s.publish(reservations.EventCreated, reservation)
 	return id, nil
 }
 
+// Modify cambia fechas, habitaciones o huéspedes. La disponibilidad se revalida sin contar
+// las noches que la propia reserva libera, y el nuevo precio y las fechas se guardan en una única
+// escritura condicionada a la versión leída: o se aplica todo o no se aplica nada.
+func (s Service) Modify(ctx context.Context, id string, request reservations.ModifyRequest) (reservations.Reservation, error) {
+	current, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return reservations.Reservation{}, err
+	}
+	modifiable := false
+	for _, status := range reservations.ModifiableStatuses {
+		modifiable = modifiable || current.Status == status
+	}
+	if !modifiable {
+		return reservations.Reservation{}, fmt.Errorf("a %q reservation: %w", current.Status, reservations.ErrNotModifiable)
+	}
+
+	// Se copian las líneas para no pisar las de current al recalcular el precio
+	updated := current
+	updated.Items = append([]reservations.LineItem(nil), current.LineItems()...)
+	if len(request.Items) > 0 {
+		updated.Items = request.Items
+	} else if request.RoomTypeID != "" || request.Guests != 0 {
+		if len(updated.Items) != 1 || updated.Items[0].Quantity != 1 {
+			return reservations.Reservation{}, fmt.Errorf("room_type_id and guests only apply to single-room reservations, send items instead: %w", reservations.ErrInvalidItems)
+		}
+		if request.RoomTypeID != "" {
+			updated.Items[0].RoomTypeID = request.RoomTypeID
+		}
+		if request.Guests != 0 {
+			updated.Items[0].Adults, updated.Items[0].Children = request.Guests, 0
+		}
+	}
+	if !request.StartDate.IsZero() {
+		updated.StartDate = request.StartDate
+	}
+	if !request.EndDate.IsZero() {
+		updated.EndDate = request.EndDate
+	}
+
+	hotel, err := s.hotelsRepository.GetHotelByID(ctx, current.HotelID)
+	if err != nil {
+		return reservations.Reservation{}, fmt.Errorf("error getting hotel: %w", err)
+	}
+	now := time.Now().UTC()
+	if current.Status == reservations.StatusCheckedIn {
+		// Con el huésped en el hotel solo se puede mover la salida
+		if !updated.StartDate.Equal(current.StartDate.Time) || !sameRooms(updated.Items, current.LineItems()) {
+			return reservations.Reservation{}, fmt.Errorf("only end_date can change after check-in: %w", reservations.ErrNotModifiable)
+		}
+		if updated.EndDate.Before(reservations.DateOf(now.In(hotelsDomain.Location(hotel.TimeZone))).Time) {
+			return reservations.Reservation{}, fmt.Errorf("end_date %s is in the past: %w", updated.EndDate, reservations.ErrInvalidDates)
+		}
+	} else if err := validateStay(hotel, updated.StartDate, updated.EndDate, now); err != nil {
+		return reservations.Reservation{}, err
+	}
+
+	capacity, err := s.prepareStay(ctx, &updated)
+	if err != nil {
+		return reservations.Reservation{}, err
+	}
+	if current.AllotmentID != "" {
+		allotment, err := s.allotmentsRepository.GetByID(ctx, current.AllotmentID)
+		if err != nil {
+			return reservations.Reservation{}, err
+		}
+		// Con el cupo cerrado solo se puede acortar la estadía, que no toma noches nuevas
+		shorter := !updated.StartDate.Before(current.StartDate.Time) && !updated.EndDate.After(current.EndDate.Time) && sameRooms(updated.Items, current.LineItems())
+		if !shorter && !allotment.IsOpen(reservations.DateOf(now.In(hotelsDomain.Location(hotel.TimeZone)))) {
+			return reservations.Reservation{}, fmt.Errorf("room block %s: %w", allotment.Code, allotments.ErrBlockClosed)
+		}
+		if err := fitsBlock(allotment, updated); err != nil {
+			return reservations.Reservation{}, err
+		}
+		capacity[allotment.ID] = allotment.Rooms
+	}
+	if current.PromotionID != "" {
+		promotion, err := s.promotionsRepository.GetByID(ctx, current.PromotionID)
+		if err != nil {
+			return reservations.Reservation{}, err
+		}
+		// El uso ya se contó al reservar: solo se revisa que la nueva estadía cumpla las condiciones
+		if err := applyPromotion(&updated, promotion); err != nil {
+			return reservations.Reservation{}, err
+		}
+	}
+	if current.PointsRedeemed > 0 {
+		// Los puntos ya se canjearon: se vuelven a descontar del nuevo total
+		if err := applyPoints(&updated); err != nil {
+			return reservations.Reservation{}, err
+		}
+	}
+	updated.Modifications = append(updated.Modifications, reservations.Modification{
+		At:                 now,
+		PreviousRoomTypeID: current.RoomTypeID,
+		PreviousStartDate:  current.StartDate,
+		PreviousEndDate:    current.EndDate,
+		PreviousTotal:      current.Total,
+		PreviousItems:      current.LineItems(),
+	})
+
+	if err := s.repository.Rebook(ctx, updated, current, capacity); err != nil {
+		return reservations.Reservation{}, err
+	}
+	updated.Version = current.Version + 1
+	s.invalidateAvailability(ctx, updated.HotelID)
+	s.publish(reservations.EventModified, updated)
+
+	// Si la reserva dejó noches libres se le ofrecen a la lista de espera
+	s.promoteReservation(ctx, current)
+	return updated, nil
+}
+
+// prepareStay valida cada línea contra su tipo de habitación, completa el precio de la estadía y
+// devuelve cuántas habitaciones hay de cada tipo para tomar el inventario. Una reserva sin líneas
+// se toma como una única habitación de RoomTypeID para Guests huéspedes.
+func (s Service) prepareStay(ctx context.Context, reservation *reservations.Reservation) (map[string]int, error) {
+	if len(reservation.Items) == 0 {
+		if reservation.RoomTypeID == "" {
+			return nil, fmt.Errorf("items or room_type_id are required: %w", reservations.ErrInvalidItems)
+		}
+		reservation.Items = []reservations.LineItem{{RoomTypeID: reservation.RoomTypeID, Quantity: 1, Adults: reservation.Guests}}
+	}
+
+	capacity := make(map[string]int)
+	items := make([]reservations.LineItem, 0, len(reservation.Items))
+	reservation.Currency, reservation.Nights = "", nil
+	reservation.Guests, reservation.Discount, reservation.Total = 0, 0, 0
+	for _, item := range reservation.Items {
+		if item.Quantity == 0 {
+			item.Quantity = 1
+		}
+		if item.Adults == 0 && item.Children == 0 {
+			item.Adults = item.Quantity
+		}
+		if item.Quantity < 0 || item.Adults < 0 || item.Children < 0 {
+			return nil, fmt.Errorf("quantity, adults and children cannot be negative: %w", reservations.ErrInvalidItems)
+		}
+
+		roomType, err := s.roomsRepository.GetByID(ctx, item.RoomTypeID)
+		if err != nil || roomType.HotelID != reservation.HotelID {
+			return nil, fmt.Errorf("room type %q: %w", item.RoomTypeID, reservations.ErrUnknownRoomType)
+		}
+		if item.Adults < item.Quantity {
+			return nil, fmt.Errorf("each %s room needs at least one adult: %w", roomType.Name, reservations.ErrInvalidGuests)
+		}
+		if guests := item.Adults + item.Children; guests > roomType.Capacity*item.Quantity {
+			return nil, fmt.Errorf("%d %s rooms hold up to %d guests, not %d: %w", item.Quantity, roomType.Name, roomType.Capacity*item.Quantity, guests, reservations.ErrInvalidGuests)
+		}
+
+		// El precio se cotiza en el servidor y queda fijo en la reserva
+		quote, err := pricing.Calculate(roomType, reservation.StartDate.Time, reservation.EndDate.Time)
+		if err != nil {
+			return nil, fmt.Errorf("error pricing reservation: %w", err)
+		}
+		if reservation.Currency != "" && quote.Currency != reservation.Currency {
+			return nil, fmt.Errorf("room type %s is priced in %s, not %s: %w", roomType.Name, quote.Currency, reservation.Currency, reservations.ErrInvalidItems)
+		}
+		item.Nights = quote.Nights
+		item.Discount = math.Round(quote.Discount*float64(item.Quantity)*100) / 100
+		item.Total = math.Round(quote.Total*float64(item.Quantity)*100) / 100
+
+		reservation.Currency = quote.Currency
+		reservation.Guests += item.Adults + item.Children
+		reservation.Discount += item.Discount
+		reservation.Total += item.Total
+		capacity[item.RoomTypeID] = roomType.Count
+		items = append(items, item)
+	}
+	reservation.Items = items
+	reservation.RoomTypeID = items[0].RoomTypeID
+	reservation.Discount = math.Round(reservation.Discount*100) / 100
+	reservation.Total = math.Round(reservation.Total*100) / 100
+	return capacity, nil
+}
+
+// fitsBlock verifica que todas las habitaciones de la reserva sean del tipo del cupo y que la
+// estadía caiga dentro de sus fechas.
+func fitsBlock(allotment allotments.Allotment, reservation reservations.Reservation) error {
+	if !allotment.Covers(reservation.StartDate, reservation.EndDate) {
+		return fmt.Errorf("room block %s runs from %s to %s: %w", allotment.Code, allotment.StartDate, allotment.EndDate, allotments.ErrOutsideBlock)
+	}
+	for _, item := range reservation.Items {
+		if item.RoomTypeID != allotment.RoomTypeID {
+			return fmt.Errorf("room block %s does not include room type %s: %w", allotment.Code, item.RoomTypeID, allotments.ErrOutsideBlock)
+		}
+	}
+	return nil
+}
+
+// applyPromotion descuenta el código del total de la reserva, con el descuento por estadía ya
+// aplicado, si la estadía cumple sus condiciones.
+func applyPromotion(reservation *reservations.Reservation, promotion promotions.Promotion) error {
+	if err := promotion.Fits(reservation.HotelID, reservation.StartDate, reservation.EndDate); err != nil {
+		return err
+	}
+	discount, err := promotion.DiscountFor(reservation.Total, reservation.Currency)
+	if err != nil {
+		return err
+	}
+	reservation.PromotionID, reservation.PromoCode, reservation.PromoDiscount = promotion.ID, promotion.Code, discount
+	reservation.Total = math.Round((reservation.Total-discount)*100) / 100
+	return nil
+}
+
+// applyPoints descuenta del total los puntos canjeados, después del código de descuento. Los
+// puntos no pueden valer más de lo que queda por pagar.
+func applyPoints(reservation *reservations.Reservation) error {
+	discount, err := loyalty.DiscountFor(reservation.PointsRedeemed, reservation.Currency)
+	if err != nil {
+		return err
+	}
+	if discount > reservation.Total {
+		return fmt.Errorf("%d points are worth %.2f %s, more than the %.2f total: %w", reservation.PointsRedeemed, discount, reservation.Currency, reservation.Total, loyalty.ErrInvalidPoints)
+	}
+	reservation.PointsDiscount = discount
+	reservation.Total = math.Round((reservation.Total-discount)*100) / 100
+	return nil
+}
+
+// returnPoints devuelve al huésped los puntos que canjeó en una reserva que se canceló o que no se
+// pudo tomar. La reserva ya quedó así, de modo que un error solo se registra.
+func (s Service) returnPoints(ctx context.Context, reservation reservations.Reservation) {
+	if reservation.PointsReference == "" {
+		return
+	}
+	if err := s.loyaltyClient.Refund(ctx, reservation.UserID, reservation.PointsReference); err != nil {
+		log.Printf("Error returning points of reservation %s: %v", reservation.ID, err)
+	}
+}
+
+// returnPromotion devuelve el uso del código de una reserva que se canceló o que no se pudo tomar.
+// La reserva ya quedó así, de modo que un error solo se registra.
+func (s Service) returnPromotion(ctx context.Context, reservation reservations.Reservation) {
+	if reservation.PromotionID == "" {
+		return
+	}
+	promotion, err := s.promotionsRepository.GetByID(ctx, reservation.PromotionID)
+	if err == nil {
+		err = s.promotionsRepository.Unredeem(ctx, promotion, reservation.UserID)
+	}
+	if err != nil {
+		log.Printf("Error returning promo code of reservation %s: %v", reservation.ID, err)
+	}
+}
+
+// sameRooms indica si dos reservas ocupan las mismas habitaciones, línea por línea.
+func sameRooms(a, b []reservations.LineItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].RoomTypeID != b[i].RoomTypeID || a[i].Quantity != b[i].Quantity {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Service) GetReservationByID(ctx context.Context, id string) (reservations.Reservation, error) {
+	return s.repository.GetByID(ctx, id)
+}
+
+// Transition mueve la reserva al estado indicado si el ciclo de vida lo permite.
+func (s Service) Transition(ctx context.Context, id string, to string) (reservations.Reservation, error) {
+	if to == reservations.StatusCancelled {
+		return s.Cancel(ctx, id)
+	}
+
+	reservation, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return reservations.Reservation{}, err
+	}
+	if !reservations.CanTransition(reservation.Status, to) {
+		return reservations.Reservation{}, fmt.Errorf("cannot go from %q to %q: %w", reservation.Status, to, reservations.ErrInvalidTransition)
+	}
+
+	now := time.Now().UTC()
+	switch to {
+	case reservations.StatusConfirmed:
+		if err := s.checkOffer(ctx, reservation, now); err != nil {
+			return reservations.Reservation{}, err
+		}
+		// En los hoteles con seña solo se confirma pagando
+		hotel, err := s.hotelsRepository.GetHotelByID(ctx, reservation.HotelID)
+		if err != nil {
+			return reservations.Reservation{}, fmt.Errorf("error getting hotel: %w", err)
+		}
+		if depositPercent(hotel) > 0 {
+			return reservations.Reservation{}, fmt.Errorf("pay the %.0f%% deposit to confirm: %w", depositPercent(hotel), payments.ErrPaymentRequired)
+		}
+	case reservations.StatusCheckedIn:
+		if err := s.captureBalance(ctx, reservation, now); err != nil {
+			return reservations.Reservation{}, err
+		}
+	}
+
+	if err := s.repository.UpdateStatus(ctx, id, reservation.Status, to, now); err != nil {
+		return reservations.Reservation{}, err
+	}
+	reservation.History = append(reservation.History, reservations.StatusChange{From: reservation.Status, To: to, At: now})
+	reservation.Status = to
+
+	if to == reservations.StatusCheckedOut {
+		s.publish(reservations.EventCheckedOut, reservation)
+	}
+	if reservations.IsInactive(to) {
+		s.invalidateAvailability(ctx, reservation.HotelID)
+		s.promoteReservation(ctx, reservation)
+	}
+	return reservation, nil
+}
+
+// Cancel cancela la reserva aplicando la política de cancelación del hotel.
+func (s Service) Cancel(ctx context.Context, id string) (reservations.Reservation, error) {
+	reservation, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return reservations.Reservation{}, err
+	}
+	if !reservations.CanTransition(reservation.Status, reservations.StatusCancelled) {
+		return reservations.Reservation{}, fmt.Errorf("cannot cancel a %q reservation: %w", reservation.Status, reservations.ErrInvalidTransition)
+	}
+
+	hotel, err := s.hotelsRepository.GetHotelByID(ctx, reservation.HotelID)
+	if err != nil {
+		return reservations.Reservation{}, fmt.Errorf("error getting hotel: %w", err)
+	}
+	now := time.Now().UTC()
+	percent := penaltyPercent(hotel.Policy, checkInAt(hotel, reservation.StartDate), now)
+	penalty := math.Round(reservation.Total*percent) / 100
+	cancellation := reservations.Cancellation{
+		PenaltyPercent: percent,
+		Penalty:        penalty,
+		Refund:         math.Round((reservation.Total-penalty)*100) / 100,
+		CancelledAt:    now,
+	}
+	if err := s.repository.Cancel(ctx, id, reservation.Status, cancellation); err != nil {
+		return reservations.Reservation{}, err
+	}
+
+	reservation.History = append(reservation.History, reservations.StatusChange{From: reservation.Status, To: reservations.StatusCancelled, At: now})
+	reservation.Status = reservations.StatusCancelled
+	reservation.Cancellation = &cancellation
+	s.returnPromotion(ctx, reservation)
+	s.returnPoints(ctx, reservation)
+
+	// La cancelación ya se aplicó; si la pasarela falla el pago queda para revisar a mano
+	if err := s.settle(ctx, reservation, penalty, now); err != nil {
+		log.Printf("error settling payment for cancelled reservation %s: %v", id, err)
+	}
+	s.invalidateAvailability(ctx, reservation.HotelID)
+	s.publish(reservations.EventCancelled, reservation)
+	s.promoteReservation(ctx, reservation)
+	return reservation, nil
+}
+
+// release cancela sin penalidad una reserva pendiente que no llegó a confirmarse, porque venció
+// o falló el pago, libera su inventario y devuelve lo que se haya llegado a cobrar.
+func (s Service) release(ctx context.Context, reservation reservations.Reservation, reason string, now time.Time) error {
+	cancellation := reservations.Cancellation{
+		Refund:      reservation.Total,
+		Reason:      reason,
+		CancelledAt: now,
+	}
+	if err := s.repository.Cancel(ctx, reservation.ID, reservations.StatusPending, cancellation); err != nil {
+		return err
+	}
+	s.invalidateAvailability(ctx, reservation.HotelID)
+	s.returnPromotion(ctx, reservation)
+	s.returnPoints(ctx, reservation)
+	// Una reserva pendiente no tiene penalidad: si quedó algo autorizado o cobrado se devuelve entero
+	if err := s.settle(ctx, reservation, 0, now); err != nil {
+		log.Printf("error settling payment for released reservation %s: %v", reservation.ID, err)
+	}
+
+	reservation.History = append(reservation.History, reservations.StatusChange{From: reservation.Status, To: reservations.StatusCancelled, At: now})
+	reservation.Status = reservations.StatusCancelled
+	reservation.Cancellation = &cancellation
+	s.publish(reservations.EventCancelled, reservation)
+
+	s.promoteReservation(ctx, reservation)
+	return nil
+}
+
+// publish avisa a otros sistemas del cambio en la reserva. Una falla solo se registra: el cambio
+// ya se guardó y devolver error haría que el cliente lo reintente.
+func (s Service) publish(eventType string, reservation reservations.Reservation) {
+	event := reservations.NewEvent(primitive.NewObjectID().Hex(), eventType, reservation, time.Now().UTC())
+	if err := s.eventsQueue.Publish(event); err != nil {
+		log.Printf("error publishing %s for reservation %s: %v", eventType, reservation.ID, err)
+	}
+}
+
 func (s Service) GetReservationsByUserID(ctx context.Context, userID string) ([]reservations.Reservation, error) {
 	return s.repository.GetByUserID(ctx, userID)
 }
+
+// Search busca reservas para el personal del hotel, paginadas y ordenadas.
+func (s Service) Search(ctx context.Context, filter reservations.SearchFilter) (reservations.SearchResult, error) {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return reservations.SearchResult{}, err
+	}
+	if filter.Limit == 0 {
+		filter.Limit = reservations.DefaultSearchLimit
+	}
+
+	results, total, err := s.repository.Search(ctx, filter)
+	if err != nil {
+		return reservations.SearchResult{}, fmt.Errorf("error searching reservations: %w", err)
+	}
+	return reservations.SearchResult{
+		Results: results,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+// Export recorre todas las reservas del filtro, sin paginar, para exportarlas.
+func (s Service) Export(ctx context.Context, filter reservations.SearchFilter, fn func(reservations.Reservation) error) error {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return err
+	}
+	filter.Limit, filter.Offset = 0, 0
+	return s.repository.Iterate(ctx, filter, fn)
+}
+
+func normalizeFilter(filter reservations.SearchFilter) (reservations.SearchFilter, error) {
+	for _, status := range filter.Statuses {
+		if !reservations.IsStatus(status) {
+			return filter, fmt.Errorf("unknown status %q: %w", status, reservations.ErrInvalidFilter)
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.To.After(filter.From.Time) {
+		return filter, fmt.Errorf("to must be after from: %w", reservations.ErrInvalidFilter)
+	}
+	if filter.Limit < 0 || filter.Limit > reservations.MaxSearchLimit || filter.Offset < 0 {
+		return filter, fmt.Errorf("limit must be between 1 and %d and offset cannot be negative: %w", reservations.MaxSearchLimit, reservations.ErrInvalidFilter)
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = "start_date"
+	}
+	valid := false
+	for _, field := range reservations.SortFields {
+		valid = valid || field == filter.SortBy
+	}
+	if !valid {
+		return filter, fmt.Errorf("cannot sort by %q: %w", filter.SortBy, reservations.ErrInvalidFilter)
+	}
+	return filter, nil
+}
+
+// validateStay verifica las fechas de la estadía contra el calendario local del hotel.
+func validateStay(hotel hotelsDAO.Hotel, start, end reservations.Date, now time.Time) error {
+	if start.IsZero() || end.IsZero() {
+		return fmt.Errorf("start_date and end_date are required: %w", reservations.ErrInvalidDates)
+	}
+	if !end.After(start.Time) {
+		return fmt.Errorf("end_date must be after start_date: %w", reservations.ErrInvalidDates)
+	}
+
+	maxStay := hotel.MaxStayNights
+	if maxStay == 0 {
+		maxStay = hotelsDomain.DefaultMaxStayNights
+	}
+	if nights := start.NightsUntil(end); nights > maxStay {
+		return fmt.Errorf("stay of %d nights exceeds the maximum of %d: %w", nights, maxStay, reservations.ErrInvalidDates)
+	}
+
+	localNow := now.In(hotelsDomain.Location(hotel.TimeZone))
+	today := reservations.DateOf(localNow)
+	if start.Before(today.Time) {
+		return fmt.Errorf("start_date %s is in the past: %w", start, reservations.ErrInvalidDates)
+	}
+	if start.Equal(today.Time) && hotel.BookingCutoff != "" {
+		cutoff, err := time.ParseInLocation(hotelsDomain.TimeOfDayLayout, hotel.BookingCutoff, localNow.Location())
+		if err == nil && localNow.Hour()*60+localNow.Minute() >= cutoff.Hour()*60+cutoff.Minute() {
+			return fmt.Errorf("same-day bookings close at %s hotel time: %w", hotel.BookingCutoff, reservations.ErrInvalidDates)
+		}
+	}
+	return nil
+}
+
+// checkInAt devuelve el instante del check-in en la hora local del hotel.
+func checkInAt(hotel hotelsDAO.Hotel, date reservations.Date) time.Time {
+	checkInTime := hotel.CheckInTime
+	if checkInTime == "" {
+		checkInTime = hotelsDomain.DefaultCheckInTime
+	}
+	clock, err := time.Parse(hotelsDomain.TimeOfDayLayout, checkInTime)
+	if err != nil {
+		clock, _ = time.Parse(hotelsDomain.TimeOfDayLayout, hotelsDomain.DefaultCheckInTime)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, hotelsDomain.Location(hotel.TimeZone))
+}
+
+// penaltyPercent devuelve el porcentaje a cobrar al cancelar en el instante at.
+// Sin política configurada la cancelación es gratuita.
+func penaltyPercent(policy *hotelsDAO.CancellationPolicy, checkIn time.Time, at time.Time) float64 {
+	if policy == nil {
+		return 0
+	}
+	if policy.NonRefundable {
+		return 100
+	}
+	hoursLeft := checkIn.Sub(at).Hours()
+	percent := 0.0
+	for _, tier := range policy.Tiers {
+		if hoursLeft < float64(tier.HoursBeforeCheckIn) && tier.PenaltyPercent > percent {
+			percent = tier.PenaltyPercent
+		}
+	}
+	return percent
+}
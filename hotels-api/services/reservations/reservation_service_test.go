@@ -0,0 +1,1005 @@
+package reservations_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	clientsLoyalty "hotels-api/clients/loyalty"
+	clientsPayments "hotels-api/clients/payments"
+	"hotels-api/clients/queues"
+	hotelsDAO "hotels-api/dao/hotels"
+	roomsDAO "hotels-api/dao/rooms"
+	"hotels-api/domain/allotments"
+	"hotels-api/domain/loyalty"
+	"hotels-api/domain/promotions"
+	"hotels-api/domain/reservations"
+	repositoriesAllotments "hotels-api/repositories/allotments"
+	repositoriesHotels "hotels-api/repositories/hotels"
+	repositoriesInvoices "hotels-api/repositories/invoices"
+	repositoriesPayments "hotels-api/repositories/payments"
+	repositoriesPromotions "hotels-api/repositories/promotions"
+	repositoriesReservations "hotels-api/repositories/reservations"
+	repositoriesRooms "hotels-api/repositories/rooms"
+	repositoriesWaitlist "hotels-api/repositories/waitlist"
+	service "hotels-api/services/reservations"
+)
+
+type fixture struct {
+	repository repositoriesReservations.Mock
+	hotels     repositoriesHotels.Mock
+	payments   repositoriesPayments.Mock
+	waitlist   repositoriesWaitlist.Mock
+	rooms      repositoriesRooms.Mock
+	allotments repositoriesAllotments.Mock
+	promotions repositoriesPromotions.Mock
+	loyalty    clientsLoyalty.Fake
+	events     queues.ReservationsMock
+	service    service.Service
+	hotelID    string
+	roomTypeID string
+	start      reservations.Date
+}
+
+func newFixture(t *testing.T, rooms int) fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	hotelsRepo := repositoriesHotels.NewMock()
+	hotelID, err := hotelsRepo.Create(ctx, hotelsDAO.Hotel{Name: "Hotel Sierras", TimeZone: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roomsRepo := repositoriesRooms.NewMock()
+	roomTypeID, err := roomsRepo.Create(ctx, roomsDAO.RoomType{
+		HotelID:  hotelID,
+		Name:     "Doble",
+		Capacity: 2,
+		Count:    rooms,
+		RatePlan: &roomsDAO.RatePlan{Currency: "ARS", BaseRate: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository := repositoriesReservations.NewMock()
+	waitlist := repositoriesWaitlist.NewMock()
+	events := queues.NewReservationsMock()
+	paymentsRepo := repositoriesPayments.NewMock()
+	allotmentsRepo := repositoriesAllotments.NewMock()
+	promotionsRepo := repositoriesPromotions.NewMock()
+	loyaltyClient := clientsLoyalty.NewFake()
+	return fixture{
+		repository: repository,
+		hotels:     hotelsRepo,
+		payments:   paymentsRepo,
+		waitlist:   waitlist,
+		rooms:      roomsRepo,
+		allotments: allotmentsRepo,
+		promotions: promotionsRepo,
+		loyalty:    loyaltyClient,
+		events:     events,
+		service: service.NewService(repository, roomsRepo, hotelsRepo, waitlist, repositoriesHotels.NewCache(repositoriesHotels.CacheConfig{
+			MaxSize:      1000,
+			ItemsToPrune: 10,
+			Duration:     time.Minute,
+		}), events, paymentsRepo, clientsPayments.NewFake(), repositoriesInvoices.NewMock(), allotmentsRepo, promotionsRepo, loyaltyClient),
+		hotelID:    hotelID,
+		roomTypeID: roomTypeID,
+		start:      reservations.DateOf(time.Now().UTC()).AddDays(10),
+	}
+}
+
+func (f fixture) reservation(userID string, start reservations.Date, nights int) reservations.Reservation {
+	return reservations.Reservation{
+		HotelID:    f.hotelID,
+		RoomTypeID: f.roomTypeID,
+		UserID:     userID,
+		StartDate:  start,
+		EndDate:    start.AddDays(nights),
+	}
+}
+
+func TestCreateReservationChecksEveryNight(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+
+	if _, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start, 2)); err != nil {
+		t.Fatal(err)
+	}
+	// Cualquier estadía que toque una de las dos noches ocupadas se rechaza, aunque empiece antes
+	for _, start := range []reservations.Date{f.start.AddDays(-1), f.start, f.start.AddDays(1)} {
+		if _, err := f.service.CreateReservation(ctx, f.reservation("user-2", start, 2)); !errors.Is(err, reservations.ErrNoAvailability) {
+			t.Fatalf("expected ErrNoAvailability for a stay from %s, got %v", start, err)
+		}
+	}
+	// La noche del check-out queda libre para la reserva siguiente
+	if _, err := f.service.CreateReservation(ctx, f.reservation("user-3", f.start.AddDays(2), 1)); err != nil {
+		t.Fatalf("expected the check-out night to be free, got %v", err)
+	}
+	if _, err := f.service.CreateReservation(ctx, f.reservation("user-4", f.start.AddDays(-1), 1)); err != nil {
+		t.Fatalf("expected the night before the stay to be free, got %v", err)
+	}
+}
+
+func TestCreateReservationIgnoresClientID(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+
+	// Un "id" en el cuerpo del POST no puede elegir el _id del documento
+	reservation := f.reservation("user-1", f.start, 2)
+	reservation.ID = "abc"
+	id, err := f.service.CreateReservation(ctx, reservation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == "abc" {
+		t.Fatal("expected the service to assign the reservation ID")
+	}
+	if _, err := f.service.GetReservationByID(ctx, "abc"); !errors.Is(err, reservations.ErrNotFound) {
+		t.Fatalf("expected no reservation stored under the client ID, got %v", err)
+	}
+	if count := f.repository.Booked(f.roomTypeID, f.start); count != 1 {
+		t.Fatalf("expected 1 room taken, got %d", count)
+	}
+}
+
+func TestCreateReservationRejectsInvalidDates(t *testing.T) {
+	f := newFixture(t, 1)
+	yesterday := reservations.DateOf(time.Now().UTC()).AddDays(-1)
+	stays := []struct {
+		start reservations.Date
+		end   reservations.Date
+	}{
+		{start: reservations.Date{}, end: f.start},
+		{start: f.start, end: f.start},
+		{start: f.start, end: f.start.AddDays(-2)},
+		{start: yesterday, end: yesterday.AddDays(2)},
+	}
+	for _, stay := range stays {
+		reservation := f.reservation("user-1", stay.start, 1)
+		reservation.EndDate = stay.end
+		if _, err := f.service.CreateReservation(context.Background(), reservation); !errors.Is(err, reservations.ErrInvalidDates) {
+			t.Fatalf("expected ErrInvalidDates for %s to %s, got %v", stay.start, stay.end, err)
+		}
+	}
+}
+
+func TestCreateReservationRejectsRoomTypesOfOtherHotels(t *testing.T) {
+	f := newFixture(t, 2)
+	ctx := context.Background()
+
+	otherHotelID, err := f.hotels.Create(ctx, hotelsDAO.Hotel{Name: "Amerian", TimeZone: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRoomTypeID, err := f.rooms.Create(ctx, roomsDAO.RoomType{
+		HotelID:  otherHotelID,
+		Name:     "Suite",
+		Capacity: 2,
+		Count:    5,
+		RatePlan: &roomsDAO.RatePlan{Currency: "ARS", BaseRate: 300},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, roomTypeID := range []string{otherRoomTypeID, "unknown-room-type"} {
+		reservation := f.reservation("user-1", f.start, 2)
+		reservation.RoomTypeID = roomTypeID
+		if _, err := f.service.CreateReservation(ctx, reservation); !errors.Is(err, reservations.ErrUnknownRoomType) {
+			t.Fatalf("expected ErrUnknownRoomType for %s, got %v", roomTypeID, err)
+		}
+	}
+}
+
+func TestRoomTypesHaveTheirOwnInventory(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+	suiteID, err := f.rooms.Create(ctx, roomsDAO.RoomType{
+		HotelID:  f.hotelID,
+		Name:     "Suite",
+		Capacity: 2,
+		Count:    1,
+		RatePlan: &roomsDAO.RatePlan{Currency: "ARS", BaseRate: 300},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start, 2)); err != nil {
+		t.Fatal(err)
+	}
+	suite := f.reservation("user-2", f.start, 2)
+	suite.RoomTypeID = suiteID
+	if _, err := f.service.CreateReservation(ctx, suite); err != nil {
+		t.Fatalf("expected the suite to be free while the double is full, got %v", err)
+	}
+	if _, err := f.service.CreateReservation(ctx, suite); !errors.Is(err, reservations.ErrNoAvailability) {
+		t.Fatalf("expected ErrNoAvailability once the suite is taken, got %v", err)
+	}
+}
+
+func TestTransitionsFollowTheLifecycle(t *testing.T) {
+	f := newFixture(t, 2)
+	ctx := context.Background()
+
+	id, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Desde pendiente no se puede saltar al check-in ni al check-out
+	for _, status := range []string{reservations.StatusCheckedIn, reservations.StatusCheckedOut, reservations.StatusNoShow} {
+		if _, err := f.service.Transition(ctx, id, status); !errors.Is(err, reservations.ErrInvalidTransition) {
+			t.Fatalf("expected ErrInvalidTransition from pending to %s, got %v", status, err)
+		}
+	}
+
+	path := []string{reservations.StatusConfirmed, reservations.StatusCheckedIn, reservations.StatusCheckedOut}
+	for _, status := range path {
+		reservation, err := f.service.Transition(ctx, id, status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reservation.Status != status {
+			t.Fatalf("expected %s, got %s", status, reservation.Status)
+		}
+	}
+	for _, status := range []string{reservations.StatusConfirmed, reservations.StatusCheckedIn, reservations.StatusCancelled} {
+		if _, err := f.service.Transition(ctx, id, status); !errors.Is(err, reservations.ErrInvalidTransition) {
+			t.Fatalf("expected ErrInvalidTransition from checked_out to %s, got %v", status, err)
+		}
+	}
+
+	reservation, err := f.service.GetReservationByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// La primera entrada del historial es la creación de la reserva
+	path = append([]string{reservations.StatusPending}, path...)
+	if reservation.Status != reservations.StatusCheckedOut || len(reservation.History) != len(path) {
+		t.Fatalf("expected the stay to be recorded, got %s with history %+v", reservation.Status, reservation.History)
+	}
+	from := ""
+	for i, change := range reservation.History {
+		if change.From != from || change.To != path[i] || change.At.IsZero() {
+			t.Fatalf("history %d: expected %q -> %s, got %+v", i, from, path[i], change)
+		}
+		from = change.To
+	}
+}
+
+func TestNoShowReleasesInventory(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+
+	id, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Transition(ctx, id, reservations.StatusConfirmed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Transition(ctx, id, reservations.StatusNoShow); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.CreateReservation(ctx, f.reservation("user-2", f.start, 2)); err != nil {
+		t.Fatalf("expected a no-show to release the room, got %v", err)
+	}
+	if _, err := f.service.Transition(ctx, id, reservations.StatusCheckedIn); !errors.Is(err, reservations.ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition after a no-show, got %v", err)
+	}
+}
+
+func TestCancelAppliesPolicyTiers(t *testing.T) {
+	tiers := []hotelsDAO.PenaltyTier{
+		{HoursBeforeCheckIn: 48, PenaltyPercent: 50},
+		{HoursBeforeCheckIn: 24 * 30, PenaltyPercent: 20},
+	}
+	tests := []struct {
+		name    string
+		policy  *hotelsDAO.CancellationPolicy
+		days    int
+		percent float64
+	}{
+		{name: "no policy is free", policy: nil, days: 10, percent: 0},
+		{name: "outside every tier is free", policy: &hotelsDAO.CancellationPolicy{Tiers: tiers[:1]}, days: 10, percent: 0},
+		{name: "one tier applies", policy: &hotelsDAO.CancellationPolicy{Tiers: tiers}, days: 10, percent: 20},
+		{name: "the highest tier wins", policy: &hotelsDAO.CancellationPolicy{Tiers: tiers}, days: 1, percent: 50},
+		{name: "non refundable keeps everything", policy: &hotelsDAO.CancellationPolicy{NonRefundable: true, Tiers: tiers}, days: 10, percent: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t, 1)
+			ctx := context.Background()
+			hotel, err := f.hotels.GetHotelByID(ctx, f.hotelID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hotel.Policy = tt.policy
+			if err := f.hotels.Update(ctx, hotel); err != nil {
+				t.Fatal(err)
+			}
+
+			start := reservations.DateOf(time.Now().UTC()).AddDays(tt.days)
+			id, err := f.service.CreateReservation(ctx, f.reservation("user-1", start, 2))
+			if err != nil {
+				t.Fatal(err)
+			}
+			reservation, err := f.service.Cancel(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			penalty := 200 * tt.percent / 100
+			if c := reservation.Cancellation; c == nil || c.PenaltyPercent != tt.percent || c.Penalty != penalty || c.Refund != 200-penalty {
+				t.Fatalf("expected a %.0f%% penalty of %.2f, got %+v", tt.percent, penalty, reservation.Cancellation)
+			}
+			if stored, _ := f.service.GetReservationByID(ctx, id); stored.Cancellation == nil || stored.Cancellation.Penalty != penalty {
+				t.Fatalf("expected the cancellation to be stored, got %+v", stored.Cancellation)
+			}
+		})
+	}
+}
+
+func TestCancelRoundsTheRefundToCents(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+	hotel, err := f.hotels.GetHotelByID(ctx, f.hotelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hotel.Policy = &hotelsDAO.CancellationPolicy{Tiers: []hotelsDAO.PenaltyTier{{HoursBeforeCheckIn: 24 * 30, PenaltyPercent: 25}}}
+	if err := f.hotels.Update(ctx, hotel); err != nil {
+		t.Fatal(err)
+	}
+	roomTypeID, err := f.rooms.Create(ctx, roomsDAO.RoomType{
+		HotelID:  f.hotelID,
+		Name:     "Single",
+		Capacity: 1,
+		Count:    1,
+		RatePlan: &roomsDAO.RatePlan{Currency: "ARS", BaseRate: 33.33},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reservation := f.reservation("user-1", f.start, 2)
+	reservation.RoomTypeID = roomTypeID
+	id, err := f.service.CreateReservation(ctx, reservation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, err := f.service.Cancel(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 66.66 - 16.67 en float64 da 49.989999999999995
+	if c := cancelled.Cancellation; c == nil || c.Penalty != 16.67 || c.Refund != 49.99 {
+		t.Fatalf("expected a penalty of 16.67 and a refund of 49.99, got %+v", cancelled.Cancellation)
+	}
+}
+
+func TestModifyRecordsChangesAndGuardsStatus(t *testing.T) {
+	f := newFixture(t, 2)
+	ctx := context.Background()
+	suiteID, err := f.rooms.Create(ctx, roomsDAO.RoomType{
+		HotelID:  f.hotelID,
+		Name:     "Suite",
+		Capacity: 2,
+		Count:    1,
+		RatePlan: &roomsDAO.RatePlan{Currency: "ARS", BaseRate: 300},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	modified, err := f.service.Modify(ctx, id, reservations.ModifyRequest{RoomTypeID: suiteID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified.RoomTypeID != suiteID || modified.Total != 600 || modified.Version != 2 {
+		t.Fatalf("unexpected modified reservation: %+v", modified)
+	}
+	if f.repository.Booked(f.roomTypeID, f.start) != 0 || f.repository.Booked(suiteID, f.start) != 1 {
+		t.Fatal("expected the booking to move to the suite")
+	}
+	stored, err := f.service.GetReservationByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Modifications) != 1 || stored.Version != 2 {
+		t.Fatalf("expected one recorded modification at version 2, got %+v", stored)
+	}
+	if change := stored.Modifications[0]; change.PreviousRoomTypeID != f.roomTypeID || change.PreviousTotal != 200 || change.PreviousStartDate != f.start || change.PreviousEndDate != f.start.AddDays(2) {
+		t.Fatalf("unexpected modification record: %+v", change)
+	}
+
+	// Con el huésped en el hotel solo se puede mover la salida
+	for _, status := range []string{reservations.StatusConfirmed, reservations.StatusCheckedIn} {
+		if _, err := f.service.Transition(ctx, id, status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := f.service.Modify(ctx, id, reservations.ModifyRequest{StartDate: f.start.AddDays(1)}); !errors.Is(err, reservations.ErrNotModifiable) {
+		t.Fatalf("expected ErrNotModifiable moving the check-in of a checked-in stay, got %v", err)
+	}
+	if modified, err = f.service.Modify(ctx, id, reservations.ModifyRequest{EndDate: f.start.AddDays(3)}); err != nil {
+		t.Fatal(err)
+	}
+	if modified.Total != 900 || modified.Version != 3 {
+		t.Fatalf("unexpected extended reservation: %+v", modified)
+	}
+
+	cancelled, err := f.service.CreateReservation(ctx, f.reservation("user-2", f.start, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Cancel(ctx, cancelled); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Modify(ctx, cancelled, reservations.ModifyRequest{EndDate: f.start.AddDays(3)}); !errors.Is(err, reservations.ErrNotModifiable) {
+		t.Fatalf("expected ErrNotModifiable for a cancelled reservation, got %v", err)
+	}
+}
+
+func TestConcurrentModificationsDoNotOverwriteEachOther(t *testing.T) {
+	f := newFixture(t, 5)
+	ctx := context.Background()
+
+	id, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(nights int) {
+			defer wg.Done()
+			_, err := f.service.Modify(context.Background(), id, reservations.ModifyRequest{EndDate: f.start.AddDays(nights)})
+			errs <- err
+		}(i%3 + 1)
+	}
+	wg.Wait()
+	close(errs)
+
+	applied := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			applied++
+		case !errors.Is(err, reservations.ErrConcurrentUpdate):
+			t.Fatalf("expected ErrConcurrentUpdate, got %v", err)
+		}
+	}
+	reservation, err := f.service.GetReservationByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied == 0 || reservation.Version != 1+applied || len(reservation.Modifications) != applied {
+		t.Fatalf("expected version %d with %d modifications, got %d with %d", 1+applied, applied, reservation.Version, len(reservation.Modifications))
+	}
+	// El inventario refleja solo la última estadía guardada
+	nights := int(reservation.EndDate.Sub(reservation.StartDate.Time).Hours() / 24)
+	for night := 0; night < 3; night++ {
+		expected := 0
+		if night < nights {
+			expected = 1
+		}
+		if count := f.repository.Booked(f.roomTypeID, f.start.AddDays(night)); count != expected {
+			t.Fatalf("night %d: expected %d rooms taken, got %d", night, expected, count)
+		}
+	}
+}
+
+func TestCreateReservationConcurrently(t *testing.T) {
+	const rooms = 3
+	const attempts = 50
+	f := newFixture(t, rooms)
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	booked, rejected := 0, 0
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Todas las estadías se pisan en la noche f.start+2
+			start := f.start.AddDays(i % 3)
+			_, err := f.service.CreateReservation(context.Background(), f.reservation("user", start, 3))
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			switch {
+			case err == nil:
+				booked++
+			case errors.Is(err, reservations.ErrNoAvailability):
+				rejected++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if booked != rooms || booked+rejected != attempts {
+		t.Fatalf("expected %d bookings and %d rejections, got %d and %d", rooms, attempts-rooms, booked, rejected)
+	}
+	for night := f.start; night.Before(f.start.AddDays(5).Time); night = night.AddDays(1) {
+		if count := f.repository.Booked(f.roomTypeID, night); count > rooms {
+			t.Fatalf("night %s overbooked: %d rooms taken", night, count)
+		}
+	}
+}
+
+func TestCancelReleasesInventory(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+
+	id, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.CreateReservation(ctx, f.reservation("user-2", f.start, 2)); !errors.Is(err, reservations.ErrNoAvailability) {
+		t.Fatalf("expected ErrNoAvailability, got %v", err)
+	}
+
+	if _, err := f.service.Cancel(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.CreateReservation(ctx, f.reservation("user-2", f.start, 2)); err != nil {
+		t.Fatalf("expected the released room to be bookable, got %v", err)
+	}
+}
+
+func TestModifyKeepsNightsWhenExtensionFails(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+
+	id, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.CreateReservation(ctx, f.reservation("user-2", f.start.AddDays(2), 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Extender sobre noches ocupadas falla sin perder las noches propias
+	_, err = f.service.Modify(ctx, id, reservations.ModifyRequest{EndDate: f.start.AddDays(3)})
+	if !errors.Is(err, reservations.ErrNoAvailability) {
+		t.Fatalf("expected ErrNoAvailability, got %v", err)
+	}
+	if count := f.repository.Booked(f.roomTypeID, f.start); count != 1 {
+		t.Fatalf("expected the original night to stay booked, got %d", count)
+	}
+
+	// Acortar y correr la estadía libera solo lo que deja de usar
+	modified, err := f.service.Modify(ctx, id, reservations.ModifyRequest{StartDate: f.start.AddDays(1), EndDate: f.start.AddDays(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified.Total != 100 || modified.Version != 2 {
+		t.Fatalf("unexpected modified reservation: %+v", modified)
+	}
+	if f.repository.Booked(f.roomTypeID, f.start) != 0 || f.repository.Booked(f.roomTypeID, f.start.AddDays(1)) != 1 {
+		t.Fatal("inventory not updated after modification")
+	}
+}
+
+func TestMultiRoomReservationIsOneUnit(t *testing.T) {
+	f := newFixture(t, 3)
+	ctx := context.Background()
+	booking := func(userID string, items ...reservations.LineItem) reservations.Reservation {
+		reservation := f.reservation(userID, f.start, 2)
+		reservation.RoomTypeID = ""
+		reservation.Items = items
+		return reservation
+	}
+
+	invalid := []reservations.LineItem{
+		{RoomTypeID: f.roomTypeID, Quantity: 1, Adults: 2, Children: 1},
+		{RoomTypeID: f.roomTypeID, Quantity: 2, Adults: 1, Children: 1},
+	}
+	for _, item := range invalid {
+		if _, err := f.service.CreateReservation(ctx, booking("user-1", item)); !errors.Is(err, reservations.ErrInvalidGuests) {
+			t.Fatalf("expected ErrInvalidGuests for %+v, got %v", item, err)
+		}
+	}
+
+	id, err := f.service.CreateReservation(ctx, booking("user-1", reservations.LineItem{RoomTypeID: f.roomTypeID, Quantity: 2, Adults: 2, Children: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reservation, _ := f.service.GetReservationByID(ctx, id)
+	if reservation.Total != 400 || reservation.Guests != 4 || reservation.RoomTypeID != f.roomTypeID {
+		t.Fatalf("unexpected multi-room reservation: %+v", reservation)
+	}
+	if count := f.repository.Booked(f.roomTypeID, f.start); count != 2 {
+		t.Fatalf("expected 2 rooms taken, got %d", count)
+	}
+
+	// Dos líneas del mismo tipo cuentan juntas contra el inventario
+	twoLines := booking("user-2",
+		reservations.LineItem{RoomTypeID: f.roomTypeID, Quantity: 1, Adults: 1},
+		reservations.LineItem{RoomTypeID: f.roomTypeID, Quantity: 1, Adults: 1},
+	)
+	if _, err := f.service.CreateReservation(ctx, twoLines); !errors.Is(err, reservations.ErrNoAvailability) {
+		t.Fatalf("expected ErrNoAvailability, got %v", err)
+	}
+
+	if _, err := f.service.Cancel(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if count := f.repository.Booked(f.roomTypeID, f.start); count != 0 {
+		t.Fatalf("expected cancellation to release every room, got %d taken", count)
+	}
+}
+
+func TestLifecyclePublishesEvents(t *testing.T) {
+	f := newFixture(t, 2)
+	ctx := context.Background()
+
+	cancelled, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Modify(ctx, cancelled, reservations.ModifyRequest{EndDate: f.start.AddDays(3)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Cancel(ctx, cancelled); err != nil {
+		t.Fatal(err)
+	}
+
+	stayed, err := f.service.CreateReservation(ctx, f.reservation("user-2", f.start, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []string{reservations.StatusConfirmed, reservations.StatusCheckedIn, reservations.StatusCheckedOut} {
+		if _, err := f.service.Transition(ctx, stayed, status); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []struct{ eventType, reservationID string }{
+		{reservations.EventCreated, cancelled},
+		{reservations.EventModified, cancelled},
+		{reservations.EventCancelled, cancelled},
+		{reservations.EventCreated, stayed},
+		{reservations.EventCheckedOut, stayed},
+	}
+	events := f.events.Events()
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %+v", len(expected), len(events), events)
+	}
+	for i, event := range events {
+		if event.Type != expected[i].eventType || event.Reservation.ID != expected[i].reservationID || event.Version != reservations.EventVersion {
+			t.Fatalf("event %d: expected %s for %s, got %+v", i, expected[i].eventType, expected[i].reservationID, event)
+		}
+	}
+	if events[1].Reservation.EndDate != f.start.AddDays(3) || events[2].Reservation.Refund == nil {
+		t.Fatalf("events are missing reservation data: %+v %+v", events[1], events[2])
+	}
+}
+
+func TestBlockCodeBookingsDrawFromTheBlock(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, 3)
+	end := f.start.AddDays(3)
+
+	// El cupo aparta 2 de las 3 habitaciones, como lo hace el servicio de cupos
+	if err := f.repository.Hold(ctx, f.roomTypeID, f.start, end, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	allotmentID, err := f.allotments.Create(ctx, allotments.Allotment{
+		HotelID:     f.hotelID,
+		RoomTypeID:  f.roomTypeID,
+		Code:        "ACME",
+		StartDate:   f.start,
+		EndDate:     end,
+		Rooms:       2,
+		ReleaseDate: f.start.AddDays(-5),
+		Status:      allotments.StatusActive,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := func(userID string, start reservations.Date, nights int) (string, error) {
+		reservation := f.reservation(userID, start, nights)
+		reservation.BlockCode = "acme"
+		return f.service.CreateReservation(ctx, reservation)
+	}
+
+	if _, err := f.service.CreateReservation(ctx, f.reservation("walk-in", f.start, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.CreateReservation(ctx, f.reservation("walk-in-2", f.start, 1)); !errors.Is(err, reservations.ErrNoAvailability) {
+		t.Fatalf("expected the block rooms to be out of general sale, got %v", err)
+	}
+
+	// Las reservas del grupo salen del cupo, no del inventario general
+	first, err := block("guest-1", f.start, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := block("guest-2", f.start, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := block("guest-3", f.start, 1); !errors.Is(err, reservations.ErrNoAvailability) {
+		t.Fatalf("expected the block to be full, got %v", err)
+	}
+	if _, err := block("guest-4", f.start.AddDays(2), 2); !errors.Is(err, allotments.ErrOutsideBlock) {
+		t.Fatalf("expected a stay outside the block to be rejected, got %v", err)
+	}
+	if booked := f.repository.Booked(f.roomTypeID, f.start); booked != 3 {
+		t.Fatalf("expected 3 rooms taken on the first night, got %d", booked)
+	}
+	reservation, err := f.service.GetReservationByID(ctx, first)
+	if err != nil || reservation.AllotmentID != allotmentID || reservation.BlockCode != "ACME" {
+		t.Fatalf("expected the reservation tied to the block, got %+v, %v", reservation, err)
+	}
+
+	// Al liberar el cupo vuelve a la venta la tercera noche, que el grupo no usó
+	released, err := f.repository.ReleaseAllotment(ctx, allotmentID, f.roomTypeID, f.start, end, 2)
+	if err != nil || released != 2 {
+		t.Fatalf("expected 2 room-nights released, got %d, %v", released, err)
+	}
+	if err := f.allotments.MarkReleased(ctx, allotmentID, released, time.Now().UTC()); err != nil {
+		t.Fatal(err)
+	}
+	if booked := f.repository.Booked(f.roomTypeID, end.AddDays(-1)); booked != 1 {
+		t.Fatalf("expected only the walk-in on the last night, got %d", booked)
+	}
+	if _, err := block("guest-5", f.start, 1); !errors.Is(err, allotments.ErrBlockClosed) {
+		t.Fatalf("expected the released block to be closed, got %v", err)
+	}
+
+	// Una cancelación del grupo después de la liberación devuelve la habitación a la venta
+	if _, err := f.service.Cancel(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if booked := f.repository.Booked(f.roomTypeID, f.start); booked != 2 {
+		t.Fatalf("expected the cancelled block room back on sale, got %d booked", booked)
+	}
+}
+
+func TestPromoCodeIsAppliedAndCounted(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, 50)
+	now := time.Now().UTC()
+	promotionID, err := f.promotions.Create(ctx, promotions.Promotion{
+		Code:            "VERANO",
+		DiscountPercent: 10,
+		ValidFrom:       now.Add(-time.Hour),
+		ValidUntil:      now.Add(time.Hour),
+		HotelIDs:        []string{f.hotelID},
+		MinNights:       2,
+		MaxRedemptions:  5,
+		MaxPerUser:      2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	promo := func(userID string, nights int) (string, error) {
+		reservation := f.reservation(userID, f.start, nights)
+		reservation.PromoCode = " verano "
+		return f.service.CreateReservation(ctx, reservation)
+	}
+
+	// 3 noches a 100: el código descuenta el 10% y queda guardado en la reserva
+	id, err := promo("guest-1", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reservation, err := f.service.GetReservationByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reservation.PromotionID != promotionID || reservation.PromoCode != "VERANO" || reservation.PromoDiscount != 30 || reservation.Total != 270 {
+		t.Fatalf("expected the promo discount stored, got %+v", reservation)
+	}
+	// Al modificar, el descuento se recalcula y se guarda junto con el nuevo total
+	if _, err := f.service.Modify(ctx, id, reservations.ModifyRequest{EndDate: f.start.AddDays(4)}); err != nil {
+		t.Fatal(err)
+	}
+	if reservation, err = f.service.GetReservationByID(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if reservation.PromotionID != promotionID || reservation.PromoDiscount != 40 || reservation.Total != 360 {
+		t.Fatalf("expected the promo discount recalculated on modify, got %+v", reservation)
+	}
+	if _, err := promo("guest-2", 1); !errors.Is(err, promotions.ErrNotApplicable) {
+		t.Fatalf("expected a stay below min nights to be rejected, got %v", err)
+	}
+
+	// Límite por huésped
+	if _, err := promo("guest-1", 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := promo("guest-1", 2); !errors.Is(err, promotions.ErrExhausted) {
+		t.Fatalf("expected the per-guest limit, got %v", err)
+	}
+
+	// Quedan 3 usos: de muchas reservas simultáneas pasan exactamente 3
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	booked := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := promo(fmt.Sprintf("rush-%d", i), 2)
+			mutex.Lock()
+			defer mutex.Unlock()
+			switch {
+			case err == nil:
+				booked++
+			case !errors.Is(err, promotions.ErrExhausted):
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if booked != 3 {
+		t.Fatalf("expected 3 redemptions left, got %d bookings", booked)
+	}
+
+	// Una cancelación devuelve el uso
+	if _, err := f.service.Cancel(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := promo("guest-3", 2); err != nil {
+		t.Fatalf("expected the cancelled redemption back, got %v", err)
+	}
+	current, err := f.promotions.GetByID(ctx, promotionID)
+	if err != nil || current.Redemptions != 5 {
+		t.Fatalf("expected 5 redemptions, got %+v, %v", current, err)
+	}
+}
+
+func TestRedeemedPointsDiscountAndAreReturned(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, 1)
+	f.loyalty.Credit("guest-1", 1000)
+	withPoints := func(userID string, points int64) (string, error) {
+		reservation := f.reservation(userID, f.start, 3)
+		reservation.PointsRedeemed = points
+		return f.service.CreateReservation(ctx, reservation)
+	}
+
+	if _, err := withPoints("guest-1", 40); !errors.Is(err, loyalty.ErrInvalidPoints) {
+		t.Fatalf("expected points worth more than the stay to be rejected, got %v", err)
+	}
+	if _, err := withPoints("guest-2", 10); !errors.Is(err, loyalty.ErrInsufficientPoints) {
+		t.Fatalf("expected a guest without points to be rejected, got %v", err)
+	}
+
+	// 3 noches a 100 ARS: 10 puntos descuentan 100
+	id, err := withPoints("guest-1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reservation, err := f.service.GetReservationByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reservation.PointsDiscount != 100 || reservation.Total != 200 || f.loyalty.Balance("guest-1") != 990 {
+		t.Fatalf("expected the points discounted, got %+v with %d points left", reservation, f.loyalty.Balance("guest-1"))
+	}
+
+	// Sin lugar la reserva no se toma y los puntos vuelven
+	if _, err := withPoints("guest-1", 10); !errors.Is(err, reservations.ErrNoAvailability) {
+		t.Fatalf("expected no availability, got %v", err)
+	}
+	if balance := f.loyalty.Balance("guest-1"); balance != 990 {
+		t.Fatalf("expected the points of the failed booking back, got %d", balance)
+	}
+
+	if _, err := f.service.Cancel(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if balance := f.loyalty.Balance("guest-1"); balance != 1000 {
+		t.Fatalf("expected the points back after cancelling, got %d", balance)
+	}
+}
+
+func TestSearchValidatesTheFilter(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+
+	cases := map[string]reservations.SearchFilter{
+		"unknown status":  {Statuses: []string{reservations.StatusConfirmed, "archived"}},
+		"inverted dates":  {From: f.start.AddDays(2), To: f.start},
+		"empty range":     {From: f.start, To: f.start},
+		"negative limit":  {Limit: -1},
+		"limit too large": {Limit: reservations.MaxSearchLimit + 1},
+		"negative offset": {Offset: -1},
+		"unknown sort":    {SortBy: "user_id"},
+	}
+	for name, filter := range cases {
+		if _, err := f.service.Search(ctx, filter); !errors.Is(err, reservations.ErrInvalidFilter) {
+			t.Fatalf("%s: expected ErrInvalidFilter, got %v", name, err)
+		}
+		called := false
+		err := f.service.Export(ctx, filter, func(reservations.Reservation) error {
+			called = true
+			return nil
+		})
+		if !errors.Is(err, reservations.ErrInvalidFilter) || called {
+			t.Fatalf("%s: expected the export to fail with ErrInvalidFilter before any row, got %v", name, err)
+		}
+	}
+	for _, field := range reservations.SortFields {
+		if _, err := f.service.Search(ctx, reservations.SearchFilter{SortBy: field, SortDesc: true}); err != nil {
+			t.Fatalf("expected to sort by %s, got %v", field, err)
+		}
+	}
+}
+
+func TestSearchPaginates(t *testing.T) {
+	f := newFixture(t, 5)
+	ctx := context.Background()
+
+	ids := make([]string, 0)
+	for i := 0; i < 5; i++ {
+		id, err := f.service.CreateReservation(ctx, f.reservation(fmt.Sprintf("user-%d", i), f.start.AddDays(i), 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	// Sin límite se usa el predeterminado
+	result, err := f.service.Search(ctx, reservations.SearchFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Limit != reservations.DefaultSearchLimit || result.Total != 5 || len(result.Results) != 5 {
+		t.Fatalf("expected the 5 reservations with the default limit, got %d of %d with limit %d", len(result.Results), result.Total, result.Limit)
+	}
+
+	pages := []struct {
+		limit, offset int
+		expected      []string
+	}{
+		{2, 0, ids[0:2]},
+		{2, 2, ids[2:4]},
+		{2, 4, ids[4:5]},
+		{2, 6, nil},
+		{reservations.MaxSearchLimit, 3, ids[3:5]},
+	}
+	for _, page := range pages {
+		result, err := f.service.Search(ctx, reservations.SearchFilter{Limit: page.limit, Offset: page.offset})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Total != 5 || result.Limit != page.limit || result.Offset != page.offset || len(result.Results) != len(page.expected) {
+			t.Fatalf("limit %d offset %d: expected %d of 5, got %d of %d", page.limit, page.offset, len(page.expected), len(result.Results), result.Total)
+		}
+		for i, reservation := range result.Results {
+			if reservation.ID != page.expected[i] {
+				t.Fatalf("limit %d offset %d: expected %s at %d, got %s", page.limit, page.offset, page.expected[i], i, reservation.ID)
+			}
+		}
+	}
+
+	// Export ignora la paginación y recorre todo el filtro
+	exported := 0
+	if err := f.service.Export(ctx, reservations.SearchFilter{Limit: 1, Offset: 4}, func(reservations.Reservation) error {
+		exported++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if exported != 5 {
+		t.Fatalf("expected the export to include the 5 reservations, got %d", exported)
+	}
+}
@@ -0,0 +1,143 @@
+package reservations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotels-api/domain/reservations"
+	"log"
+	"time"
+)
+
+// JoinWaitlist anota al huésped para un tipo de habitación en las fechas pedidas.
+func (s Service) JoinWaitlist(ctx context.Context, entry reservations.WaitlistEntry) (string, error) {
+	hotel, err := s.hotelsRepository.GetHotelByID(ctx, entry.HotelID)
+	if err != nil {
+		return "", fmt.Errorf("error getting hotel: %w", err)
+	}
+	now := time.Now().UTC()
+	if err := validateStay(hotel, entry.StartDate, entry.EndDate, now); err != nil {
+		return "", err
+	}
+
+	// Se valida con la misma lógica que una reserva para que la oferta no falle después
+	stay := reservations.Reservation{
+		HotelID:    entry.HotelID,
+		RoomTypeID: entry.RoomTypeID,
+		StartDate:  entry.StartDate,
+		EndDate:    entry.EndDate,
+		Guests:     entry.Guests,
+	}
+	if _, err := s.prepareStay(ctx, &stay); err != nil {
+		return "", err
+	}
+
+	entry.Guests = stay.Guests
+	entry.Status = reservations.WaitlistWaiting
+	entry.CreatedAt = now
+	entry.ReservationID = ""
+	entry.OfferedAt = nil
+	id, err := s.waitlistRepository.Create(ctx, entry)
+	if err != nil {
+		return "", fmt.Errorf("error joining waitlist: %w", err)
+	}
+	return id, nil
+}
+
+func (s Service) GetWaitlistEntry(ctx context.Context, id string) (reservations.WaitlistEntry, error) {
+	return s.waitlistRepository.GetByID(ctx, id)
+}
+
+func (s Service) GetWaitlistByUserID(ctx context.Context, userID string) ([]reservations.WaitlistEntry, error) {
+	return s.waitlistRepository.GetByUserID(ctx, userID)
+}
+
+func (s Service) GetWaitlistByHotelID(ctx context.Context, hotelID string) ([]reservations.WaitlistEntry, error) {
+	return s.waitlistRepository.GetByHotelID(ctx, hotelID)
+}
+
+// LeaveWaitlist saca al huésped de la lista. Una entrada que ya recibió una oferta no se puede
+// dejar; para liberar el lugar hay que cancelar la reserva ofrecida.
+func (s Service) LeaveWaitlist(ctx context.Context, id string) (reservations.WaitlistEntry, error) {
+	entry, err := s.waitlistRepository.GetByID(ctx, id)
+	if err != nil {
+		return reservations.WaitlistEntry{}, err
+	}
+	if err := s.waitlistRepository.Leave(ctx, id); err != nil {
+		return reservations.WaitlistEntry{}, err
+	}
+	entry.Status = reservations.WaitlistLeft
+	return entry, nil
+}
+
+// promoteReservation ofrece a la lista de espera las noches que ocupaba la reserva, para cada
+// tipo de habitación que tenía.
+func (s Service) promoteReservation(ctx context.Context, reservation reservations.Reservation) {
+	promoted := make(map[string]bool)
+	for _, item := range reservation.LineItems() {
+		if !promoted[item.RoomTypeID] {
+			promoted[item.RoomTypeID] = true
+			s.promote(ctx, item.RoomTypeID, reservation.StartDate, reservation.EndDate)
+		}
+	}
+}
+
+// promote ofrece las noches liberadas de [from, to) a las entradas en espera, de la más antigua
+// a la más nueva. Las que no entran en el inventario libre siguen esperando. Los errores solo se
+// registran: la cancelación o modificación que liberó las noches ya se aplicó.
+func (s Service) promote(ctx context.Context, roomTypeID string, from, to reservations.Date) {
+	entries, err := s.waitlistRepository.GetWaiting(ctx, roomTypeID, from, to)
+	if err != nil {
+		log.Printf("error getting waitlist for room type %s: %v", roomTypeID, err)
+		return
+	}
+	for _, entry := range entries {
+		err := s.offer(ctx, entry)
+		switch {
+		case err == nil:
+			log.Printf("Reserva ofrecida a la entrada de lista de espera %s", entry.ID)
+		case errors.Is(err, reservations.ErrNoAvailability), errors.Is(err, reservations.ErrInvalidDates):
+			// Todavía no hay lugar para esta estadía, o ya pasó
+		default:
+			log.Printf("error offering waitlist entry %s: %v", entry.ID, err)
+		}
+	}
+}
+
+// offer crea la reserva pendiente para la entrada y la marca como ofrecida. Si otra liberación
+// ya le ofreció una habitación, la reserva recién creada se cancela para devolver el inventario.
+func (s Service) offer(ctx context.Context, entry reservations.WaitlistEntry) error {
+	now := time.Now().UTC()
+	expiresAt := now.Add(reservations.OfferTTL)
+	id, err := s.book(ctx, reservations.Reservation{
+		HotelID:         entry.HotelID,
+		RoomTypeID:      entry.RoomTypeID,
+		UserID:          entry.UserID,
+		StartDate:       entry.StartDate,
+		EndDate:         entry.EndDate,
+		Guests:          entry.Guests,
+		ExpiresAt:       &expiresAt,
+		WaitlistEntryID: entry.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.waitlistRepository.Offer(ctx, entry.ID, id, now); err != nil {
+		reservation, getErr := s.repository.GetByID(ctx, id)
+		if getErr != nil {
+			return fmt.Errorf("error releasing duplicated offer %s: %w", id, getErr)
+		}
+		cancellation := reservations.Cancellation{CancelledAt: now}
+		if cancelErr := s.repository.Cancel(ctx, id, reservations.StatusPending, cancellation); cancelErr != nil {
+			return fmt.Errorf("error releasing duplicated offer %s: %w", id, cancelErr)
+		}
+		s.invalidateAvailability(ctx, entry.HotelID)
+
+		reservation.Status = reservations.StatusCancelled
+		reservation.Cancellation = &cancellation
+		s.publish(reservations.EventCancelled, reservation)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package reservations_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hotels-api/domain/reservations"
+)
+
+func (f fixture) join(t *testing.T, userID string, start reservations.Date, nights int) string {
+	t.Helper()
+	id, err := f.service.JoinWaitlist(context.Background(), reservations.WaitlistEntry{
+		HotelID:    f.hotelID,
+		RoomTypeID: f.roomTypeID,
+		UserID:     userID,
+		StartDate:  start,
+		EndDate:    start.AddDays(nights),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Las entradas se ordenan por antigüedad
+	time.Sleep(time.Millisecond)
+	return id
+}
+
+func TestCancelOffersRoomToOldestWaitlistEntry(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+
+	id, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.CreateReservation(ctx, f.reservation("user-5", f.start.AddDays(2), 2)); err != nil {
+		t.Fatal(err)
+	}
+	tooLong := f.join(t, "user-2", f.start, 4)
+	first := f.join(t, "user-3", f.start, 2)
+	second := f.join(t, "user-4", f.start.AddDays(1), 1)
+
+	if _, err := f.service.Cancel(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	offered, _ := f.waitlist.GetByID(ctx, first)
+	if offered.Status != reservations.WaitlistOffered || offered.ReservationID == "" {
+		t.Fatalf("expected oldest matching entry to get an offer, got %+v", offered)
+	}
+	for _, waiting := range []string{tooLong, second} {
+		if entry, _ := f.waitlist.GetByID(ctx, waiting); entry.Status != reservations.WaitlistWaiting {
+			t.Fatalf("expected entry %s to keep waiting, got %s", waiting, entry.Status)
+		}
+	}
+
+	offer, err := f.service.GetReservationByID(ctx, offered.ReservationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offer.Status != reservations.StatusPending || offer.UserID != "user-3" || offer.ExpiresAt == nil {
+		t.Fatalf("expected an expiring pending reservation for user-3, got %+v", offer)
+	}
+}
+
+func TestExpiredOfferMovesToNextEntry(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+
+	id, err := f.service.CreateReservation(ctx, f.reservation("user-1", f.start, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := f.join(t, "user-2", f.start, 2)
+	second := f.join(t, "user-3", f.start, 2)
+	if _, err := f.service.Cancel(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.service.ExpirePending(ctx, time.Now().UTC().Add(reservations.OfferTTL+time.Minute), 24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	expired, _ := f.waitlist.GetByID(ctx, first)
+	if offer, _ := f.service.GetReservationByID(ctx, expired.ReservationID); offer.Status != reservations.StatusCancelled {
+		t.Fatalf("expected expired offer to be cancelled, got %s", offer.Status)
+	}
+	next, _ := f.waitlist.GetByID(ctx, second)
+	if next.Status != reservations.WaitlistOffered {
+		t.Fatalf("expected the next entry to get the room, got %s", next.Status)
+	}
+	if count := f.repository.Booked(f.roomTypeID, f.start); count != 1 {
+		t.Fatalf("expected exactly one room taken, got %d", count)
+	}
+}
@@ -0,0 +1,181 @@
+package reviews
+
+import (
+	"context"
+	"fmt"
+	hotelsDomain "hotels-api/domain/hotels"
+	"hotels-api/domain/reservations"
+	"hotels-api/domain/reviews"
+	"log"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type Repository interface {
+	Create(ctx context.Context, review reviews.Review) (string, error)
+	GetByID(ctx context.Context, id string) (reviews.Review, error)
+	GetByHotelID(ctx context.Context, hotelID, status string) ([]reviews.Review, error)
+	GetByStatus(ctx context.Context, status string) ([]reviews.Review, error)
+	Moderate(ctx context.Context, id string, from, to string, decision reviews.Decision) error
+	SetReply(ctx context.Context, id string, reply reviews.Reply) error
+	GetSummary(ctx context.Context, hotelID string) (reviews.Summary, error)
+}
+
+type ReservationsRepository interface {
+	GetByID(ctx context.Context, id string) (reservations.Reservation, error)
+}
+
+type HotelsRepository interface {
+	SetRating(ctx context.Context, id string, rating float64, reviewCount int) error
+}
+
+type HotelsCache interface {
+	Delete(ctx context.Context, id string) error
+}
+
+type Queue interface {
+	Publish(hotelNew hotelsDomain.HotelNew) error
+}
+
+type Service struct {
+	repository             Repository
+	reservationsRepository ReservationsRepository
+	hotelsRepository       HotelsRepository
+	hotelsCache            HotelsCache
+	eventsQueue            Queue
+}
+
+func NewService(repository Repository, reservationsRepository ReservationsRepository, hotelsRepository HotelsRepository, hotelsCache HotelsCache, eventsQueue Queue) Service {
+	return Service{
+		repository:             repository,
+		reservationsRepository: reservationsRepository,
+		hotelsRepository:       hotelsRepository,
+		hotelsCache:            hotelsCache,
+		eventsQueue:            eventsQueue,
+	}
+}
+
+// Create guarda la reseña de una estadía terminada del usuario. La reseña queda pendiente de
+// moderación y no cuenta para la calificación hasta que se aprueba.
+func (s Service) Create(ctx context.Context, review reviews.Review, now time.Time) (reviews.Review, error) {
+	review.Text = strings.TrimSpace(review.Text)
+	if review.Score < reviews.MinScore || review.Score > reviews.MaxScore {
+		return reviews.Review{}, fmt.Errorf("score must be between %d and %d: %w", reviews.MinScore, reviews.MaxScore, reviews.ErrInvalidReview)
+	}
+	if review.Text == "" || utf8.RuneCountInString(review.Text) > reviews.MaxTextLength {
+		return reviews.Review{}, fmt.Errorf("text is required and up to %d characters: %w", reviews.MaxTextLength, reviews.ErrInvalidReview)
+	}
+
+	// Solo reseña quien se alojó: la reserva tiene que ser suya, de este hotel y con check-out
+	reservation, err := s.reservationsRepository.GetByID(ctx, review.ReservationID)
+	if err != nil || reservation.UserID != review.UserID || reservation.HotelID != review.HotelID {
+		return reviews.Review{}, fmt.Errorf("reservation %q: %w", review.ReservationID, reviews.ErrNotEligible)
+	}
+	if reservation.Status != reservations.StatusCheckedOut {
+		return reviews.Review{}, fmt.Errorf("reservation is %s, not %s: %w", reservation.Status, reservations.StatusCheckedOut, reviews.ErrNotEligible)
+	}
+
+	review.ID = ""
+	review.Status = reviews.StatusPending
+	review.CreatedAt = now
+	review.Moderation, review.Reply = nil, nil
+	id, err := s.repository.Create(ctx, review)
+	if err != nil {
+		return reviews.Review{}, err
+	}
+
+	review.ID = id
+	return review, nil
+}
+
+// GetPublished devuelve las reseñas aprobadas del hotel, de la más nueva a la más vieja.
+func (s Service) GetPublished(ctx context.Context, hotelID string) ([]reviews.Review, error) {
+	return s.repository.GetByHotelID(ctx, hotelID, reviews.StatusApproved)
+}
+
+// GetQueue devuelve las reseñas de todos los hoteles que esperan moderación, primero las más viejas.
+func (s Service) GetQueue(ctx context.Context) ([]reviews.Review, error) {
+	return s.repository.GetByStatus(ctx, reviews.StatusPending)
+}
+
+// Approve publica una reseña pendiente y recalcula la calificación del hotel.
+func (s Service) Approve(ctx context.Context, id, moderatorID string, now time.Time) (reviews.Review, error) {
+	return s.moderate(ctx, id, reviews.StatusApproved, reviews.Decision{ModeratorID: moderatorID, At: now})
+}
+
+// Reject descarta una reseña pendiente o da de baja una ya publicada; en ese caso deja de contar
+// para la calificación del hotel.
+func (s Service) Reject(ctx context.Context, id, moderatorID, reason string, now time.Time) (reviews.Review, error) {
+	return s.moderate(ctx, id, reviews.StatusRejected, reviews.Decision{ModeratorID: moderatorID, Reason: strings.TrimSpace(reason), At: now})
+}
+
+func (s Service) moderate(ctx context.Context, id, to string, decision reviews.Decision) (reviews.Review, error) {
+	review, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return reviews.Review{}, err
+	}
+	if review.Status == to || review.Status == reviews.StatusRejected {
+		return reviews.Review{}, fmt.Errorf("review is already %s: %w", review.Status, reviews.ErrInvalidModeration)
+	}
+	if err := s.repository.Moderate(ctx, id, review.Status, to, decision); err != nil {
+		return reviews.Review{}, err
+	}
+
+	// La calificación cambia al publicar una reseña o al dar de baja una publicada
+	if to == reviews.StatusApproved || review.Status == reviews.StatusApproved {
+		s.refreshRating(ctx, review.HotelID)
+	}
+	review.Status, review.Moderation = to, &decision
+	return review, nil
+}
+
+// Reply publica la respuesta del hotel a una de sus reseñas aprobadas.
+func (s Service) Reply(ctx context.Context, hotelID, id string, reply reviews.Reply) (reviews.Review, error) {
+	reply.Text = strings.TrimSpace(reply.Text)
+	if reply.Text == "" || utf8.RuneCountInString(reply.Text) > reviews.MaxTextLength {
+		return reviews.Review{}, fmt.Errorf("reply is required and up to %d characters: %w", reviews.MaxTextLength, reviews.ErrInvalidReview)
+	}
+	review, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return reviews.Review{}, err
+	}
+	if review.HotelID != hotelID {
+		return reviews.Review{}, fmt.Errorf("review %s: %w", id, reviews.ErrNotFound)
+	}
+	if review.Status != reviews.StatusApproved {
+		return reviews.Review{}, fmt.Errorf("only approved reviews can be answered: %w", reviews.ErrInvalidReview)
+	}
+	if err := s.repository.SetReply(ctx, id, reply); err != nil {
+		return reviews.Review{}, err
+	}
+
+	review.Reply = &reply
+	return review, nil
+}
+
+// refreshRating recalcula la calificación del hotel con todas sus reseñas aprobadas y avisa a
+// search-api para que reindexe el hotel. La moderación ya quedó guardada, así que un error acá
+// solo se registra: la próxima moderación del hotel vuelve a calcular desde cero.
+func (s Service) refreshRating(ctx context.Context, hotelID string) {
+	summary, err := s.repository.GetSummary(ctx, hotelID)
+	if err != nil {
+		log.Printf("Error computing rating of hotel %s: %v", hotelID, err)
+		return
+	}
+	rating := math.Round(summary.Rating*10) / 10
+	if err := s.hotelsRepository.SetRating(ctx, hotelID, rating, summary.Count); err != nil {
+		log.Printf("Error saving rating of hotel %s: %v", hotelID, err)
+		return
+	}
+	if err := s.hotelsCache.Delete(ctx, hotelID); err != nil {
+		log.Printf("Error invalidating hotel %s in cache: %v", hotelID, err)
+	}
+	if err := s.eventsQueue.Publish(hotelsDomain.HotelNew{
+		Operation: "UPDATE",
+		HotelID:   hotelID,
+	}); err != nil {
+		log.Printf("Error publishing rating update of hotel %s: %v", hotelID, err)
+	}
+}
@@ -0,0 +1,197 @@
+package reviews_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hotels-api/clients/queues"
+	hotelsDAO "hotels-api/dao/hotels"
+	"hotels-api/domain/reservations"
+	"hotels-api/domain/reviews"
+	repositoriesHotels "hotels-api/repositories/hotels"
+	repositoriesReservations "hotels-api/repositories/reservations"
+	repositoriesReviews "hotels-api/repositories/reviews"
+	service "hotels-api/services/reviews"
+)
+
+type fixture struct {
+	service      service.Service
+	reservations repositoriesReservations.Mock
+	hotels       repositoriesHotels.Mock
+	events       queues.Mock
+	hotelID      string
+	now          time.Time
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+	hotelsRepo := repositoriesHotels.NewMock()
+	hotelID, err := hotelsRepo.Create(context.Background(), hotelsDAO.Hotel{Name: "Hotel Sierras", TimeZone: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := fixture{
+		reservations: repositoriesReservations.NewMock(),
+		hotels:       hotelsRepo,
+		events:       queues.NewMock(),
+		hotelID:      hotelID,
+		now:          time.Now().UTC(),
+	}
+	f.service = service.NewService(repositoriesReviews.NewMock(), f.reservations, hotelsRepo, repositoriesHotels.NewCache(repositoriesHotels.CacheConfig{
+		MaxSize:      1000,
+		ItemsToPrune: 10,
+		Duration:     time.Minute,
+	}), f.events)
+	return f
+}
+
+// stay guarda una reserva del usuario en el estado pedido y devuelve su ID.
+func (f fixture) stay(t *testing.T, userID, status string) string {
+	t.Helper()
+	start := reservations.DateOf(f.now).AddDays(-3)
+	id, err := f.reservations.Book(context.Background(), reservations.Reservation{
+		HotelID:   f.hotelID,
+		UserID:    userID,
+		StartDate: start,
+		EndDate:   start.AddDays(2),
+		Status:    status,
+		Items:     []reservations.LineItem{{RoomTypeID: "doble", Quantity: 1, Adults: 2}},
+	}, map[string]int{"doble": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func (f fixture) review(t *testing.T, userID string, score int) reviews.Review {
+	t.Helper()
+	review, err := f.service.Create(context.Background(), reviews.Review{
+		HotelID:       f.hotelID,
+		ReservationID: f.stay(t, userID, reservations.StatusCheckedOut),
+		UserID:        userID,
+		Score:         score,
+		Text:          "Muy buena atención",
+	}, f.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return review
+}
+
+func (f fixture) rating(t *testing.T) (float64, int) {
+	t.Helper()
+	hotel, err := f.hotels.GetHotelByID(context.Background(), f.hotelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hotel.Rating, hotel.ReviewCount
+}
+
+func TestOnlyCompletedStaysCanBeReviewed(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	confirmed := f.stay(t, "7", reservations.StatusConfirmed)
+	completed := f.stay(t, "7", reservations.StatusCheckedOut)
+
+	cases := map[string]struct {
+		review reviews.Review
+		err    error
+	}{
+		"stay not completed": {reviews.Review{HotelID: f.hotelID, ReservationID: confirmed, UserID: "7", Score: 4, Text: "Bien"}, reviews.ErrNotEligible},
+		"someone else's":     {reviews.Review{HotelID: f.hotelID, ReservationID: completed, UserID: "8", Score: 4, Text: "Bien"}, reviews.ErrNotEligible},
+		"another hotel":      {reviews.Review{HotelID: "otro", ReservationID: completed, UserID: "7", Score: 4, Text: "Bien"}, reviews.ErrNotEligible},
+		"score out of range": {reviews.Review{HotelID: f.hotelID, ReservationID: completed, UserID: "7", Score: 6, Text: "Bien"}, reviews.ErrInvalidReview},
+		"empty text":         {reviews.Review{HotelID: f.hotelID, ReservationID: completed, UserID: "7", Score: 4, Text: "  "}, reviews.ErrInvalidReview},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := f.service.Create(ctx, c.review, f.now); !errors.Is(err, c.err) {
+				t.Fatalf("expected %v, got %v", c.err, err)
+			}
+		})
+	}
+
+	review := reviews.Review{HotelID: f.hotelID, ReservationID: completed, UserID: "7", Score: 4, Text: "Bien"}
+	created, err := f.service.Create(ctx, review, f.now)
+	if err != nil || created.Status != reviews.StatusPending {
+		t.Fatalf("expected a pending review, got %+v, %v", created, err)
+	}
+	if _, err := f.service.Create(ctx, review, f.now); !errors.Is(err, reviews.ErrAlreadyReviewed) {
+		t.Fatalf("expected a single review per stay, got %v", err)
+	}
+}
+
+func TestModerationRecomputesTheHotelRating(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	first := f.review(t, "7", 5)
+	second := f.review(t, "8", 4)
+
+	// Las reseñas pendientes no cuentan ni se publican
+	if published, err := f.service.GetPublished(ctx, f.hotelID); err != nil || len(published) != 0 {
+		t.Fatalf("expected no published reviews, got %+v, %v", published, err)
+	}
+	if queue, err := f.service.GetQueue(ctx); err != nil || len(queue) != 2 {
+		t.Fatalf("expected 2 reviews in the queue, got %+v, %v", queue, err)
+	}
+
+	if _, err := f.service.Approve(ctx, first.ID, "admin", f.now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Approve(ctx, second.ID, "admin", f.now); err != nil {
+		t.Fatal(err)
+	}
+	if rating, count := f.rating(t); rating != 4.5 || count != 2 {
+		t.Fatalf("expected rating 4.5 from 2 reviews, got %v from %d", rating, count)
+	}
+	if _, err := f.service.Approve(ctx, first.ID, "admin", f.now); !errors.Is(err, reviews.ErrInvalidModeration) {
+		t.Fatalf("expected the review already approved, got %v", err)
+	}
+
+	// Dar de baja una reseña publicada la saca del promedio
+	if _, err := f.service.Reject(ctx, first.ID, "admin", "Datos personales", f.now); err != nil {
+		t.Fatal(err)
+	}
+	if rating, count := f.rating(t); rating != 4 || count != 1 {
+		t.Fatalf("expected rating 4 from 1 review, got %v from %d", rating, count)
+	}
+
+	// Cada cambio de calificación avisa a search-api
+	events := f.events.Events()
+	if len(events) != 3 || events[2].Operation != "UPDATE" || events[2].HotelID != f.hotelID {
+		t.Fatalf("expected 3 hotel updates, got %+v", events)
+	}
+	// Rechazar una pendiente no cambia la calificación
+	if _, err := f.service.Reject(ctx, f.review(t, "9", 1).ID, "admin", "Spam", f.now); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.events.Events()) != 3 {
+		t.Fatalf("expected no update for a rejected pending review, got %+v", f.events.Events())
+	}
+}
+
+func TestReplyOnlyToTheHotelApprovedReviews(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	review := f.review(t, "7", 3)
+	reply := reviews.Reply{AuthorID: "admin", Text: "Gracias por su visita", At: f.now}
+
+	if _, err := f.service.Reply(ctx, f.hotelID, review.ID, reply); !errors.Is(err, reviews.ErrInvalidReview) {
+		t.Fatalf("expected a pending review not to be answered, got %v", err)
+	}
+	if _, err := f.service.Approve(ctx, review.ID, "admin", f.now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Reply(ctx, "otro", review.ID, reply); !errors.Is(err, reviews.ErrNotFound) {
+		t.Fatalf("expected another hotel's review to be hidden, got %v", err)
+	}
+	if _, err := f.service.Reply(ctx, f.hotelID, review.ID, reply); err != nil {
+		t.Fatal(err)
+	}
+	published, err := f.service.GetPublished(ctx, f.hotelID)
+	if err != nil || len(published) != 1 || published[0].Reply == nil || published[0].Reply.Text != reply.Text {
+		t.Fatalf("expected the reply on the published review, got %+v, %v", published, err)
+	}
+}
@@ -0,0 +1,214 @@
+package rooms
+
+import (
+	"context"
+	"fmt"
+	hotelsDAO "hotels-api/dao/hotels"
+	roomsDAO "hotels-api/dao/rooms"
+	pricingDomain "hotels-api/domain/pricing"
+	"hotels-api/domain/reservations"
+	roomsDomain "hotels-api/domain/rooms"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Repository interface {
+	GetByHotelID(ctx context.Context, hotelID string) ([]roomsDAO.RoomType, error)
+	GetByID(ctx context.Context, id string) (roomsDAO.RoomType, error)
+	Create(ctx context.Context, roomType roomsDAO.RoomType) (string, error)
+	Update(ctx context.Context, roomType roomsDAO.RoomType) error
+	Delete(ctx context.Context, hotelID string, id string) error
+}
+
+type HotelsRepository interface {
+	GetHotelByID(ctx context.Context, id string) (hotelsDAO.Hotel, error)
+}
+
+type Service struct {
+	repository       Repository
+	hotelsRepository HotelsRepository
+}
+
+func NewService(repository Repository, hotelsRepository HotelsRepository) Service {
+	return Service{
+		repository:       repository,
+		hotelsRepository: hotelsRepository,
+	}
+}
+
+func (service Service) GetByHotelID(ctx context.Context, hotelID string) ([]roomsDomain.RoomType, error) {
+	records, err := service.repository.GetByHotelID(ctx, hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting room types: %w", err)
+	}
+
+	result := make([]roomsDomain.RoomType, 0, len(records))
+	for _, record := range records {
+		result = append(result, toDomain(record))
+	}
+	return result, nil
+}
+
+func (service Service) GetByID(ctx context.Context, hotelID string, id string) (roomsDomain.RoomType, error) {
+	record, err := service.repository.GetByID(ctx, id)
+	if err != nil {
+		return roomsDomain.RoomType{}, fmt.Errorf("error getting room type: %w", err)
+	}
+	if record.HotelID != hotelID {
+		return roomsDomain.RoomType{}, fmt.Errorf("room type %s does not belong to hotel %s", id, hotelID)
+	}
+	return toDomain(record), nil
+}
+
+func (service Service) Create(ctx context.Context, roomType roomsDomain.RoomType) (string, error) {
+	if roomType.Name == "" || roomType.Capacity <= 0 || roomType.Count < 0 {
+		return "", fmt.Errorf("name and a positive capacity are required: %w", roomsDomain.ErrInvalidRoomType)
+	}
+	if err := validateRatePlan(roomType.RatePlan); err != nil {
+		return "", err
+	}
+	if _, err := service.hotelsRepository.GetHotelByID(ctx, roomType.HotelID); err != nil {
+		return "", fmt.Errorf("error getting hotel: %w", err)
+	}
+
+	id, err := service.repository.Create(ctx, toDAO(roomType))
+	if err != nil {
+		return "", fmt.Errorf("error creating room type: %w", err)
+	}
+	return id, nil
+}
+
+func (service Service) Update(ctx context.Context, roomType roomsDomain.RoomType) error {
+	if roomType.Capacity < 0 || roomType.Count < 0 {
+		return fmt.Errorf("capacity and count cannot be negative: %w", roomsDomain.ErrInvalidRoomType)
+	}
+	if err := validateRatePlan(roomType.RatePlan); err != nil {
+		return err
+	}
+	record := toDAO(roomType)
+	objectID, err := primitive.ObjectIDFromHex(roomType.ID)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+	record.ID = objectID
+
+	if err := service.repository.Update(ctx, record); err != nil {
+		return fmt.Errorf("error updating room type: %w", err)
+	}
+	return nil
+}
+
+// SetRatePlan reemplaza el plan de tarifas del tipo de habitación.
+func (service Service) SetRatePlan(ctx context.Context, hotelID string, id string, plan pricingDomain.RatePlan) error {
+	return service.Update(ctx, roomsDomain.RoomType{
+		ID:       id,
+		HotelID:  hotelID,
+		RatePlan: &plan,
+	})
+}
+
+func (service Service) Delete(ctx context.Context, hotelID string, id string) error {
+	if err := service.repository.Delete(ctx, hotelID, id); err != nil {
+		return fmt.Errorf("error deleting room type: %w", err)
+	}
+	return nil
+}
+
+func toDomain(record roomsDAO.RoomType) roomsDomain.RoomType {
+	beds := make([]roomsDomain.Bed, 0, len(record.Beds))
+	for _, bed := range record.Beds {
+		beds = append(beds, roomsDomain.Bed{Type: bed.Type, Quantity: bed.Quantity})
+	}
+	return roomsDomain.RoomType{
+		ID:       record.ID.Hex(),
+		HotelID:  record.HotelID,
+		Name:     record.Name,
+		Capacity: record.Capacity,
+		Beds:     beds,
+		Count:    record.Count,
+		RatePlan: ratePlanToDomain(record.RatePlan),
+	}
+}
+
+func toDAO(roomType roomsDomain.RoomType) roomsDAO.RoomType {
+	beds := make([]roomsDAO.Bed, 0, len(roomType.Beds))
+	for _, bed := range roomType.Beds {
+		beds = append(beds, roomsDAO.Bed{Type: bed.Type, Quantity: bed.Quantity})
+	}
+	return roomsDAO.RoomType{
+		HotelID:  roomType.HotelID,
+		Name:     roomType.Name,
+		Capacity: roomType.Capacity,
+		Beds:     beds,
+		Count:    roomType.Count,
+		RatePlan: ratePlanToDAO(roomType.RatePlan),
+	}
+}
+
+func validateRatePlan(plan *pricingDomain.RatePlan) error {
+	if plan == nil {
+		return nil
+	}
+	if plan.Currency == "" || plan.BaseRate <= 0 {
+		return fmt.Errorf("rate plan needs a currency and a positive base_rate: %w", roomsDomain.ErrInvalidRoomType)
+	}
+	if plan.WeekendSurchargePercent < 0 {
+		return fmt.Errorf("weekend_surcharge_percent cannot be negative: %w", roomsDomain.ErrInvalidRoomType)
+	}
+	for _, season := range plan.Seasons {
+		start, startErr := time.Parse(reservations.DateLayout, season.StartDate)
+		end, endErr := time.Parse(reservations.DateLayout, season.EndDate)
+		if startErr != nil || endErr != nil || end.Before(start) || season.Rate <= 0 {
+			return fmt.Errorf("season %q needs valid dates and a positive rate: %w", season.Name, roomsDomain.ErrInvalidRoomType)
+		}
+	}
+	for _, discount := range plan.LengthOfStayDiscounts {
+		if discount.MinNights < 1 || discount.DiscountPercent < 0 || discount.DiscountPercent > 100 {
+			return fmt.Errorf("length of stay discounts need min_nights >= 1 and a percent between 0 and 100: %w", roomsDomain.ErrInvalidRoomType)
+		}
+	}
+	return nil
+}
+
+func ratePlanToDomain(plan *roomsDAO.RatePlan) *pricingDomain.RatePlan {
+	if plan == nil {
+		return nil
+	}
+	seasons := make([]pricingDomain.Season, 0, len(plan.Seasons))
+	for _, season := range plan.Seasons {
+		seasons = append(seasons, pricingDomain.Season(season))
+	}
+	discounts := make([]pricingDomain.StayDiscount, 0, len(plan.LengthOfStayDiscounts))
+	for _, discount := range plan.LengthOfStayDiscounts {
+		discounts = append(discounts, pricingDomain.StayDiscount(discount))
+	}
+	return &pricingDomain.RatePlan{
+		Currency:                plan.Currency,
+		BaseRate:                plan.BaseRate,
+		WeekendSurchargePercent: plan.WeekendSurchargePercent,
+		Seasons:                 seasons,
+		LengthOfStayDiscounts:   discounts,
+	}
+}
+
+func ratePlanToDAO(plan *pricingDomain.RatePlan) *roomsDAO.RatePlan {
+	if plan == nil {
+		return nil
+	}
+	seasons := make([]roomsDAO.Season, 0, len(plan.Seasons))
+	for _, season := range plan.Seasons {
+		seasons = append(seasons, roomsDAO.Season(season))
+	}
+	discounts := make([]roomsDAO.StayDiscount, 0, len(plan.LengthOfStayDiscounts))
+	for _, discount := range plan.LengthOfStayDiscounts {
+		discounts = append(discounts, roomsDAO.StayDiscount(discount))
+	}
+	return &roomsDAO.RatePlan{
+		Currency:                plan.Currency,
+		BaseRate:                plan.BaseRate,
+		WeekendSurchargePercent: plan.WeekendSurchargePercent,
+		Seasons:                 seasons,
+		LengthOfStayDiscounts:   discounts,
+	}
+}
@@ -0,0 +1,152 @@
+package rooms_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	hotelsDAO "hotels-api/dao/hotels"
+	pricingDomain "hotels-api/domain/pricing"
+	roomsDomain "hotels-api/domain/rooms"
+	repositoriesHotels "hotels-api/repositories/hotels"
+	repositoriesRooms "hotels-api/repositories/rooms"
+	"hotels-api/services/rooms"
+)
+
+func newService(t *testing.T) (rooms.Service, string) {
+	t.Helper()
+	hotels := repositoriesHotels.NewMock()
+	hotelID, err := hotels.Create(context.Background(), hotelsDAO.Hotel{Name: "Hotel Sierras"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rooms.NewService(repositoriesRooms.NewMock(), hotels), hotelID
+}
+
+func TestCreateValidatesRoomType(t *testing.T) {
+	s, hotelID := newService(t)
+	ctx := context.Background()
+
+	cases := map[string]roomsDomain.RoomType{
+		"missing name":      {HotelID: hotelID, Capacity: 2},
+		"zero capacity":     {HotelID: hotelID, Name: "Doble"},
+		"negative count":    {HotelID: hotelID, Name: "Doble", Capacity: 2, Count: -1},
+		"rate without base": {HotelID: hotelID, Name: "Doble", Capacity: 2, RatePlan: &pricingDomain.RatePlan{Currency: "ARS"}},
+		"inverted season": {HotelID: hotelID, Name: "Doble", Capacity: 2, RatePlan: &pricingDomain.RatePlan{
+			Currency: "ARS", BaseRate: 100,
+			Seasons: []pricingDomain.Season{{Name: "Verano", StartDate: "2026-03-01", EndDate: "2026-01-01", Rate: 150}},
+		}},
+		"discount over 100": {HotelID: hotelID, Name: "Doble", Capacity: 2, RatePlan: &pricingDomain.RatePlan{
+			Currency: "ARS", BaseRate: 100,
+			LengthOfStayDiscounts: []pricingDomain.StayDiscount{{MinNights: 7, DiscountPercent: 120}},
+		}},
+	}
+	for name, roomType := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Create(ctx, roomType); !errors.Is(err, roomsDomain.ErrInvalidRoomType) {
+				t.Fatalf("expected ErrInvalidRoomType, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRoomTypesBelongToTheirHotel(t *testing.T) {
+	s, hotelID := newService(t)
+	ctx := context.Background()
+
+	id, err := s.Create(ctx, roomsDomain.RoomType{
+		HotelID:  hotelID,
+		Name:     "Doble",
+		Capacity: 2,
+		Count:    5,
+		Beds:     []roomsDomain.Bed{{Type: "queen", Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roomType, err := s.GetByID(ctx, hotelID, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roomType.Name != "Doble" || roomType.Capacity != 2 || roomType.Count != 5 || len(roomType.Beds) != 1 {
+		t.Fatalf("unexpected room type %+v", roomType)
+	}
+	if _, err := s.GetByID(ctx, "otro-hotel", id); err == nil {
+		t.Fatal("expected an error getting the room type through another hotel")
+	}
+
+	list, err := s.GetByHotelID(ctx, hotelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != id {
+		t.Fatalf("expected only the created room type, got %+v", list)
+	}
+
+	if err := s.Delete(ctx, "otro-hotel", id); err == nil {
+		t.Fatal("expected an error deleting the room type through another hotel")
+	}
+	if err := s.Delete(ctx, hotelID, id); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := s.GetByHotelID(ctx, hotelID); len(list) != 0 {
+		t.Fatalf("expected no room types after deleting, got %+v", list)
+	}
+}
+
+func TestUpdateOnlyChangesTheGivenFields(t *testing.T) {
+	s, hotelID := newService(t)
+	ctx := context.Background()
+
+	id, err := s.Create(ctx, roomsDomain.RoomType{HotelID: hotelID, Name: "Doble", Capacity: 2, Count: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(ctx, roomsDomain.RoomType{ID: id, HotelID: hotelID, Count: -1}); !errors.Is(err, roomsDomain.ErrInvalidRoomType) {
+		t.Fatalf("expected ErrInvalidRoomType, got %v", err)
+	}
+	if err := s.Update(ctx, roomsDomain.RoomType{ID: id, HotelID: "otro-hotel", Count: 5}); err == nil {
+		t.Fatal("expected an error updating the room type through another hotel")
+	}
+	if err := s.Update(ctx, roomsDomain.RoomType{ID: id, HotelID: hotelID, Count: 5}); err != nil {
+		t.Fatal(err)
+	}
+	roomType, err := s.GetByID(ctx, hotelID, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roomType.Name != "Doble" || roomType.Capacity != 2 || roomType.Count != 5 {
+		t.Fatalf("expected only the count to change, got %+v", roomType)
+	}
+}
+
+func TestSetRatePlanKeepsTheRestOfTheRoomType(t *testing.T) {
+	s, hotelID := newService(t)
+	ctx := context.Background()
+
+	id, err := s.Create(ctx, roomsDomain.RoomType{HotelID: hotelID, Name: "Doble", Capacity: 2, Count: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := pricingDomain.RatePlan{Currency: "ARS", BaseRate: 100, WeekendSurchargePercent: -10}
+	if err := s.SetRatePlan(ctx, hotelID, id, invalid); !errors.Is(err, roomsDomain.ErrInvalidRoomType) {
+		t.Fatalf("expected ErrInvalidRoomType, got %v", err)
+	}
+
+	plan := pricingDomain.RatePlan{Currency: "ARS", BaseRate: 100, WeekendSurchargePercent: 20}
+	if err := s.SetRatePlan(ctx, hotelID, id, plan); err != nil {
+		t.Fatal(err)
+	}
+	roomType, err := s.GetByID(ctx, hotelID, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roomType.RatePlan == nil || roomType.RatePlan.BaseRate != 100 || roomType.RatePlan.WeekendSurchargePercent != 20 {
+		t.Fatalf("expected the new rate plan, got %+v", roomType.RatePlan)
+	}
+	if roomType.Name != "Doble" || roomType.Capacity != 2 || roomType.Count != 3 {
+		t.Fatalf("expected the rest of the room type untouched, got %+v", roomType)
+	}
+}
@@ -0,0 +1,68 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+type Leases interface {
+	Acquire(ctx context.Context, name, owner string, now time.Time, duration time.Duration) (bool, error)
+}
+
+// Job es una tarea periódica. Run recibe un contexto que vence al terminar el intervalo, para que
+// una corrida no siga cuando otra instancia ya pudo tomar el lease.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context, now time.Time) error
+}
+
+// Runner ejecuta tareas periódicas en varias instancias de la API a la vez: en cada intervalo solo
+// corre la instancia que tiene el lease de la tarea. Mientras siga viva lo renueva en cada corrida;
+// si se cae, otra lo toma cuando vence.
+type Runner struct {
+	leases Leases
+	owner  string
+}
+
+func NewRunner(leases Leases, owner string) Runner {
+	return Runner{leases: leases, owner: owner}
+}
+
+// Start corre job cada Interval hasta que se cancele ctx.
+func (r Runner) Start(ctx context.Context, job Job) {
+	go func() {
+		ticker := time.NewTicker(job.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := r.RunOnce(ctx, job, time.Now().UTC()); err != nil {
+					log.Printf("Error running job %s: %v", job.Name, err)
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce ejecuta job si esta instancia consigue su lease e indica si lo ejecutó.
+func (r Runner) RunOnce(ctx context.Context, job Job, now time.Time) (bool, error) {
+	acquired, err := r.leases.Acquire(ctx, job.Name, r.owner, now, job.Interval)
+	if err != nil {
+		return false, err
+	}
+	if !acquired {
+		return false, nil
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, job.Interval)
+	defer cancel()
+	if err := job.Run(runCtx, now); err != nil {
+		return true, fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	return true, nil
+}
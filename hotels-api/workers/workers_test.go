@@ -0,0 +1,75 @@
+package workers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	repositoriesLeases "hotels-api/repositories/leases"
+	"hotels-api/workers"
+)
+
+func TestJobRunsOnOneInstanceAtATime(t *testing.T) {
+	leases := repositoriesLeases.NewMock()
+	first := workers.NewRunner(leases, "instance-1")
+	second := workers.NewRunner(leases, "instance-2")
+	runs := make([]string, 0)
+	job := func(owner string) workers.Job {
+		return workers.Job{
+			Name:     "expire",
+			Interval: time.Minute,
+			Run: func(ctx context.Context, now time.Time) error {
+				runs = append(runs, owner)
+				return nil
+			},
+		}
+	}
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	steps := []struct {
+		runner workers.Runner
+		owner  string
+		at     time.Duration
+		ran    bool
+	}{
+		{first, "instance-1", 0, true},
+		// La otra instancia no corre mientras el lease está vigente
+		{second, "instance-2", 30 * time.Second, false},
+		// El dueño lo renueva en su próximo intervalo
+		{first, "instance-1", time.Minute, true},
+		{second, "instance-2", 90 * time.Second, false},
+		// Si el dueño deja de renovarlo, otra instancia lo toma cuando vence
+		{second, "instance-2", 3 * time.Minute, true},
+		{first, "instance-1", 3*time.Minute + time.Second, false},
+	}
+	for i, step := range steps {
+		ran, err := step.runner.RunOnce(context.Background(), job(step.owner), start.Add(step.at))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ran != step.ran {
+			t.Fatalf("step %d: expected ran=%v for %s, got %v", i, step.ran, step.owner, ran)
+		}
+	}
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %v", runs)
+	}
+}
+
+func TestRunOnceReportsJobErrors(t *testing.T) {
+	failure := errors.New("mongo down")
+	runner := workers.NewRunner(repositoriesLeases.NewMock(), "instance-1")
+	ran, err := runner.RunOnce(context.Background(), workers.Job{
+		Name:     "sync",
+		Interval: time.Minute,
+		Run: func(ctx context.Context, now time.Time) error {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Error("expected the run to be bounded by the lease")
+			}
+			return failure
+		},
+	}, time.Now().UTC())
+	if !ran || !errors.Is(err, failure) {
+		t.Fatalf("expected the job error, got ran=%v err=%v", ran, err)
+	}
+}
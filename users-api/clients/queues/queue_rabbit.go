@@ -0,0 +1,129 @@
+package queues
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/streadway/amqp"
+	"log"
+	"sync"
+	"time"
+	"users-api/domain/users"
+)
+
+// RabbitConfig apunta al exchange fanout donde hotels-api publica los eventos de reservas.
+// QueueName es la cola propia de users-api unida a ese exchange, que comparten sus instancias.
+type RabbitConfig struct {
+	Host          string
+	Port          string
+	Username      string
+	Password      string
+	Exchange      string
+	QueueName     string
+	RetryInterval time.Duration
+}
+
+type Rabbit struct {
+	config    RabbitConfig
+	closed    chan struct{}
+	closeOnce *sync.Once
+}
+
+// NewRabbit no se conecta todavía: la conexión la abre StartConsumer en segundo plano, así
+// users-api arranca aunque RabbitMQ no esté disponible.
+func NewRabbit(config RabbitConfig) Rabbit {
+	if config.RetryInterval == 0 {
+		config.RetryInterval = 5 * time.Second
+	}
+	return Rabbit{
+		config:    config,
+		closed:    make(chan struct{}),
+		closeOnce: &sync.Once{},
+	}
+}
+
+// StartConsumer entrega a handler los eventos de reservas. Si RabbitMQ no responde o se pierde la
+// conexión se reintenta cada RetryInterval hasta Close. Cada mensaje se confirma recién cuando
+// handler termina bien; si falla vuelve a la cola para reintentarse, salvo que no se pueda leer.
+func (queue Rabbit) StartConsumer(handler func(users.ReservationEvent) error) {
+	go func() {
+		for {
+			err := queue.consume(handler)
+			select {
+			case <-queue.closed:
+				return
+			default:
+			}
+			log.Printf("reservations consumer stopped, retrying in %s: %v", queue.config.RetryInterval, err)
+			select {
+			case <-queue.closed:
+				return
+			case <-time.After(queue.config.RetryInterval):
+			}
+		}
+	}()
+}
+
+// consume se conecta, declara el exchange y la cola, y procesa mensajes hasta que se corta la
+// conexión o se llama a Close.
+func (queue Rabbit) consume(handler func(users.ReservationEvent) error) error {
+	connection, err := amqp.Dial(fmt.Sprintf("amqp://%s:%s@%s:%s/", queue.config.Username, queue.config.Password, queue.config.Host, queue.config.Port))
+	if err != nil {
+		return fmt.Errorf("error getting Rabbit connection: %w", err)
+	}
+	defer connection.Close()
+	channel, err := connection.Channel()
+	if err != nil {
+		return fmt.Errorf("error creating Rabbit channel: %w", err)
+	}
+	// Mismos parámetros que el exchange que declara hotels-api
+	if err := channel.ExchangeDeclare(queue.config.Exchange, amqp.ExchangeFanout, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("error declaring Rabbit exchange: %w", err)
+	}
+	declared, err := channel.QueueDeclare(queue.config.QueueName, true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("error declaring Rabbit queue: %w", err)
+	}
+	if err := channel.QueueBind(declared.Name, "", queue.config.Exchange, false, nil); err != nil {
+		return fmt.Errorf("error binding Rabbit queue: %w", err)
+	}
+	messages, err := channel.Consume(declared.Name, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("error registering consumer: %w", err)
+	}
+
+	for {
+		select {
+		case <-queue.closed:
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return errors.New("channel closed by Rabbit")
+			}
+			handle(msg, handler)
+		}
+	}
+}
+
+func handle(msg amqp.Delivery, handler func(users.ReservationEvent) error) {
+	var event users.ReservationEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Printf("error unmarshaling reservation event: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	if err := handler(event); err != nil {
+		log.Printf("error handling reservation event %s: %v", event.ID, err)
+		msg.Nack(false, true)
+		return
+	}
+	msg.Ack(false)
+}
+
+// Close detiene el consumidor y libera la conexión con RabbitMQ
+func (queue Rabbit) Close() {
+	queue.closeOnce.Do(func() {
+		close(queue.closed)
+	})
+}
@@ -0,0 +1,153 @@
+package points
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	domain "users-api/domain/users"
+	"users-api/middlewares"
+)
+
+type Service interface {
+	GetBalance(userID int64) (domain.PointsBalance, error)
+	GetHistory(userID int64, limit int, offset int) ([]domain.PointsEntry, error)
+	Redeem(userID int64, request domain.RedemptionRequest) (domain.PointsEntry, error)
+	Refund(userID int64, reference string) (domain.PointsEntry, error)
+}
+
+type Controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return Controller{
+		service: service,
+	}
+}
+
+// GetBalance devuelve el saldo de puntos; solo lo ve el propio usuario o un administrador
+func (controller Controller) GetBalance(c *gin.Context) {
+	userID, ok := controller.ownUserID(c)
+	if !ok {
+		return
+	}
+
+	balance, err := controller.service.GetBalance(userID)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{
+			"error": fmt.Sprintf("error getting points balance: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// GetHistory devuelve los movimientos de puntos, paginados con limit y offset
+func (controller Controller) GetHistory(c *gin.Context) {
+	userID, ok := controller.ownUserID(c)
+	if !ok {
+		return
+	}
+	limit, limitErr := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	offset, offsetErr := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limitErr != nil || offsetErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request: limit and offset must be numbers",
+		})
+		return
+	}
+
+	entries, err := controller.service.GetHistory(userID, limit, offset)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{
+			"error": fmt.Sprintf("error getting points history: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// Redeem canjea puntos; es una ruta interna que usa hotels-api al reservar
+func (controller Controller) Redeem(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("invalid request: %s", err.Error()),
+		})
+		return
+	}
+	var request domain.RedemptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("invalid request: %s", err.Error()),
+		})
+		return
+	}
+
+	entry, err := controller.service.Redeem(userID, request)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// Refund devuelve los puntos de un canje; es una ruta interna que usa hotels-api al cancelar
+func (controller Controller) Refund(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("invalid request: %s", err.Error()),
+		})
+		return
+	}
+
+	entry, err := controller.service.Refund(userID, c.Param("reference"))
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// ownUserID lee el usuario de la ruta y verifica que sea el del token o que el token sea de un
+// administrador. Si no, ya respondió el error.
+func (controller Controller) ownUserID(c *gin.Context) (int64, bool) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("invalid request: %s", err.Error()),
+		})
+		return 0, false
+	}
+	if !middlewares.CanAccess(c, strconv.FormatInt(userID, 10)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden: not the owner of these points",
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrRedemptionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidPoints):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrInsufficientPoints):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,17 @@
+package users
+
+import "time"
+
+// PointsEntry es un movimiento del libro de puntos de un usuario. El libro solo crece: una
+// redención que se devuelve se compensa con otro movimiento, nunca se borra. Reference identifica
+// la operación que originó el movimiento y es única, así que repetir la operación no duplica
+// puntos. Balance es el saldo del usuario después del movimiento.
+type PointsEntry struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	UserID    int64     `gorm:"not null;index"`
+	Type      string    `gorm:"type:enum('stay', 'redemption', 'refund');not null"`
+	Points    int64     `gorm:"not null"`
+	Balance   int64     `gorm:"not null"`
+	Reference string    `gorm:"size:100;not null;unique"`
+	CreatedAt time.Time `gorm:"not null"`
+}
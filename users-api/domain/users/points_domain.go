@@ -0,0 +1,70 @@
+package users
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrUserNotFound        = errors.New("user not found")
+	ErrInvalidPoints       = errors.New("invalid points operation")
+	ErrInsufficientPoints  = errors.New("insufficient points")
+	ErrRedemptionNotFound  = errors.New("redemption not found")
+	ErrInvalidReservation  = errors.New("invalid reservation event")
+	ErrPointsEntryNotFound = errors.New("points entry not found")
+)
+
+// Tipos de movimientos del libro de puntos
+const (
+	PointsStay       = "stay"
+	PointsRedemption = "redemption"
+	PointsRefund     = "refund"
+)
+
+// PointsPerRoomNight son los puntos que suma cada habitación por noche de una estadía completa.
+const PointsPerRoomNight = 100
+
+type PointsEntry struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Type      string    `json:"type"`
+	Points    int64     `json:"points"`
+	Balance   int64     `json:"balance"`
+	Reference string    `json:"reference"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PointsBalance struct {
+	UserID  int64 `json:"user_id"`
+	Balance int64 `json:"balance"`
+}
+
+// RedemptionRequest es el canje de puntos que pide hotels-api al reservar. Reference identifica el
+// canje: repetirlo con la misma referencia no descuenta dos veces y con ella se devuelve.
+type RedemptionRequest struct {
+	Points    int64  `json:"points"`
+	Reference string `json:"reference"`
+}
+
+// ReservationEvent es la parte que usa users-api de los eventos de reservas de hotels-api.
+type ReservationEvent struct {
+	Version     int                `json:"version"`
+	ID          string             `json:"id"`
+	Type        string             `json:"type"`
+	Reservation ReservationPayload `json:"reservation"`
+}
+
+type ReservationPayload struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	StartDate string            `json:"start_date"`
+	EndDate   string            `json:"end_date"`
+	Rooms     []ReservationRoom `json:"rooms"`
+}
+
+type ReservationRoom struct {
+	Quantity int `json:"quantity"`
+}
+
+// EventCheckedOut es el evento de hotels-api de una estadía completa, la única que suma puntos.
+const EventCheckedOut = "reservation.checked_out"
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"errors"
 	"log"
+	"os"
 	"time"
+	"users-api/clients/queues"
+	pointsControllers "users-api/controllers/points"
 	controllers "users-api/controllers/users"
+	domain "users-api/domain/users"
 	"users-api/internal/tokenizers"
+	"users-api/middlewares"
 	repositories "users-api/repositories/users"
+	pointsServices "users-api/services/points"
 	services "users-api/services/users"
 
 	"github.com/gin-contrib/cors" // Importa el paquete de CORS
@@ -57,9 +64,33 @@ func main() {
 
 	// Services
 	service := services.NewService(mySQLRepo, cacheRepo, memcachedRepo, jwtTokenizer)
+	pointsService := pointsServices.NewService(mySQLRepo)
+
+	// Puntos por estadías completas, a partir de los eventos de reservas de hotels-api. La cola es
+	// propia de users-api: los eventos que no son de estadías solo se descartan de esta cola.
+	reservationsQueue := queues.NewRabbit(queues.RabbitConfig{
+		Host:      "rabbitmq",
+		Port:      "5672",
+		Username:  "root",
+		Password:  "root",
+		Exchange:  "reservations",
+		QueueName: "users-api-reservations",
+	})
+	defer reservationsQueue.Close()
+	reservationsQueue.StartConsumer(func(event domain.ReservationEvent) error {
+		err := pointsService.CreditStay(event)
+		// Un evento que nunca se va a poder acreditar se descarta en lugar de reintentarse
+		if errors.Is(err, domain.ErrInvalidReservation) || errors.Is(err, domain.ErrUserNotFound) {
+			log.Printf("Discarding reservation event %s: %v", event.ID, err)
+			return nil
+		}
+		return err
+	})
 
 	// Handlers
 	controller := controllers.NewController(service)
+	pointsController := pointsControllers.NewController(pointsService)
+	jwtMiddleware := middlewares.NewJWTMiddleware("ThisIsAnExampleJWTKey!")
 
 	// URL mappings
 	router.GET("/users", controller.GetAll)
@@ -67,6 +98,15 @@ func main() {
 	router.POST("/users", controller.Create)
 	router.PUT("/users/:id", controller.Update)
 	router.POST("/login", controller.Login)
+	router.GET("/users/:id/points", jwtMiddleware.Authenticate(), pointsController.GetBalance)
+	router.GET("/users/:id/points/history", jwtMiddleware.Authenticate(), pointsController.GetHistory)
+
+	// Rutas internas para hotels-api, protegidas con el token compartido entre servicios
+	internalRoutes := router.Group("/internal", middlewares.InternalOnly(os.Getenv("INTERNAL_API_TOKEN")))
+	{
+		internalRoutes.POST("/users/:id/points/redemptions", pointsController.Redeem)
+		internalRoutes.DELETE("/users/:id/points/redemptions/:reference", pointsController.Refund)
+	}
 
 	// Run application
 	if err := router.Run(":8080"); err != nil {
@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claves con las que Authenticate guarda los datos del token en el contexto de gin
+const (
+	UserTypeKey = "userType"
+	UserIDKey   = "userID"
+
+	AdminUserType = "administrador"
+
+	// InternalTokenHeader es el header con el que los otros servicios llaman a las rutas internas
+	InternalTokenHeader = "X-Internal-Token"
+)
+
+type JWTMiddleware struct {
+	SecretKey string
+}
+
+func NewJWTMiddleware(secretKey string) JWTMiddleware {
+	return JWTMiddleware{SecretKey: secretKey}
+}
+
+// Authenticate valida el JWT que emite Login y guarda el usuario en el contexto.
+func (m JWTMiddleware) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
+			return
+		}
+
+		token, err := jwt.Parse(parts[1], func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return []byte(m.SecretKey), nil
+		})
+		if err != nil || !token.Valid {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		}
+
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			return
+		}
+		userType, _ := claims["tipo"].(string)
+		// El user_id se firma como número, que llega como float64 en los claims
+		userID, ok := claims["user_id"].(float64)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+			return
+		}
+
+		c.Set(UserTypeKey, userType)
+		c.Set(UserIDKey, strconv.FormatInt(int64(userID), 10))
+		c.Next()
+	}
+}
+
+// CanAccess indica si el usuario autenticado es el dueño del recurso o un administrador.
+func CanAccess(c *gin.Context, ownerID string) bool {
+	return c.GetString(UserTypeKey) == AdminUserType || (ownerID != "" && c.GetString(UserIDKey) == ownerID)
+}
+
+// InternalOnly deja pasar solo las llamadas de otros servicios, que se identifican con el token
+// compartido en InternalTokenHeader. Estas rutas no deben quedar expuestas al frontend.
+func InternalOnly(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		received := c.GetHeader(InternalTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(received), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid internal token"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package users
+
+import (
+	"github.com/stretchr/testify/mock"
+	"users-api/dao/users"
+)
+
+// PointsMock simula el libro de puntos del repositorio MySQL
+type PointsMock struct {
+	mock.Mock
+}
+
+func NewPointsMock() *PointsMock {
+	return &PointsMock{}
+}
+
+func (m *PointsMock) AddPoints(entry users.PointsEntry) (users.PointsEntry, error) {
+	args := m.Called(entry)
+	if err := args.Error(1); err != nil {
+		return users.PointsEntry{}, err
+	}
+	return args.Get(0).(users.PointsEntry), nil
+}
+
+func (m *PointsMock) GetPointsEntry(reference string) (users.PointsEntry, error) {
+	args := m.Called(reference)
+	if err := args.Error(1); err != nil {
+		return users.PointsEntry{}, err
+	}
+	return args.Get(0).(users.PointsEntry), nil
+}
+
+func (m *PointsMock) GetPointsEntries(userID int64, limit int, offset int) ([]users.PointsEntry, error) {
+	args := m.Called(userID, limit, offset)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	return args.Get(0).([]users.PointsEntry), nil
+}
+
+func (m *PointsMock) GetPointsBalance(userID int64) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
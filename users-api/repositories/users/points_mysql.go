@@ -0,0 +1,94 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"users-api/dao/users"
+	domain "users-api/domain/users"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddPoints agrega entry al libro de puntos y devuelve el movimiento con el saldo resultante. Si la
+// referencia ya existe devuelve el movimiento guardado, así una operación repetida no suma dos
+// veces. Los movimientos de un mismo usuario se serializan bloqueando su fila, de modo que el saldo
+// sobre el que se calcula cada uno es el último y nunca queda negativo.
+func (repository MySQL) AddPoints(entry users.PointsEntry) (users.PointsEntry, error) {
+	err := repository.db.Transaction(func(tx *gorm.DB) error {
+		var user users.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, entry.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("user %d: %w", entry.UserID, domain.ErrUserNotFound)
+			}
+			return fmt.Errorf("error locking user: %w", err)
+		}
+
+		var existing users.PointsEntry
+		err := tx.Where("reference = ?", entry.Reference).First(&existing).Error
+		if err == nil {
+			if existing.UserID != entry.UserID || existing.Type != entry.Type {
+				return fmt.Errorf("reference %q belongs to another operation: %w", entry.Reference, domain.ErrInvalidPoints)
+			}
+			entry = existing
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("error fetching points entry: %w", err)
+		}
+
+		balance, err := lastBalance(tx, entry.UserID)
+		if err != nil {
+			return err
+		}
+		if balance+entry.Points < 0 {
+			return fmt.Errorf("balance is %d points: %w", balance, domain.ErrInsufficientPoints)
+		}
+		entry.Balance = balance + entry.Points
+		if err := tx.Create(&entry).Error; err != nil {
+			return fmt.Errorf("error creating points entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return users.PointsEntry{}, err
+	}
+	return entry, nil
+}
+
+func (repository MySQL) GetPointsEntry(reference string) (users.PointsEntry, error) {
+	var entry users.PointsEntry
+	if err := repository.db.Where("reference = ?", reference).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entry, fmt.Errorf("reference %q: %w", reference, domain.ErrPointsEntryNotFound)
+		}
+		return entry, fmt.Errorf("error fetching points entry: %w", err)
+	}
+	return entry, nil
+}
+
+// GetPointsEntries devuelve los movimientos del usuario del más reciente al más antiguo.
+func (repository MySQL) GetPointsEntries(userID int64, limit int, offset int) ([]users.PointsEntry, error) {
+	entries := make([]users.PointsEntry, 0)
+	if err := repository.db.Where("user_id = ?", userID).Order("id DESC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("error fetching points entries: %w", err)
+	}
+	return entries, nil
+}
+
+func (repository MySQL) GetPointsBalance(userID int64) (int64, error) {
+	return lastBalance(repository.db, userID)
+}
+
+// lastBalance es el saldo que dejó el último movimiento del usuario, o 0 si no tiene ninguno.
+func lastBalance(db *gorm.DB, userID int64) (int64, error) {
+	var last users.PointsEntry
+	err := db.Where("user_id = ?", userID).Order("id DESC").First(&last).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error fetching points balance: %w", err)
+	}
+	return last.Balance, nil
+}
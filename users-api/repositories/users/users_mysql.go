@@ -26,6 +26,7 @@ type MySQL struct {
 var (
 	migrate = []interface{}{
 		users.User{},
+		users.PointsEntry{},
 	}
 )
 
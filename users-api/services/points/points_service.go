@@ -0,0 +1,171 @@
+package points
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	dao "users-api/dao/users"
+	domain "users-api/domain/users"
+)
+
+type Repository interface {
+	AddPoints(entry dao.PointsEntry) (dao.PointsEntry, error)
+	GetPointsEntry(reference string) (dao.PointsEntry, error)
+	GetPointsEntries(userID int64, limit int, offset int) ([]dao.PointsEntry, error)
+	GetPointsBalance(userID int64) (int64, error)
+}
+
+type Service struct {
+	repository Repository
+}
+
+func NewService(repository Repository) Service {
+	return Service{
+		repository: repository,
+	}
+}
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+	maxReferenceLength  = 64
+)
+
+func (service Service) GetBalance(userID int64) (domain.PointsBalance, error) {
+	balance, err := service.repository.GetPointsBalance(userID)
+	if err != nil {
+		return domain.PointsBalance{}, fmt.Errorf("error getting points balance: %w", err)
+	}
+	return domain.PointsBalance{UserID: userID, Balance: balance}, nil
+}
+
+// GetHistory devuelve los movimientos del usuario del más reciente al más antiguo. Un limit en 0
+// usa el valor por defecto.
+func (service Service) GetHistory(userID int64, limit int, offset int) ([]domain.PointsEntry, error) {
+	if limit == 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit < 0 || limit > maxHistoryLimit || offset < 0 {
+		return nil, fmt.Errorf("limit must be between 1 and %d and offset positive: %w", maxHistoryLimit, domain.ErrInvalidPoints)
+	}
+
+	entries, err := service.repository.GetPointsEntries(userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error getting points history: %w", err)
+	}
+	result := make([]domain.PointsEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, convertEntry(entry))
+	}
+	return result, nil
+}
+
+// Redeem descuenta los puntos de un canje. Repetir el canje con la misma referencia devuelve el
+// movimiento original sin volver a descontar.
+func (service Service) Redeem(userID int64, request domain.RedemptionRequest) (domain.PointsEntry, error) {
+	request.Reference = strings.TrimSpace(request.Reference)
+	if request.Points <= 0 {
+		return domain.PointsEntry{}, fmt.Errorf("points must be positive: %w", domain.ErrInvalidPoints)
+	}
+	if request.Reference == "" || len(request.Reference) > maxReferenceLength {
+		return domain.PointsEntry{}, fmt.Errorf("reference must have 1 to %d characters: %w", maxReferenceLength, domain.ErrInvalidPoints)
+	}
+
+	entry, err := service.repository.AddPoints(dao.PointsEntry{
+		UserID:    userID,
+		Type:      domain.PointsRedemption,
+		Points:    -request.Points,
+		Reference: redemptionReference(request.Reference),
+	})
+	if err != nil {
+		return domain.PointsEntry{}, fmt.Errorf("error redeeming points: %w", err)
+	}
+	return convertEntry(entry), nil
+}
+
+// Refund devuelve los puntos de un canje, por ejemplo si la reserva se canceló. El canje original
+// queda en el libro y la devolución se agrega como un movimiento aparte, una sola vez.
+func (service Service) Refund(userID int64, reference string) (domain.PointsEntry, error) {
+	reference = strings.TrimSpace(reference)
+	redemption, err := service.repository.GetPointsEntry(redemptionReference(reference))
+	if errors.Is(err, domain.ErrPointsEntryNotFound) {
+		return domain.PointsEntry{}, fmt.Errorf("reference %q: %w", reference, domain.ErrRedemptionNotFound)
+	}
+	if err != nil {
+		return domain.PointsEntry{}, fmt.Errorf("error getting redemption: %w", err)
+	}
+	if redemption.UserID != userID {
+		return domain.PointsEntry{}, fmt.Errorf("reference %q: %w", reference, domain.ErrRedemptionNotFound)
+	}
+
+	entry, err := service.repository.AddPoints(dao.PointsEntry{
+		UserID:    userID,
+		Type:      domain.PointsRefund,
+		Points:    -redemption.Points,
+		Reference: "refund:" + reference,
+	})
+	if err != nil {
+		return domain.PointsEntry{}, fmt.Errorf("error refunding points: %w", err)
+	}
+	return convertEntry(entry), nil
+}
+
+// CreditStay suma los puntos de una estadía completa. Los demás eventos de reservas se ignoran y un
+// evento repetido no suma dos veces, porque la referencia es la reserva.
+func (service Service) CreditStay(event domain.ReservationEvent) error {
+	if event.Type != domain.EventCheckedOut {
+		return nil
+	}
+
+	reservation := event.Reservation
+	userID, err := strconv.ParseInt(reservation.UserID, 10, 64)
+	if err != nil || reservation.ID == "" {
+		return fmt.Errorf("reservation %q of user %q: %w", reservation.ID, reservation.UserID, domain.ErrInvalidReservation)
+	}
+	start, startErr := time.Parse("2006-01-02", reservation.StartDate)
+	end, endErr := time.Parse("2006-01-02", reservation.EndDate)
+	if startErr != nil || endErr != nil || !end.After(start) {
+		return fmt.Errorf("reservation %s dates: %w", reservation.ID, domain.ErrInvalidReservation)
+	}
+	nights := int(end.Sub(start).Hours() / 24)
+	rooms := 0
+	for _, room := range reservation.Rooms {
+		rooms += room.Quantity
+	}
+	if rooms == 0 {
+		return fmt.Errorf("reservation %s has no rooms: %w", reservation.ID, domain.ErrInvalidReservation)
+	}
+
+	entry, err := service.repository.AddPoints(dao.PointsEntry{
+		UserID:    userID,
+		Type:      domain.PointsStay,
+		Points:    int64(nights * rooms * domain.PointsPerRoomNight),
+		Reference: "stay:" + reservation.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("error crediting stay: %w", err)
+	}
+	log.Printf("User %d earned %d points for reservation %s", userID, entry.Points, reservation.ID)
+	return nil
+}
+
+// redemptionReference separa las referencias de los canjes de las de las estadías y devoluciones,
+// que comparten el índice único del libro.
+func redemptionReference(reference string) string {
+	return "redemption:" + reference
+}
+
+func convertEntry(entry dao.PointsEntry) domain.PointsEntry {
+	return domain.PointsEntry{
+		ID:        entry.ID,
+		UserID:    entry.UserID,
+		Type:      entry.Type,
+		Points:    entry.Points,
+		Balance:   entry.Balance,
+		Reference: entry.Reference,
+		CreatedAt: entry.CreatedAt,
+	}
+}
@@ -0,0 +1,116 @@
+package points_test
+
+import (
+	"fmt"
+	"testing"
+	dao "users-api/dao/users"
+	domain "users-api/domain/users"
+	repositories "users-api/repositories/users"
+	service "users-api/services/points"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	pointsRepo    = repositories.NewPointsMock()
+	pointsService = service.NewService(pointsRepo)
+)
+
+func TestService(t *testing.T) {
+	t.Run("CreditStay - Credits every room night once", func(t *testing.T) {
+		// 3 noches en 2 habitaciones
+		entry := dao.PointsEntry{UserID: 7, Type: domain.PointsStay, Points: 600, Reference: "stay:res-1"}
+		pointsRepo.On("AddPoints", entry).Return(dao.PointsEntry{ID: 1, UserID: 7, Type: domain.PointsStay, Points: 600, Balance: 600, Reference: "stay:res-1"}, nil).Once()
+
+		err := pointsService.CreditStay(domain.ReservationEvent{
+			ID:   "event-1",
+			Type: domain.EventCheckedOut,
+			Reservation: domain.ReservationPayload{
+				ID:        "res-1",
+				UserID:    "7",
+				StartDate: "2024-12-02",
+				EndDate:   "2024-12-05",
+				Rooms:     []domain.ReservationRoom{{Quantity: 1}, {Quantity: 1}},
+			},
+		})
+
+		assert.NoError(t, err)
+		pointsRepo.AssertExpectations(t)
+	})
+
+	t.Run("CreditStay - Ignores other events", func(t *testing.T) {
+		err := pointsService.CreditStay(domain.ReservationEvent{ID: "event-2", Type: "reservation.cancelled"})
+
+		assert.NoError(t, err)
+		pointsRepo.AssertExpectations(t)
+	})
+
+	t.Run("CreditStay - Rejects events without a numeric user", func(t *testing.T) {
+		err := pointsService.CreditStay(domain.ReservationEvent{
+			ID:          "event-3",
+			Type:        domain.EventCheckedOut,
+			Reservation: domain.ReservationPayload{ID: "res-3", UserID: "guest", StartDate: "2024-12-02", EndDate: "2024-12-03"},
+		})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidReservation)
+		pointsRepo.AssertExpectations(t)
+	})
+
+	t.Run("Redeem - Debits the points", func(t *testing.T) {
+		entry := dao.PointsEntry{UserID: 7, Type: domain.PointsRedemption, Points: -200, Reference: "redemption:abc"}
+		pointsRepo.On("AddPoints", entry).Return(dao.PointsEntry{ID: 2, UserID: 7, Type: domain.PointsRedemption, Points: -200, Balance: 400, Reference: "redemption:abc"}, nil).Once()
+
+		result, err := pointsService.Redeem(7, domain.RedemptionRequest{Points: 200, Reference: " abc "})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(400), result.Balance)
+		pointsRepo.AssertExpectations(t)
+	})
+
+	t.Run("Redeem - Insufficient points", func(t *testing.T) {
+		entry := dao.PointsEntry{UserID: 7, Type: domain.PointsRedemption, Points: -5000, Reference: "redemption:def"}
+		pointsRepo.On("AddPoints", entry).Return(nil, fmt.Errorf("balance is 400 points: %w", domain.ErrInsufficientPoints)).Once()
+
+		_, err := pointsService.Redeem(7, domain.RedemptionRequest{Points: 5000, Reference: "def"})
+
+		assert.ErrorIs(t, err, domain.ErrInsufficientPoints)
+		pointsRepo.AssertExpectations(t)
+	})
+
+	t.Run("Redeem - Invalid request", func(t *testing.T) {
+		_, err := pointsService.Redeem(7, domain.RedemptionRequest{Points: 0, Reference: "ghi"})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidPoints)
+		pointsRepo.AssertExpectations(t)
+	})
+
+	t.Run("Refund - Returns the redeemed points", func(t *testing.T) {
+		redemption := dao.PointsEntry{ID: 2, UserID: 7, Type: domain.PointsRedemption, Points: -200, Balance: 400, Reference: "redemption:abc"}
+		pointsRepo.On("GetPointsEntry", "redemption:abc").Return(redemption, nil).Once()
+		entry := dao.PointsEntry{UserID: 7, Type: domain.PointsRefund, Points: 200, Reference: "refund:abc"}
+		pointsRepo.On("AddPoints", entry).Return(dao.PointsEntry{ID: 3, UserID: 7, Type: domain.PointsRefund, Points: 200, Balance: 600, Reference: "refund:abc"}, nil).Once()
+
+		result, err := pointsService.Refund(7, "abc")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(600), result.Balance)
+		pointsRepo.AssertExpectations(t)
+	})
+
+	t.Run("Refund - Another user's redemption", func(t *testing.T) {
+		redemption := dao.PointsEntry{ID: 2, UserID: 7, Type: domain.PointsRedemption, Points: -200, Balance: 400, Reference: "redemption:abc"}
+		pointsRepo.On("GetPointsEntry", "redemption:abc").Return(redemption, nil).Once()
+
+		_, err := pointsService.Refund(8, "abc")
+
+		assert.ErrorIs(t, err, domain.ErrRedemptionNotFound)
+		pointsRepo.AssertExpectations(t)
+	})
+
+	t.Run("GetHistory - Rejects a limit over the maximum", func(t *testing.T) {
+		_, err := pointsService.GetHistory(7, 500, 0)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidPoints)
+		pointsRepo.AssertExpectations(t)
+	})
+}